@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/hknutzen/Netspoc/go/pkg/anonymize"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"os"
+)
+
+func main() {
+	os.Exit(anonymize.Main(oslink.Get()))
+}
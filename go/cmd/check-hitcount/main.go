@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os"
+
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/hknutzen/Netspoc/go/pkg/pass2"
+)
+
+func main() {
+	os.Exit(pass2.CheckHitcountMain(oslink.Get()))
+}
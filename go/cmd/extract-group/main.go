@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/hknutzen/Netspoc/go/pkg/extract"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"os"
+)
+
+func main() {
+	os.Exit(extract.Main(oslink.Get()))
+}
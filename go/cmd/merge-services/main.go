@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/hknutzen/Netspoc/go/pkg/mergeservices"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"os"
+)
+
+func main() {
+	os.Exit(mergeservices.Main(oslink.Get()))
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/hknutzen/Netspoc/go/pkg/pass1"
+	"os"
+)
+
+func main() {
+	os.Exit(pass1.PrintServiceDatesMain(oslink.Get()))
+}
@@ -0,0 +1,488 @@
+package anonymize
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/netip"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/ast"
+	"github.com/hknutzen/Netspoc/go/pkg/astset"
+	"github.com/hknutzen/Netspoc/go/pkg/fileop"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/spf13/pflag"
+)
+
+// Prefix of new names for each name space.
+var namePrefix = map[string]string{
+	"network":         "n",
+	"router":          "r",
+	"vrf":             "v",
+	"bridge":          "b",
+	"host":            "h",
+	"any":             "a",
+	"area":            "area",
+	"group":           "g",
+	"service":         "s",
+	"owner":           "o",
+	"protocol":        "p",
+	"protocolgroup":   "pg",
+	"pathrestriction": "pr",
+	"nat":             "nat",
+	"isakmp":          "isakmp",
+	"ipsec":           "ipsec",
+	"crypto":          "crypto",
+	"partition":       "part",
+	"user":            "user",
+	"domain":          "domain",
+	"ldap":            "ldap",
+	"hardware":        "hw",
+	"vpn_value":       "value",
+	"description":     "Description ",
+}
+
+// Types of typed names, that may be referenced in attribute values.
+var refType = map[string]bool{
+	"router":          true,
+	"network":         true,
+	"interface":       true,
+	"host":            true,
+	"any":             true,
+	"area":            true,
+	"group":           true,
+	"service":         true,
+	"owner":           true,
+	"protocol":        true,
+	"protocolgroup":   true,
+	"pathrestriction": true,
+	"isakmp":          true,
+	"ipsec":           true,
+	"crypto":          true,
+}
+
+// anonymizer walks all definitions twice.
+// In first pass, names and IP addresses are collected.
+// In second pass, names and IP addresses are substituted.
+type anonymizer struct {
+	collect bool
+	names   map[string]map[string]string
+	ip      *ipMapper
+}
+
+func newAnonymizer(key []byte) *anonymizer {
+	return &anonymizer{
+		collect: true,
+		names:   make(map[string]map[string]string),
+		ip:      newIPMapper(key),
+	}
+}
+
+func (a *anonymizer) name(ns, name string) string {
+	if name == "" {
+		return name
+	}
+	if a.collect {
+		m := a.names[ns]
+		if m == nil {
+			m = make(map[string]string)
+			a.names[ns] = m
+		}
+		m[name] = ""
+		return name
+	}
+	return a.names[ns][name]
+}
+
+// Assign new names of each name space in same order as original
+// names. This retains the processing order of Netspoc, which
+// depends on sort order of names.
+func (a *anonymizer) setupNames() {
+	for ns, m := range a.names {
+		l := make([]string, 0, len(m))
+		for name := range m {
+			l = append(l, name)
+		}
+		slices.Sort(l)
+		width := len(strconv.Itoa(len(l)))
+		for i, name := range l {
+			m[name] = fmt.Sprintf("%s%0*d", namePrefix[ns], width, i+1)
+		}
+	}
+	a.collect = false
+}
+
+func (a *anonymizer) networkName(name string) string {
+	// Bridged network has name with two parts.
+	if n, bridge, found := strings.Cut(name, "/"); found {
+		return a.name("network", n) + "/" + a.name("bridge", bridge)
+	}
+	return a.name("network", name)
+}
+
+func (a *anonymizer) routerName(name string) string {
+	if r, vrf, found := strings.Cut(name, "@"); found {
+		return a.name("router", r) + "@" + a.name("vrf", vrf)
+	}
+	return a.name("router", name)
+}
+
+// Change ID of host or interface like "user@domain" or "@domain" or
+// "domain".
+func (a *anonymizer) idName(id string) string {
+	if user, domain, found := strings.Cut(id, "@"); found {
+		return a.name("user", user) + "@" + a.name("domain", domain)
+	}
+	return a.name("domain", id)
+}
+
+func (a *anonymizer) email(m string) string {
+	switch m {
+	case "guest":
+		return m
+	}
+	user, domain, found := strings.Cut(m, "@")
+	if !found {
+		return m
+	}
+	if user != "[all]" {
+		user = a.name("user", strings.ToLower(user))
+	}
+	return user + "@" + a.name("domain", strings.ToLower(domain))
+}
+
+// Change name of definition.
+func (a *anonymizer) defName(typ, name string) string {
+	switch typ {
+	case "network", "interface":
+		// Name of interface is name of network or name of loopback
+		// interface.
+		return a.networkName(name)
+	case "router":
+		return a.routerName(name)
+	case "host":
+		if id, found := strings.CutPrefix(name, "id:"); found {
+			return "id:" + a.idName(id)
+		}
+	}
+	if _, found := namePrefix[typ]; !found {
+		return name
+	}
+	return a.name(typ, name)
+}
+
+// Change name of referenced object.
+func (a *anonymizer) refName(typ, name string) string {
+	switch typ {
+	case "interface":
+		r, rest, _ := strings.Cut(name, ".")
+		n, ext, found := strings.Cut(rest, ".")
+		name = a.routerName(r) + "." + a.networkName(n)
+		if found {
+			name += "." + ext
+		}
+		return name
+	case "host":
+		if id, found := strings.CutPrefix(name, "id:"); found {
+			// ID host is extended by network name: host:id:a.b@c.d.net_name
+			i := strings.LastIndex(id, ".")
+			return "id:" + a.idName(id[:i]) + "." + a.networkName(id[i+1:])
+		}
+	}
+	return a.defName(typ, name)
+}
+
+func (a *anonymizer) typedName(v string) string {
+	if typ, name, found := strings.Cut(v, ":"); found && refType[typ] {
+		return typ + ":" + a.refName(typ, name)
+	}
+	return v
+}
+
+func clearComments(n ast.Node) {
+	n.SetPreComment("")
+	n.SetPostComment("")
+}
+
+func (a *anonymizer) value(attr string, v string) string {
+	switch attr {
+	case "ip", "ip6", "filter_only", "merge_tunnelspecified":
+		return a.ip.value(v, a.collect)
+	case "range", "range6":
+		return a.ip.rangeValue(v, a.collect)
+	case "owner":
+		return a.name("owner", v)
+	case "nat_in", "nat_out":
+		return a.name("nat", v)
+	case "partition":
+		return a.name("partition", v)
+	case "admins", "watchers":
+		return a.email(v)
+	case "cert_id":
+		return a.name("domain", v)
+	case "ldap_id", "ldap_append":
+		return a.name("ldap", v)
+	case "hardware":
+		// These names have special meaning in Netspoc.
+		switch v {
+		case "device", "IN", "OUT":
+			return v
+		}
+		return a.name("hardware", v)
+	case "id":
+		// ID of interface is IP address or name like ID of host.
+		// Numeric ID of redundancy protocol is left unchanged.
+		if _, err := netip.ParseAddr(v); err == nil {
+			return a.ip.value(v, a.collect)
+		}
+		if _, err := strconv.Atoi(v); err != nil {
+			return a.idName(v)
+		}
+	}
+	return a.typedName(v)
+}
+
+func (a *anonymizer) collectStaticNAT(l []*ast.Attribute) {
+	var ip string
+	for _, at := range l {
+		switch at.Name {
+		case "dynamic", "hidden", "identity":
+			return
+		case "ip", "ip6":
+			if len(at.ValueList) == 1 {
+				ip = at.ValueList[0].Value
+			}
+		}
+	}
+	if ip != "" {
+		a.ip.addStaticNAT(ip)
+	}
+}
+
+// Values of vpn_attributes may contain names of customer, servers
+// and domains. Value of 'split-tunnel-policy' is checked by Netspoc
+// and numeric values like timeouts are left unchanged.
+func (a *anonymizer) vpnAttributes(l []*ast.Attribute) {
+	for _, at := range l {
+		clearComments(at)
+		for _, v := range at.ValueList {
+			clearComments(v)
+			if _, err := strconv.Atoi(v.Value); err == nil ||
+				at.Name == "split-tunnel-policy" {
+				continue
+			}
+			v.Value = a.name("vpn_value", v.Value)
+		}
+	}
+}
+
+func (a *anonymizer) attributes(l []*ast.Attribute) {
+	for _, at := range l {
+		clearComments(at)
+		if at.Name == "vpn_attributes" {
+			a.vpnAttributes(at.ComplexValue)
+			continue
+		}
+		if typ, name, found := strings.Cut(at.Name, ":"); found {
+			if typ == "nat" && a.collect {
+				a.collectStaticNAT(at.ComplexValue)
+			}
+			at.Name = typ + ":" + a.defName(typ, name)
+		}
+		for _, v := range at.ValueList {
+			clearComments(v)
+			v.Value = a.value(at.Name, v.Value)
+		}
+		a.attributes(at.ComplexValue)
+	}
+}
+
+func (a *anonymizer) elements(l []ast.Element) {
+	for _, el := range l {
+		clearComments(el)
+		switch x := el.(type) {
+		case *ast.NamedRef:
+			x.Name = a.refName(x.Type, x.Name)
+		case *ast.IntfRef:
+			x.Router = a.routerName(x.Router)
+			// Network is "[" in interface:r.[all]
+			if x.Network != "[" {
+				x.Network = a.networkName(x.Network)
+			}
+		case *ast.SimpleAuto:
+			a.elements(x.Elements)
+		case *ast.AggAuto:
+			if x.Net != "" {
+				x.Net = a.ip.value(x.Net, a.collect)
+			}
+			a.elements(x.Elements)
+		case *ast.IntfAuto:
+			a.elements(x.Elements)
+		case *ast.Intersection:
+			a.elements(x.Elements)
+		case *ast.Complement:
+			a.elements([]ast.Element{x.Element})
+		}
+	}
+}
+
+func (a *anonymizer) namedUnion(n *ast.NamedUnion) {
+	if n != nil {
+		clearComments(n)
+		a.elements(n.Elements)
+	}
+}
+
+func (a *anonymizer) toplevel(n ast.Toplevel) {
+	clearComments(n)
+	typ, name, _ := strings.Cut(n.GetName(), ":")
+	n.SetName(typ + ":" + a.defName(typ, name))
+	if d := n.GetDescription(); d != nil {
+		clearComments(d)
+		d.Text = a.name("description", d.Text)
+	}
+	switch x := n.(type) {
+	case *ast.TopList:
+		a.elements(x.Elements)
+	case *ast.Protocolgroup:
+		for _, v := range x.ValueList {
+			clearComments(v)
+			v.Value = a.typedName(v.Value)
+		}
+	case *ast.TopStruct:
+		a.attributes(x.Attributes)
+	case *ast.Service:
+		a.attributes(x.Attributes)
+		a.namedUnion(x.User)
+		for _, r := range x.Rules {
+			clearComments(r)
+			a.namedUnion(r.Src)
+			a.namedUnion(r.Dst)
+			a.attributes([]*ast.Attribute{r.Prt})
+			if r.Log != nil {
+				a.attributes([]*ast.Attribute{r.Log})
+			}
+		}
+	case *ast.Network:
+		a.attributes(x.Attributes)
+		a.attributes(x.Hosts)
+	case *ast.Router:
+		a.attributes(x.Attributes)
+		a.attributes(x.Interfaces)
+	case *ast.Area:
+		a.attributes(x.Attributes)
+		a.namedUnion(x.Border)
+		a.namedUnion(x.InclusiveBorder)
+	}
+}
+
+func process(s *astset.State, key []byte) {
+	a := newAnonymizer(key)
+	walk := func() {
+		s.Modify(func(n ast.Toplevel) bool {
+			a.toplevel(n)
+			return true
+		})
+	}
+	walk()
+	a.setupNames()
+	walk()
+	for _, aF := range s.Files() {
+		aF.BottomCmt = ""
+	}
+}
+
+// Files get new names by their index. File without any definition
+// is left out.
+func writeFiles(s *astset.State, outDir string) error {
+	files := s.Files()
+	width := len(strconv.Itoa(len(files)))
+	return s.WriteTo(outDir, func(i int, _ string) string {
+		if len(files[i].Nodes) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("file%0*d", width, i+1)
+	})
+}
+
+// Copy file with Netspoc options, it contains no confidential data.
+func copyConfig(inPath, outDir string) error {
+	name := path.Join(inPath, "config")
+	if !fileop.IsRegular(name) {
+		return nil
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return fileop.Overwrite(filepath.Join(outDir, "config"), data)
+}
+
+func Main(d oslink.Data) int {
+	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
+
+	// Setup custom usage function.
+	fs.Usage = func() {
+		fmt.Fprintf(d.Stderr,
+			"Usage: %s [options] IN-DIR|IN-FILE OUT-DIR\n%s",
+			d.Args[0], fs.FlagUsages())
+	}
+
+	// Command line flags
+	quiet := fs.BoolP("quiet", "q", false, "Don't print status messages")
+	keyStr := fs.StringP("key", "k", "",
+		"Key for mapping of IP addresses, random if not given")
+	if err := fs.Parse(d.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 1
+		}
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		fs.Usage()
+		return 1
+	}
+
+	// Argument processing
+	args := fs.Args()
+	if len(args) != 2 {
+		fs.Usage()
+		return 1
+	}
+	inPath := args[0]
+	outDir := args[1]
+	if _, err := os.Stat(outDir); err == nil {
+		fmt.Fprintf(d.Stderr, "Error: %s already exists\n", outDir)
+		return 1
+	}
+	key := []byte(*keyStr)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+
+	s, err := astset.Read(inPath)
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	process(s, key)
+	if err := os.MkdirAll(outDir, os.ModePerm); err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := writeFiles(s, outDir); err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := copyConfig(inPath, outDir); err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if !*quiet {
+		fmt.Fprintf(d.Stderr, "Wrote anonymized files to %s\n", outDir)
+	}
+	return 0
+}
@@ -0,0 +1,159 @@
+package anonymize
+
+import (
+	"crypto/sha256"
+	"net/netip"
+	"strings"
+)
+
+// ipMapper maps IP addresses by a prefix preserving permutation:
+// If two addresses have a common prefix of n bits, then the mapped
+// addresses have a common prefix of n bits as well.
+// Hence each network is mapped to a network of same size and all
+// subnet relations are retained.
+//
+// Bit i of an address is flipped depending on bits 0..i-1
+// and a secret key. A bit is never flipped,
+//   - if it would change the network address or broadcast address
+//     of some network, i.e. if all bits between the prefix length
+//     of a network and bit i are all zero or all one,
+//   - if it is located below the common prefix of a range.
+//     Hence ranges keep their order.
+//
+// Static NAT takes the host part of an address from the original
+// address. Hence bits after the prefix length of the smallest static
+// NAT network are flipped only depending on bits after this prefix
+// length. This retains the relation between original and translated
+// addresses.
+type ipMapper struct {
+	key      []byte
+	prefixes map[netip.Prefix]bool
+	frozen   map[netip.Prefix]bool
+	// Smallest prefix length of static NAT for IPv4 and IPv6.
+	staticNAT map[int]int
+	cache     map[netip.Addr]netip.Addr
+}
+
+func newIPMapper(key []byte) *ipMapper {
+	return &ipMapper{
+		key:       key,
+		prefixes:  make(map[netip.Prefix]bool),
+		frozen:    make(map[netip.Prefix]bool),
+		staticNAT: make(map[int]int),
+		cache:     make(map[netip.Addr]netip.Addr),
+	}
+}
+
+// Remember prefix length of static NAT network.
+func (m *ipMapper) addStaticNAT(v string) {
+	if p, err := netip.ParsePrefix(v); err == nil {
+		bitLen := p.Addr().BitLen()
+		if l, found := m.staticNAT[bitLen]; !found || p.Bits() < l {
+			m.staticNAT[bitLen] = p.Bits()
+		}
+	}
+}
+
+// Change single IP address or prefix.
+// In collect mode, only remember prefix.
+// Invalid value is left unchanged.
+func (m *ipMapper) value(v string, collect bool) string {
+	if strings.Contains(v, "/") {
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return v
+		}
+		if collect {
+			m.prefixes[p.Masked()] = true
+			return v
+		}
+		return netip.PrefixFrom(m.mapAddr(p.Addr()), p.Bits()).String()
+	}
+	ip, err := netip.ParseAddr(v)
+	if err != nil || collect {
+		return v
+	}
+	return m.mapAddr(ip).String()
+}
+
+// Change range "IP1 - IP2".
+// In collect mode, only remember common prefix of both addresses.
+func (m *ipMapper) rangeValue(v string, collect bool) string {
+	s1, s2, _ := strings.Cut(v, "-")
+	ip1, err1 := netip.ParseAddr(strings.TrimSpace(s1))
+	ip2, err2 := netip.ParseAddr(strings.TrimSpace(s2))
+	if err1 != nil || err2 != nil || ip1.BitLen() != ip2.BitLen() {
+		return v
+	}
+	if collect {
+		bits := 0
+		for bits < ip1.BitLen() && getBit(ip1, bits) == getBit(ip2, bits) {
+			bits++
+		}
+		m.frozen[netip.PrefixFrom(ip1, bits).Masked()] = true
+		return v
+	}
+	return m.mapAddr(ip1).String() + "-" + m.mapAddr(ip2).String()
+}
+
+func getBit(ip netip.Addr, i int) byte {
+	b := ip.AsSlice()
+	return b[i/8] >> (7 - i%8) & 1
+}
+
+func (m *ipMapper) mapAddr(ip netip.Addr) netip.Addr {
+	if r, found := m.cache[ip]; found {
+		return r
+	}
+	result := ip.AsSlice()
+	// Host part of ip with leading bits of static NAT cleared.
+	low := ip
+	lowBits := ip.BitLen()
+	if l, found := m.staticNAT[lowBits]; found {
+		lowBits = l
+		b := ip.AsSlice()
+		for i := range l {
+			b[i/8] &^= 1 << (7 - i%8)
+		}
+		low, _ = netip.AddrFromSlice(b)
+	}
+	// Largest prefix length of some network containing ip.
+	netBits := -1
+	zeros, ones := 0, 0
+	frozen := false
+	for i := range ip.BitLen() {
+		p := netip.PrefixFrom(ip, i).Masked()
+		if m.prefixes[p] {
+			netBits = i
+		}
+		if m.frozen[p] {
+			frozen = true
+		}
+		keep := frozen ||
+			netBits != -1 && (i-netBits <= zeros || i-netBits <= ones)
+		if i >= lowBits {
+			p = netip.PrefixFrom(low, i).Masked()
+		}
+		if !keep && m.flip(p) {
+			result[i/8] ^= 1 << (7 - i%8)
+		}
+		if getBit(ip, i) == 0 {
+			zeros++
+			ones = 0
+		} else {
+			ones++
+			zeros = 0
+		}
+	}
+	r, _ := netip.AddrFromSlice(result)
+	m.cache[ip] = r
+	return r
+}
+
+// Pseudo random bit, derived from prefix and key.
+func (m *ipMapper) flip(p netip.Prefix) bool {
+	h := sha256.New()
+	h.Write(m.key)
+	h.Write([]byte(p.String()))
+	return h.Sum(nil)[0]&1 == 1
+}
@@ -3,6 +3,7 @@
 package ast
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -17,7 +18,11 @@ type Node interface {
 	PostComment() string // Trailing comment, if available.
 	SetPreComment(string)
 	SetPostComment(string)
+	Start() Position // Position of first character of node.
+	End() Position   // Position after last character of node.
+	SetPos(start, end Position)
 	Order()
+	OrderWith(SortStyle)
 }
 
 type Element interface {
@@ -44,15 +49,38 @@ type ToplevelWithAttr interface {
 
 // ----------------------------------------------------------------------------
 
+// Position describes a location in source file.
+// Offset starts at 0, Line and Column start at 1.
+// Column is counted in bytes.
+// Position is only valid, if node was read by parser.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) IsValid() bool { return p.Line > 0 }
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
 type Base struct {
 	preCmt  string
 	postCmt string
+	start   Position
+	end     Position
 }
 
-func (a Base) PreComment() string       { return a.preCmt }
-func (a Base) PostComment() string      { return a.postCmt }
-func (a *Base) SetPreComment(c string)  { a.preCmt = c }
-func (a *Base) SetPostComment(c string) { a.postCmt = c }
+func (a Base) PreComment() string          { return a.preCmt }
+func (a Base) PostComment() string         { return a.postCmt }
+func (a *Base) SetPreComment(c string)     { a.preCmt = c }
+func (a *Base) SetPostComment(c string)    { a.postCmt = c }
+func (a Base) Start() Position             { return a.start }
+func (a Base) End() Position               { return a.end }
+func (a *Base) SetPos(start, end Position) { a.start, a.end = start, end }
 
 type User struct {
 	Base
@@ -151,39 +151,58 @@ func sortAttr(l []*Attribute) {
 	})
 }
 
+// SortStyle selects lists, that are left in their original order.
+// Zero value sorts all lists.
+type SortStyle struct {
+	KeepElements   bool
+	KeepHosts      bool
+	KeepAttributes bool
+}
+
 func OrderElements(l []Element) {
+	orderElements(l, SortStyle{})
+}
+
+func orderElements(l []Element, s SortStyle) {
 	for _, n := range l {
-		n.Order()
+		n.OrderWith(s)
+	}
+	if !s.KeepElements {
+		sortElem(l)
 	}
-	sortElem(l)
 }
 
-func (a *Base) Order() {}
+func (a *Base) Order()              {}
+func (a *Base) OrderWith(SortStyle) {}
 
-func (a *SimpleAuto) Order() {
-	OrderElements(a.Elements)
+func (a *SimpleAuto) Order() { a.OrderWith(SortStyle{}) }
+func (a *SimpleAuto) OrderWith(s SortStyle) {
+	orderElements(a.Elements, s)
 }
 
-func (a *Complement) Order() {
-	a.Element.Order()
+func (a *Complement) Order() { a.OrderWith(SortStyle{}) }
+func (a *Complement) OrderWith(s SortStyle) {
+	a.Element.OrderWith(s)
 }
 
-func (a *Intersection) Order() {
+func (a *Intersection) Order() { a.OrderWith(SortStyle{}) }
+func (a *Intersection) OrderWith(s SortStyle) {
 	for _, n := range a.Elements {
-		n.Order()
+		n.OrderWith(s)
 	}
 }
 
-func (a *TopList) Order() {
-	OrderElements(a.Elements)
+func (a *TopList) Order() { a.OrderWith(SortStyle{}) }
+func (a *TopList) OrderWith(s SortStyle) {
+	orderElements(a.Elements, s)
 }
 
-func (a *Protocolgroup) Order() {
-	sortProto(a.ValueList)
-}
+func (a *Protocolgroup) Order()              { sortProto(a.ValueList) }
+func (a *Protocolgroup) OrderWith(SortStyle) { a.Order() }
 
-func (a *NamedUnion) Order() {
-	OrderElements(a.Elements)
+func (a *NamedUnion) Order() { a.OrderWith(SortStyle{}) }
+func (a *NamedUnion) OrderWith(s SortStyle) {
+	orderElements(a.Elements, s)
 }
 
 func (a *Attribute) Order() {
@@ -192,10 +211,12 @@ func (a *Attribute) Order() {
 		return strings.Compare(strings.ToLower(a.Value), strings.ToLower(b.Value))
 	})
 }
+func (a *Attribute) OrderWith(SortStyle) { a.Order() }
 
-func (a *Rule) Order() {
-	a.Src.Order()
-	a.Dst.Order()
+func (a *Rule) Order() { a.OrderWith(SortStyle{}) }
+func (a *Rule) OrderWith(s SortStyle) {
+	a.Src.OrderWith(s)
+	a.Dst.OrderWith(s)
 	sortProto(a.Prt.ValueList)
 	if attr := a.Log; attr != nil {
 		attr.Order()
@@ -207,13 +228,17 @@ func (a *TopStruct) Order() {
 		attr.Order()
 	}
 }
+func (a *TopStruct) OrderWith(SortStyle) { a.Order() }
 
-func (a *Service) Order() {
-	sortAttr(a.Attributes)
+func (a *Service) Order() { a.OrderWith(SortStyle{}) }
+func (a *Service) OrderWith(s SortStyle) {
+	if !s.KeepAttributes {
+		sortAttr(a.Attributes)
+	}
 	a.TopStruct.Order()
-	a.User.Order()
+	a.User.OrderWith(s)
 	for _, r := range a.Rules {
-		r.Order()
+		r.OrderWith(s)
 	}
 }
 
@@ -240,10 +265,15 @@ func sortByIP(l []*Attribute) {
 	})
 }
 
-func (a *Network) Order() {
-	sortByIP(a.Hosts)
+func (a *Network) Order() { a.OrderWith(SortStyle{}) }
+func (a *Network) OrderWith(s SortStyle) {
+	if !s.KeepHosts {
+		sortByIP(a.Hosts)
+	}
 }
 
+func (a *Router) OrderWith(SortStyle) { a.Order() }
+
 // Only sort successive vip interfaces.
 func (a *Router) Order() {
 	start := -1
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/hknutzen/Netspoc/go/pkg/ast"
@@ -62,6 +63,32 @@ func (s *State) Print() {
 	}
 }
 
+// WriteTo writes all files into directory dir.
+// Function name gets the index and original path of each file and
+// returns the new path relative to dir. The file is left out, if
+// an empty string is returned.
+func (s *State) WriteTo(dir string, name func(int, string) string) error {
+	for i, path := range s.files {
+		rel := name(i, path)
+		if rel == "" {
+			continue
+		}
+		file := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			return err
+		}
+		if err := fileop.Overwrite(file, printer.File(s.astFiles[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files returns the syntax tree of each file.
+func (s *State) Files() []*ast.File {
+	return s.astFiles
+}
+
 func (s *State) ShowChanged(stderr io.Writer, quiet bool) {
 	if !quiet {
 		for _, file := range s.Changed() {
@@ -57,30 +57,45 @@ func (v TriState) Type() string { return "tristate" }
 
 // Config holds program flags.
 type Config struct {
-	CheckDuplicateRules          TriState
-	CheckFullyRedundantRules     TriState
-	CheckIdenticalServices       TriState
-	CheckPolicyDistributionPoint TriState
-	CheckRedundantRules          TriState
-	CheckServiceEmptyUser        TriState
-	CheckServiceMultiOwner       TriState
-	CheckServiceUnknownOwner     TriState
-	CheckServiceUselessAttribute TriState
-	CheckEmptyFiles              TriState
-	CheckSubnets                 TriState
-	CheckSupernetRules           TriState
-	CheckTransientSupernetRules  TriState
-	CheckUnenforceable           TriState
-	CheckUnusedGroups            TriState
-	CheckUnusedOwners            TriState
-	CheckUnusedProtocols         TriState
-	AutoDefaultRoute             bool
-	ConcurrencyPass1             int
-	ConcurrencyPass2             int
-	MaxErrors                    int  `flag:"max_errors m"`
-	Quiet                        bool `flag:"quiet q"`
-	TimeStamps                   bool `flag:"time_stamps t"`
-	DebugPass2                   string
+	CheckCryptoPolicy             TriState
+	CheckDuplicateRules           TriState
+	CheckFullyRedundantRules      TriState
+	CheckIdenticalServices        TriState
+	CheckPolicyDistributionPoint  TriState
+	CheckRedundantRules           TriState
+	CheckServiceEmptyUser         TriState
+	CheckServiceMultiOwner        TriState
+	CheckServiceUnknownOwner      TriState
+	CheckServiceUselessAttribute  TriState
+	CheckEmptyFiles               TriState
+	CheckSubnets                  TriState
+	CheckSupernetRules            TriState
+	CheckTransientSupernetRules   TriState
+	CheckUnenforceable            TriState
+	CheckUnusedGroups             TriState
+	CheckUnusedOwners             TriState
+	CheckUnusedProtocols          TriState
+	AggregateRoutes               bool
+	AllSyntaxErrors               bool
+	AutoDefaultRoute              bool
+	Autofix                       string
+	ConcurrencyPass1              int
+	ConcurrencyPass2              int
+	CryptoPolicyEncryption        []string
+	CryptoPolicyHash              []string
+	CryptoPolicyMinGroup          int
+	CryptoPolicyMinIkeVersion     int
+	CryptoPolicyMinIsakmpLifetime int
+	CryptoPolicyMinIpsecLifetime  int
+	GenerateRedundancy            bool
+	MaxErrors                     int `flag:"max_errors m"`
+	MemoryLimit                   int
+	ProfileDir                    string
+	Quiet                         bool `flag:"quiet q"`
+	ServiceMap                    bool
+	TimeStamps                    bool `flag:"time_stamps t"`
+	WarnExpiringDays              int
+	DebugPass2                    string
 }
 
 func DefaultOptions(fs *pflag.FlagSet) *Config {
@@ -138,6 +153,18 @@ func DefaultOptions(fs *pflag.FlagSet) *Config {
 		// 'policy_distribution_point', either directly or from inheritance.
 		CheckPolicyDistributionPoint: "",
 
+		// Check, that definitions of isakmp and ipsec comply with
+		// crypto policy given below.
+		CheckCryptoPolicy: "",
+
+		// Combine static routes with same hop into larger supernets,
+		// even if these are not adjacent, as long as no other route
+		// inside the supernet uses a different hop.
+		AggregateRoutes: false,
+
+		// Show all syntax errors of all files, not only the first one.
+		AllSyntaxErrors: false,
+
 		// Optimize the number of routing entries per router:
 		// For each router find the hop, where the largest
 		// number of routing entries points to
@@ -146,20 +173,58 @@ func DefaultOptions(fs *pflag.FlagSet) *Config {
 		// which have no default route to the internet.
 		AutoDefaultRoute: true,
 
+		// Write jobs for Netspoc-API to this file, that fix warnings
+		// about useless attributes, duplicate elements, redundant rules
+		// and unused groups. Use "-" to write to STDOUT.
+		Autofix: "",
+
 		// Set value to >= 2 to start concurrent processing.
 		ConcurrencyPass1: 1,
 		ConcurrencyPass2: 1,
 
+		// Crypto policy, checked by option 'check_crypto_policy'.
+		// Empty list or value 0 doesn't restrict corresponding attribute.
+		// Allowed values of 'encryption' and 'esp_encryption'.
+		CryptoPolicyEncryption: nil,
+		// Allowed values of 'hash', 'esp_authentication' and 'ah'.
+		CryptoPolicyHash: nil,
+		// Minimum value of 'group' and 'pfs_group'.
+		CryptoPolicyMinGroup: 0,
+		// Minimum value of 'ike_version'.
+		CryptoPolicyMinIkeVersion: 0,
+		// Minimum lifetime in seconds of isakmp and ipsec.
+		CryptoPolicyMinIsakmpLifetime: 0,
+		CryptoPolicyMinIpsecLifetime:  0,
+
+		// Generate configuration of redundancy protocols VRRP and HSRP
+		// from attribute 'virtual' for models IOS and Linux.
+		GenerateRedundancy: false,
+
 		// Abort after this many errors.
 		MaxErrors: 10,
 
+		// Limit memory usage of Netspoc to this many MiB.
+		// Value > 0 enables low-memory mode, that trades speed
+		// for lower peak memory usage.
+		MemoryLimit: 0,
+
+		// Write CPU and heap profiles of pass1 and pass2 and
+		// a report with statistics of each phase into this directory.
+		ProfileDir: "",
+
 		// Print progress messages.
 		Quiet: false,
 
+		// Add names of originating services to rules of intermediate code.
+		ServiceMap: false,
+
 		// Print progress messages with time stamps.
 		// Print "finished" with time stamp when finished.
 		TimeStamps: false,
 
+		// Warn about services, that will be disabled in this many days.
+		WarnExpiringDays: 0,
+
 		// Debug pass2, argument is filename of device, e.g. NAME or ipv6/NAME.
 		DebugPass2: "",
 	}
@@ -192,15 +257,24 @@ func readConfig(filename string) (map[string]string, error) {
 	return result, nil
 }
 
+// Keys with this prefix are only read by program format-netspoc.
+const formatPrefix = "format_"
+
 // parseFile parses the specified configuration file and populates unset flags
 // in fs based on the contents of the file.
 // Hidden flags are not set from file.
+// Keys of format-netspoc are ignored.
 func parseFile(filename string, fs *pflag.FlagSet) error {
 	isSet := make(map[*pflag.Flag]bool)
 	config, err := readConfig(filename)
 	if err != nil {
 		return err
 	}
+	for name := range config {
+		if strings.HasPrefix(name, formatPrefix) {
+			delete(config, name)
+		}
+	}
 
 	fs.Visit(func(f *pflag.Flag) {
 		isSet[f] = true
@@ -248,3 +322,17 @@ func ConfigFromFile(path string) *Config {
 	AddConfigFromFile(path, fs)
 	return cnf
 }
+
+// FormatConfigFromFile returns "key = value" pairs of config file
+// in directory inDir, where key has prefix "format_".
+// Prefix is removed from key. Missing config file is ignored.
+func FormatConfigFromFile(inDir string) map[string]string {
+	config, _ := readConfig(inDir + "/config")
+	result := make(map[string]string)
+	for key, val := range config {
+		if name, found := strings.CutPrefix(key, formatPrefix); found {
+			result[name] = val
+		}
+	}
+	return result
+}
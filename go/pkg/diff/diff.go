@@ -0,0 +1,160 @@
+// Package diff generates differences between two texts
+// in unified format.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Number of unchanged lines shown before and after each change.
+const context = 3
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified returns differences of a and b in unified format.
+// Result is empty, if a and b are equal.
+func Unified(nameA, nameB string, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+	ops := editScript(splitLines(a), splitLines(b))
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+	// Line numbers in a and b at start of ops[i].
+	posA := make([]int, len(ops)+1)
+	posB := make([]int, len(ops)+1)
+	for i, o := range ops {
+		posA[i+1], posB[i+1] = posA[i], posB[i]
+		if o.kind != '+' {
+			posA[i+1]++
+		}
+		if o.kind != '-' {
+			posB[i+1]++
+		}
+	}
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Find end of hunk, joining changes with small gap.
+		start := max(0, i-context)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		end = min(len(ops), end+context)
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(posA[start], posA[end]-posA[start]),
+			hunkRange(posB[start], posB[end]-posB[start]))
+		for _, o := range ops[start:end] {
+			out.WriteByte(o.kind)
+			out.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return out.Bytes()
+}
+
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// Split text into lines, each with its trailing newline.
+func splitLines(text []byte) []string {
+	var result []string
+	for len(text) > 0 {
+		i := bytes.IndexByte(text, '\n') + 1
+		if i == 0 {
+			i = len(text)
+		}
+		result = append(result, string(text[:i]))
+		text = text[i:]
+	}
+	return result
+}
+
+// Find shortest edit script with algorithm of Eugene W. Myers,
+// "An O(ND) Difference Algorithm and Its Variations".
+func editScript(a, b []string) []op {
+	n, m := len(a), len(b)
+	off := n + m + 1
+	v := make([]int, 2*off+1)
+	var trace [][]int
+	var d int
+SEARCH:
+	for d = 0; ; d++ {
+		// Store values of diagonals -d-1 .. d+1 from previous step.
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[off+k-1] < v[off+k+1] {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break SEARCH
+			}
+		}
+	}
+	// Walk back through trace and collect operations in reverse order.
+	var rev []op
+	x, y := n, m
+	for ; d > 0; d-- {
+		t := trace[d]
+		get := func(k int) int { return t[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || k != d && get(k-1) < get(k+1) {
+			prevK = k + 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, op{' ', a[x]})
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, op{'+', b[y]})
+		} else {
+			x--
+			rev = append(rev, op{'-', a[x]})
+		}
+	}
+	for x > 0 {
+		x--
+		rev = append(rev, op{' ', a[x]})
+	}
+	ops := make([]op, len(rev))
+	for i, o := range rev {
+		ops[len(rev)-1-i] = o
+	}
+	return ops
+}
@@ -3,6 +3,7 @@ package exportsyntax
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/hknutzen/Netspoc/go/pkg/ast"
@@ -19,6 +20,8 @@ func Main(d oslink.Data) int {
 			"Usage: %s [options] netspoc-data [TYPE:NAME|TYPE: ...]\n%s",
 			d.Args[0], fs.FlagUsages())
 	}
+	withPos := fs.BoolP("position", "p", false,
+		"Add position in source file to each definition")
 	fs.BoolP("quiet", "q", false, "Flag is ignored")
 	if err := fs.Parse(d.Args[1:]); err != nil {
 		if err == pflag.ErrHelp {
@@ -39,22 +42,33 @@ func Main(d oslink.Data) int {
 	for _, name := range args[1:] {
 		filter[name] = true
 	}
-	path := args[0]
+	inPath := args[0]
 	// Group definitions by type.
 	definitions := make(map[string][]jsonMap)
-	err := filetree.Walk(path, func(input *filetree.Context) error {
+	err := filetree.Walk(inPath, func(input *filetree.Context) error {
 		source := []byte(input.Data)
 		path := input.Path
 		aF, err := parser.ParseFile(source, path, 0)
 		if err != nil {
 			return err
 		}
+		// Show name of file relative to netspoc-data.
+		if rel, err := filepath.Rel(inPath, path); err == nil && rel != "." {
+			path = rel
+		}
 		for _, node := range aF.Nodes {
 			name := node.GetName()
 			i := strings.Index(name, ":")
 			if len(filter) == 0 || filter[name[:i+1]] || filter[name] {
 				typ := name[:i]
-				definitions[typ] = append(definitions[typ], convertToMap(node))
+				m := convertToMap(node)
+				if *withPos {
+					pos := mapPosition(node)
+					pos["file"] = path
+					m["position"] = pos
+					addChildPositions(m, node)
+				}
+				definitions[typ] = append(definitions[typ], m)
 			}
 		}
 		return nil
@@ -110,6 +124,39 @@ func convertToMap(n ast.Toplevel) jsonMap {
 	return m
 }
 
+// Position is given as object with start and end,
+// each having line, column and offset.
+func mapPosition(n ast.Node) jsonMap {
+	conv := func(p ast.Position) jsonMap {
+		return jsonMap{"line": p.Line, "column": p.Column, "offset": p.Offset}
+	}
+	return jsonMap{"start": conv(n.Start()), "end": conv(n.End())}
+}
+
+// Add position to hosts of network, interfaces of router
+// and rules of service.
+func addChildPositions(m jsonMap, n ast.Toplevel) {
+	addAttr := func(key string, l []*ast.Attribute) {
+		childs, _ := m[key].(jsonMap)
+		for _, a := range l {
+			if sub, ok := childs[a.Name].(jsonMap); ok {
+				sub["position"] = mapPosition(a)
+			}
+		}
+	}
+	switch x := n.(type) {
+	case *ast.Network:
+		addAttr("hosts", x.Hosts)
+	case *ast.Router:
+		addAttr("interfaces", x.Interfaces)
+	case *ast.Service:
+		rules := m["rules"].([]jsonMap)
+		for i, r := range x.Rules {
+			rules[i]["position"] = mapPosition(r)
+		}
+	}
+}
+
 func mapRules(l []*ast.Rule) []jsonMap {
 	result := make([]jsonMap, len(l))
 	for i, r := range l {
@@ -0,0 +1,236 @@
+package extract
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/ast"
+	"github.com/hknutzen/Netspoc/go/pkg/astset"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/hknutzen/Netspoc/go/pkg/printer"
+	"github.com/spf13/pflag"
+)
+
+type state struct {
+	*astset.State
+	base string
+	// Names of toplevel definitions.
+	used map[string]bool
+	// Occurrences of element lists with same elements.
+	occurs map[string][]*occurrence
+	keys   []string
+}
+
+// Element list of group or of user, src or dst of service.
+type occurrence struct {
+	top   ast.Toplevel
+	where string
+	list  *[]ast.Element
+}
+
+func Main(d oslink.Data) int {
+	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
+
+	// Setup custom usage function.
+	fs.Usage = func() {
+		fmt.Fprintf(d.Stderr,
+			"Usage: %s [options] FILE|DIR\n%s",
+			d.Args[0], fs.FlagUsages())
+	}
+
+	// Command line flags
+	quiet := fs.BoolP("quiet", "q", false, "Don't show changed files")
+	minSize := fs.Int("min_size", 5,
+		"Extract only lists with at least this many elements")
+	minCount := fs.Int("min_count", 2,
+		"Extract only lists, that occur at least this many times")
+	suggest := fs.Bool("suggest", false,
+		"Don't change files, but print suggested groups")
+	if err := fs.Parse(d.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 1
+		}
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		fs.Usage()
+		return 1
+	}
+
+	// Argument processing
+	args := fs.Args()
+	if len(args) != 1 {
+		fs.Usage()
+		return 1
+	}
+	path := args[0]
+
+	s := &state{
+		base:   path,
+		used:   make(map[string]bool),
+		occurs: make(map[string][]*occurrence),
+	}
+	var err error
+	s.State, err = astset.Read(path)
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	s.collect(max(*minSize, 1))
+	groups := s.findGroups(max(*minCount, 2))
+	if *suggest {
+		s.printSuggestions(d, groups)
+		return 0
+	}
+	s.substitute(groups)
+	s.ShowChanged(d.Stderr, *quiet)
+	s.Print()
+	return 0
+}
+
+// Group, that replaces element lists of occurrences.
+type group struct {
+	name string
+	// Group is newly created.
+	node   *ast.TopList
+	occurs []*occurrence
+}
+
+// Collect element lists of groups and services having at least
+// minSize elements.
+func (s *state) collect(minSize int) {
+	add := func(top ast.Toplevel, where string, l *[]ast.Element) {
+		if len(*l) < minSize {
+			return
+		}
+		var names []string
+		for _, el := range *l {
+			// Group can't contain 'user'.
+			if _, ok := el.(*ast.User); ok {
+				return
+			}
+			names = append(names, el.String())
+		}
+		slices.Sort(names)
+		key := strings.Join(names, ",")
+		if s.occurs[key] == nil {
+			s.keys = append(s.keys, key)
+		}
+		s.occurs[key] = append(s.occurs[key],
+			&occurrence{top: top, where: where, list: l})
+	}
+	s.Modify(func(t ast.Toplevel) bool {
+		s.used[t.GetName()] = true
+		switch x := t.(type) {
+		case *ast.TopList:
+			if strings.HasPrefix(x.Name, "group:") {
+				add(x, "", &x.Elements)
+			}
+		case *ast.Service:
+			// Elements of 'user = foreach' can't be combined.
+			if !x.Foreach {
+				add(x, "user", &x.User.Elements)
+			}
+			for i, r := range x.Rules {
+				nr := ""
+				if len(x.Rules) > 1 {
+					nr = strconv.Itoa(i + 1)
+				}
+				add(x, "src"+nr, &r.Src.Elements)
+				add(x, "dst"+nr, &r.Dst.Elements)
+			}
+		}
+		return false
+	})
+}
+
+// Find element lists, that occur at least minCount times.
+// An existing group with identical elements is reused.
+// Otherwise a new group is created and named after first occurrence.
+func (s *state) findGroups(minCount int) []*group {
+	var result []*group
+	for _, key := range s.keys {
+		l := s.occurs[key]
+		if len(l) < minCount {
+			continue
+		}
+		g := new(group)
+		for _, o := range l {
+			if o.where == "" {
+				g.name = o.top.GetName()
+				break
+			}
+		}
+		if g.name == "" {
+			first := l[0]
+			_, name, _ := strings.Cut(first.top.GetName(), ":")
+			g.name = s.uniqueName("group:" + name + "-" + first.where)
+			g.node = new(ast.TopList)
+			g.node.Name = g.name
+			g.node.Elements = slices.Clone(*first.list)
+			g.node.Order()
+		}
+		for _, o := range l {
+			if o.top.GetName() != g.name {
+				g.occurs = append(g.occurs, o)
+			}
+		}
+		result = append(result, g)
+	}
+	return result
+}
+
+func (s *state) uniqueName(name string) string {
+	result := name
+	for i := 2; s.used[result]; i++ {
+		result = name + "_" + strconv.Itoa(i)
+	}
+	s.used[result] = true
+	return result
+}
+
+// Replace element lists by reference to group.
+// New group is added to file of first occurrence.
+func (s *state) substitute(groups []*group) {
+	changed := make(map[ast.Toplevel]bool)
+	for _, g := range groups {
+		typ, name, _ := strings.Cut(g.name, ":")
+		for _, o := range g.occurs {
+			*o.list = []ast.Element{
+				&ast.NamedRef{TypedElt: ast.TypedElt{Type: typ}, Name: name}}
+			changed[o.top] = true
+		}
+		if g.node != nil {
+			file, _ := filepath.Rel(s.base, g.occurs[0].top.FileName())
+			if file == "." {
+				file = ""
+			}
+			s.CreateToplevel(file, g.node)
+		}
+	}
+	s.Modify(func(t ast.Toplevel) bool { return changed[t] })
+}
+
+// Print definitions of new groups and show, where each group
+// would be used.
+func (s *state) printSuggestions(d oslink.Data, groups []*group) {
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(d.Stdout)
+		}
+		if g.node == nil {
+			fmt.Fprintf(d.Stdout, "# Existing %s can be used at\n", g.name)
+		} else {
+			fmt.Fprintln(d.Stdout, "# New group can be used at")
+		}
+		for _, o := range g.occurs {
+			fmt.Fprintf(d.Stdout, "# - %s\n",
+				strings.TrimSpace(o.top.GetName()+" "+o.where))
+		}
+		if g.node != nil {
+			f := &ast.File{Nodes: []ast.Toplevel{g.node}}
+			d.Stdout.Write(printer.File(f))
+		}
+	}
+}
@@ -3,7 +3,15 @@ package format
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/hknutzen/Netspoc/go/pkg/ast"
+	"github.com/hknutzen/Netspoc/go/pkg/conf"
+	"github.com/hknutzen/Netspoc/go/pkg/diff"
 	"github.com/hknutzen/Netspoc/go/pkg/fileop"
 	"github.com/hknutzen/Netspoc/go/pkg/filetree"
 	"github.com/hknutzen/Netspoc/go/pkg/oslink"
@@ -12,18 +20,82 @@ import (
 	"github.com/spf13/pflag"
 )
 
+type style struct {
+	sort  ast.SortStyle
+	print printer.Style
+}
+
+// Read style from keys "format_*" of config file in directory
+// of Netspoc configuration. Invalid keys and values are reported.
+func getStyle(dir string) (style, error) {
+	fs := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	// Number of spaces for each level of indentation.
+	indent := fs.Int("indent", 1, "")
+	// Put list of simple elements on a single line, if it fits
+	// into this many characters. Use 0 to print each element
+	// on a separate line.
+	maxLen := fs.Int("max_line_length", 0, "")
+	// Sort elements of groups and rules,
+	// hosts of networks and attributes of services.
+	sortElements := fs.Bool("sort_elements", true, "")
+	sortHosts := fs.Bool("sort_hosts", true, "")
+	sortAttributes := fs.Bool("sort_service_attributes", true, "")
+	config := conf.FormatConfigFromFile(dir)
+	var errList []string
+	for _, key := range slices.Sorted(maps.Keys(config)) {
+		val := config[key]
+		if fs.Lookup(key) == nil {
+			errList = append(errList,
+				fmt.Sprintf("bad keyword 'format_%s'", key))
+		} else if err := fs.Set(key, val); err != nil {
+			errList = append(errList,
+				fmt.Sprintf("bad value in 'format_%s = %s'", key, val))
+		}
+	}
+	if errList != nil {
+		return style{}, fmt.Errorf("Invalid line in %s:\n - %s",
+			dir+"/config", strings.Join(errList, "\n - "))
+	}
+	return style{
+		sort: ast.SortStyle{
+			KeepElements:   !*sortElements,
+			KeepHosts:      !*sortHosts,
+			KeepAttributes: !*sortAttributes,
+		},
+		print: printer.Style{
+			Indent:        *indent,
+			MaxLineLength: *maxLen,
+		},
+	}, nil
+}
+
+func formatSource(source []byte, path string, s style) ([]byte, error) {
+	aF, err := parser.ParseFile(source, path, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range aF.Nodes {
+		n.OrderWith(s.sort)
+	}
+	return printer.StyledFile(aF, s.print), nil
+}
+
 func Main(d oslink.Data) int {
 	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
 
 	// Setup custom usage function.
 	fs.Usage = func() {
 		fmt.Fprintf(d.Stderr,
-			"Usage: %s [options] FILE|DIR\n%s",
+			"Usage: %s [options] FILE|DIR|-\n%s",
 			d.Args[0], fs.FlagUsages())
 	}
 
 	// Command line flags
 	quiet := fs.BoolP("quiet", "q", false, "Don't show changed files")
+	check := fs.Bool("check", false,
+		"Don't change files, but show unformatted files and fail")
+	showDiff := fs.Bool("diff", false,
+		"Don't change files, but show differences")
 	if err := fs.Parse(d.Args[1:]); err != nil {
 		if err == pflag.ErrHelp {
 			return 1
@@ -40,23 +112,66 @@ func Main(d oslink.Data) int {
 		return 1
 	}
 
+	// Read from STDIN and write to STDOUT.
+	if args[0] == "-" {
+		source, err := io.ReadAll(d.Stdin)
+		if err != nil {
+			fmt.Fprintf(d.Stderr, "Error: Can't read STDIN: %s\n", err)
+			return 1
+		}
+		// Read style from config file in current directory.
+		style, err := getStyle(".")
+		if err != nil {
+			fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		copy, err := formatSource(source, "<stdin>", style)
+		if err != nil {
+			fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		if *showDiff {
+			d.Stdout.Write(diff.Unified("a/<stdin>", "b/<stdin>", source, copy))
+		} else if !*check {
+			d.Stdout.Write(copy)
+		}
+		if *check && !bytes.Equal(source, copy) {
+			fmt.Fprintf(d.Stderr, "Unformatted <stdin>\n")
+			return 1
+		}
+		return 0
+	}
+
 	// Process each file.
 	path := args[0]
-	err := filetree.Walk(path, func(input *filetree.Context) error {
+	style, err := getStyle(path)
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	unformatted := false
+	err = filetree.Walk(path, func(input *filetree.Context) error {
 		source := []byte(input.Data)
 		path := input.Path
-		aF, err := parser.ParseFile(source, path, parser.ParseComments)
+		copy, err := formatSource(source, path, style)
 		if err != nil {
 			return err
 		}
-		for _, n := range aF.Nodes {
-			n.Order()
-		}
-		copy := printer.File(aF)
-
 		if bytes.Equal(source, copy) {
 			return nil
 		}
+		if *check || *showDiff {
+			if *check {
+				fmt.Fprintf(d.Stderr, "Unformatted %s\n", path)
+				unformatted = true
+			}
+			if *showDiff {
+				// Show name relative to directory of argument.
+				name, _ := filepath.Rel(filepath.Dir(args[0]), path)
+				d.Stdout.Write(diff.Unified("a/"+name, "b/"+name, source, copy))
+			}
+			return nil
+		}
 		if !*quiet {
 			fmt.Fprintf(d.Stderr, "Changed %s\n", path)
 		}
@@ -67,5 +182,8 @@ func Main(d oslink.Data) int {
 		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
 		return 1
 	}
+	if unformatted {
+		return 1
+	}
 	return 0
 }
@@ -0,0 +1,567 @@
+package importer
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/ast"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/spf13/pflag"
+)
+
+type asaState struct {
+	*state
+	cfg *asaConfig
+	// Router of ASA and name of router without prefix "router:".
+	router *ast.Router
+	rName  string
+	// IPv4 and IPv6 interface of ASA router for each nameif.
+	nameif map[string][2]*ast.Attribute
+	// Unmanaged router, that connects network learned from route.
+	gateway map[*network]*ast.Router
+	// Netspoc names of ASA objects and object-groups.
+	objects   map[string]string
+	groups    map[string]string
+	prtGroups map[string]string
+}
+
+func (s *asaState) setupRouter() {
+	cfg := s.cfg
+	hostname := cfg.hostname
+	if hostname == "" {
+		hostname = "asa"
+	}
+	s.rName = sanitize(hostname)
+	name := "router:" + s.rName
+	if r, ok := s.FindToplevel(name).(*ast.Router); ok {
+		// Find interfaces of existing router by hardware name.
+		s.router = r
+		for _, intf := range r.Interfaces {
+			hw := intf.GetAttr("hardware")
+			if hw == nil || len(hw.ValueList) != 1 {
+				continue
+			}
+			nameif := hw.ValueList[0].Value
+			l := s.nameif[nameif]
+			if intf.GetAttr("ip6") != nil {
+				l[1] = intf
+			} else {
+				l[0] = intf
+			}
+			s.nameif[nameif] = l
+		}
+		return
+	}
+	r := new(ast.Router)
+	r.Name = s.uniqueName("router", s.rName)
+	s.rName = strings.TrimPrefix(r.Name, "router:")
+	r.Attributes = []*ast.Attribute{
+		{Name: "managed"},
+		ast.CreateAttr1("model", "ASA"),
+	}
+	for _, intf := range cfg.interfaces {
+		if intf.nameif == "" {
+			continue
+		}
+		var l [2]*ast.Attribute
+		for i, p := range []netip.Prefix{intf.ip, intf.ip6} {
+			if !p.IsValid() {
+				continue
+			}
+			n := s.exactNetwork(p.Masked())
+			if n == nil {
+				name := intf.nameif
+				if i == 1 {
+					name += "_v6"
+				}
+				n = s.addNetwork(name, p.Masked())
+			}
+			a := intfAttr(n)
+			ip := p.Addr()
+			a.ComplexValue = []*ast.Attribute{
+				ast.CreateAttr1(ipAttr("ip", ip), ip.String()),
+				ast.CreateAttr1("hardware", intf.nameif),
+			}
+			r.Interfaces = append(r.Interfaces, a)
+			s.intfs[ip] = "interface:" + s.rName + "." +
+				strings.TrimPrefix(a.Name, "interface:")
+			s.intfRouter[ip] = r
+			l[i] = a
+		}
+		s.nameif[intf.nameif] = l
+	}
+	s.router = r
+	s.newNodes = append(s.newNodes, r)
+}
+
+// Find network at interface of ASA, that contains ip.
+func (s *asaState) intfNetwork(nameif string, ip netip.Addr) *network {
+	i := 0
+	if ip.Is6() {
+		i = 1
+	}
+	if intf := s.nameif[nameif][i]; intf != nil {
+		name := "network:" + strings.TrimPrefix(intf.Name, "interface:")
+		for _, n := range s.networks {
+			if n.name == name && n.prefix.Contains(ip) {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// Add networks learned from routes. Each network is connected to an
+// unmanaged router at the next hop.
+func (s *asaState) convertRoutes() {
+	routes := slices.Clone(s.cfg.routes)
+	slices.SortStableFunc(routes, func(a, b *asaRoute) int {
+		return a.dst.Bits() - b.dst.Bits()
+	})
+	var routed []*network
+	for _, r := range routes {
+		dst := r.dst.Masked()
+		if s.exactNetwork(dst) != nil {
+			continue
+		}
+		n := s.intfNetwork(r.nameif, r.hop)
+		if n == nil {
+			s.warn("Ignoring route to %s, next hop %s isn't located at %s",
+				dst, r.hop, r.nameif)
+			continue
+		}
+		gw := s.intfRouter[r.hop]
+		if gw == nil {
+			ip := r.hop
+			gw = new(ast.Router)
+			gw.Name = s.uniqueName("router",
+				"gw_"+ipName(netip.PrefixFrom(ip, ip.BitLen())))
+			a := intfAttr(n)
+			a.ComplexValue = []*ast.Attribute{
+				ast.CreateAttr1(ipAttr("ip", ip), ip.String())}
+			gw.Interfaces = []*ast.Attribute{a}
+			s.intfs[ip] = "interface:" + strings.TrimPrefix(gw.Name, "router:") +
+				"." + strings.TrimPrefix(a.Name, "interface:")
+			s.intfRouter[ip] = gw
+			s.newNodes = append(s.newNodes, gw)
+		} else if gw.GetAttr("managed") != nil {
+			s.warn("Ignoring route to %s, next hop %s is managed",
+				dst, r.hop)
+			continue
+		}
+		// Network is already reachable by same next hop.
+		if big := s.findNetwork(dst); big != nil && s.gateway[big] == gw {
+			continue
+		}
+		name := "n_" + ipName(dst)
+		if dst.Bits() == 0 {
+			name = "internet"
+			if dst.Addr().Is6() {
+				name += "_v6"
+			}
+		}
+		rn := s.addNetwork(name, dst)
+		gw.Interfaces = append(gw.Interfaces, intfAttr(rn))
+		s.changed[gw] = true
+		s.gateway[rn] = gw
+		routed = append(routed, rn)
+	}
+	// Other networks, that are located inside some network learned from
+	// route, need attribute 'subnet_of'.
+	for _, n := range s.networks {
+		if n.prefix.Bits() == 0 || n.node.GetAttr("subnet_of") != nil {
+			continue
+		}
+		var big *network
+		for _, r := range routed {
+			if r.prefix.Bits() < n.prefix.Bits() &&
+				r.prefix.Contains(n.prefix.Addr()) &&
+				(big == nil || r.prefix.Bits() > big.prefix.Bits()) {
+				big = r
+			}
+		}
+		if big != nil {
+			n.node.Attributes = append(n.node.Attributes,
+				ast.CreateAttr1("subnet_of", big.name))
+			s.changed[n.node] = true
+		}
+	}
+}
+
+// Find or add Netspoc object for IP address, subnet or range.
+func (s *asaState) lookup(a *address, objName string) string {
+	if a.kind == rangeAddr {
+		return s.state.lookup(a.prefix.Addr(), a.last, objName)
+	}
+	p := a.prefix.Masked()
+	return s.state.lookup(p.Addr(), lastAddr(p), objName)
+}
+
+func (s *asaState) object(name string) string {
+	if n, found := s.objects[name]; found {
+		return n
+	}
+	obj := s.cfg.netObjects[name]
+	result := ""
+	if obj == nil || obj.addr == nil {
+		s.warn("Ignoring unknown object %s", name)
+	} else {
+		result = s.lookup(obj.addr, obj.name)
+	}
+	s.objects[name] = result
+	return result
+}
+
+func (s *asaState) group(name string) string {
+	if n, found := s.groups[name]; found {
+		return n
+	}
+	g := s.cfg.netGroups[name]
+	if g == nil {
+		s.warn("Ignoring unknown object-group %s", name)
+		s.groups[name] = ""
+		return ""
+	}
+	n := new(ast.TopList)
+	n.Name = s.uniqueName("group", name)
+	s.groups[name] = n.Name
+	if d := g.description; d != "" {
+		n.Description = &ast.Description{Text: d}
+	}
+	var l []string
+	for _, a := range g.elements {
+		l = append(l, s.address(a))
+	}
+	n.Elements = elemList(l)
+	n.Order()
+	s.newNodes = append(s.newNodes, n)
+	return n.Name
+}
+
+func (s *asaState) address(a *address) string {
+	switch a.kind {
+	case objectAddr:
+		return s.object(a.name)
+	case groupAddr:
+		return s.group(a.name)
+	default:
+		return s.lookup(a, "")
+	}
+}
+
+func (s *asaState) protocolgroup(name string) string {
+	if n, found := s.prtGroups[name]; found {
+		return n
+	}
+	g := s.cfg.svcGroups[name]
+	if g == nil {
+		s.warn("Ignoring unknown object-group %s", name)
+		s.prtGroups[name] = ""
+		return ""
+	}
+	n := new(ast.Protocolgroup)
+	n.Name = s.uniqueName("protocolgroup", name)
+	s.prtGroups[name] = n.Name
+	if d := g.description; d != "" {
+		n.Description = &ast.Description{Text: d}
+	}
+	var l []string
+	for _, p := range g.prts {
+		if p.src != "" {
+			l = append(l, s.protocol(p.String()))
+		} else {
+			l = append(l, p.String())
+		}
+	}
+	for _, proto := range g.portProto {
+		for _, port := range g.ports {
+			l = append(l, proto+" "+port)
+		}
+	}
+	if g.typ == icmpGroup {
+		for _, t := range g.ports {
+			l = append(l, "icmp "+t)
+		}
+	}
+	for _, sub := range g.groups {
+		if n := s.protocolgroup(sub); n != "" {
+			l = append(l, n)
+		}
+	}
+	for _, v := range l {
+		n.ValueList = append(n.ValueList, &ast.Value{Value: v})
+	}
+	n.Order()
+	s.newNodes = append(s.newNodes, n)
+	return n.Name
+}
+
+// Find Netspoc elements for address of access-list.
+// Address 'any' of incoming source or outgoing destination is
+// converted to zone at interface of access-group.
+func (s *asaState) aclElements(a *address, g *accessGroup, isSrc bool) []string {
+	switch a.kind {
+	case anyAddr, any4Addr, any6Addr:
+		all := "any:[interface:" + s.rName + ".[all]]"
+		l := s.nameif[g.nameif]
+		var zones []string
+		for i, intf := range l {
+			if intf != nil &&
+				(a.kind == anyAddr || a.kind == any4Addr && i == 0 ||
+					a.kind == any6Addr && i == 1) {
+				zones = append(zones, "any:["+s.intfName(intf)+"]")
+			}
+		}
+		if zones == nil {
+			return []string{all}
+		}
+		if g.in == isSrc {
+			return zones
+		}
+		// Other side of access-list doesn't include zone at interface.
+		return []string{all + " &! " + strings.Join(zones, " &! ")}
+	case intfAddr:
+		var result []string
+		for _, intf := range s.nameif[a.name] {
+			if intf != nil {
+				result = append(result, s.intfName(intf))
+			}
+		}
+		if result == nil {
+			s.warn("Ignoring unknown interface %s", a.name)
+		}
+		return result
+	}
+	return []string{s.address(a)}
+}
+
+func (s *asaState) intfName(intf *ast.Attribute) string {
+	return "interface:" + s.rName + "." +
+		strings.TrimPrefix(intf.Name, "interface:")
+}
+
+// Convert each entry of access-list, that is bound to some interface,
+// to a separate service.
+func (s *asaState) convertACLs() {
+	seen := make(map[string]bool)
+	for _, g := range s.cfg.accessGroups {
+		if seen[g.acl] {
+			continue
+		}
+		seen[g.acl] = true
+		a := s.cfg.acls[g.acl]
+		if a == nil {
+			s.warn("Ignoring unknown access-list %s", g.acl)
+			continue
+		}
+		for i, e := range a.entries {
+			s.convertACE(fmt.Sprintf("%s-%d", a.name, i+1), e, g)
+		}
+	}
+}
+
+func (s *asaState) convertACE(name string, e *ace, g *accessGroup) {
+	var src, dst []string
+	src = s.aclElements(e.src, g, true)
+	dst = s.aclElements(e.dst, g, false)
+	var prts []string
+	for _, r := range e.prts {
+		if r.group != "" {
+			prts = append(prts, s.protocolgroup(r.group))
+		} else if r.prt.src != "" {
+			prts = append(prts, s.protocol(r.prt.String()))
+		} else {
+			prts = append(prts, r.prt.String())
+		}
+	}
+	svc := s.addService(name, e.deny, src, dst, prts)
+	if svc == nil {
+		s.warn("Ignoring incomplete line %d of access-list %s", e.line, g.acl)
+		return
+	}
+	if e.deny {
+		s.warn("Rule with 'deny' from line %d has precedence over"+
+			" all rules with 'permit' in Netspoc", e.line)
+	}
+	if e.remark != "" {
+		svc.Description = &ast.Description{Text: e.remark}
+	}
+	if e.inactive {
+		svc.Attributes = []*ast.Attribute{{Name: "disabled"}}
+	}
+}
+
+// Convert NAT of network to attribute 'nat:TAG' of network and bind
+// TAG at interface of mapped side.
+func (s *asaState) convertNAT() {
+	for _, n := range s.cfg.nats {
+		ctx := fmt.Sprintf("NAT in line %d", n.line)
+		if n.realAddr.kind != objectAddr {
+			s.warn("Ignoring %s with real address 'any'", ctx)
+			continue
+		}
+		obj := s.cfg.netObjects[n.realAddr.name]
+		var net *network
+		if obj != nil && obj.addr != nil && obj.addr.kind == prefixAddr {
+			net = s.exactNetwork(obj.addr.prefix.Masked())
+		}
+		if net == nil {
+			s.warn("Ignoring %s, real address is no known network", ctx)
+			continue
+		}
+		var mapped netip.Prefix
+		switch m := n.mappedAddr; m.kind {
+		case intfAddr:
+			i := 0
+			if net.prefix.Addr().Is6() {
+				i = 1
+			}
+			intf := s.nameif[m.name][i]
+			if intf == nil {
+				s.warn("Ignoring %s with unknown interface %s", ctx, m.name)
+				continue
+			}
+			ip, _ := netip.ParseAddr(intf.GetAttr(ipAttr("ip", net.prefix.Addr())).
+				ValueList[0].Value)
+			mapped = netip.PrefixFrom(ip, ip.BitLen())
+		case objectAddr:
+			mObj := s.cfg.netObjects[m.name]
+			if mObj.addr == nil || mObj.addr.kind != prefixAddr {
+				s.warn("Ignoring %s, mapped address is no subnet", ctx)
+				continue
+			}
+			mapped = mObj.addr.prefix
+		default:
+			mapped = m.prefix
+		}
+		if !n.dynamic {
+			if mapped.IsSingleIP() {
+				mapped = netip.PrefixFrom(mapped.Addr(), net.prefix.Bits())
+			}
+			if mapped.Bits() != net.prefix.Bits() {
+				s.warn("Ignoring %s, real and mapped address differ in size", ctx)
+				continue
+			}
+		}
+		tag := sanitize(n.mapped)
+		node := net.node
+		if node.GetAttr("nat:"+tag) != nil {
+			s.warn("Ignoring %s, %s already has nat:%s", ctx, net.name, tag)
+			continue
+		}
+		a := &ast.Attribute{Name: "nat:" + tag}
+		a.ComplexValue = []*ast.Attribute{
+			ast.CreateAttr1(ipAttr("ip", mapped.Addr()), mapped.Masked().String())}
+		if n.dynamic {
+			a.ComplexValue = append(a.ComplexValue, &ast.Attribute{Name: "dynamic"})
+		}
+		if big := s.findNetwork(mapped); big != nil {
+			a.ComplexValue = append(a.ComplexValue,
+				ast.CreateAttr1("subnet_of", big.name))
+		}
+		node.Attributes = append(node.Attributes, a)
+		s.changed[node] = true
+		s.bindNAT(n.mapped, tag, net.prefix.Addr().Is6())
+	}
+}
+
+func (s *asaState) bindNAT(nameif, tag string, v6 bool) {
+	i := 0
+	if v6 {
+		i = 1
+	}
+	intf := s.nameif[nameif][i]
+	if intf == nil {
+		s.warn("Can't bind nat:%s to unknown interface %s", tag, nameif)
+		return
+	}
+	if a := intf.GetAttr("nat_out"); a != nil {
+		for _, v := range a.ValueList {
+			if v.Value == tag {
+				return
+			}
+		}
+		a.ValueList = append(a.ValueList, &ast.Value{Value: tag})
+		a.Order()
+	} else {
+		intf.ComplexValue = append(intf.ComplexValue,
+			ast.CreateAttr1("nat_out", tag))
+	}
+	s.changed[s.router] = true
+}
+
+func (s *asaState) process() {
+	s.setupRouter()
+	s.convertRoutes()
+	s.convertNAT()
+	s.convertACLs()
+}
+
+func ImportASAMain(d oslink.Data) int {
+	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
+
+	// Setup custom usage function.
+	fs.Usage = func() {
+		fmt.Fprintf(d.Stderr,
+			"Usage: %s [options] FILE|DIR ASA-CONFIG\n%s",
+			d.Args[0], fs.FlagUsages())
+	}
+
+	// Command line flags
+	quiet := fs.BoolP("quiet", "q", false, "Don't show changed files")
+	file := fs.StringP("file", "f", "import-asa",
+		"Add new definitions to this file, if DIR is given")
+	if err := fs.Parse(d.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 1
+		}
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		fs.Usage()
+		return 1
+	}
+
+	// Argument processing
+	args := fs.Args()
+	if len(args) != 2 {
+		fs.Usage()
+		return 1
+	}
+	netspocPath := args[0]
+	asaPath := args[1]
+
+	data, err := os.ReadFile(asaPath)
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: Can't %s\n", err)
+		return 1
+	}
+	cfg, err := parseConfig(string(data))
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s of %s\n", err, asaPath)
+		return 1
+	}
+	ns, err := readNetspoc(netspocPath, d.Stderr)
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	s := &asaState{
+		state:     ns,
+		cfg:       cfg,
+		nameif:    make(map[string][2]*ast.Attribute),
+		gateway:   make(map[*network]*ast.Router),
+		objects:   make(map[string]string),
+		groups:    make(map[string]string),
+		prtGroups: make(map[string]string),
+	}
+	for _, w := range cfg.warnings {
+		s.warn("%s", w)
+	}
+	s.process()
+	s.addNodes(netspocPath, *file)
+	s.ShowChanged(d.Stderr, *quiet)
+	s.Print()
+	return 0
+}
@@ -0,0 +1,304 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/ast"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/spf13/pflag"
+)
+
+type iptState struct {
+	*state
+	cfg *iptConfig
+	// Name of router without prefix "router:".
+	rName string
+	// Names of IPv4 and IPv6 interfaces of router.
+	intfNames [2][]string
+	// Rules, that have already been reported.
+	reported map[*iptRule]bool
+}
+
+// Packets matching conditions of rule in chain are accepted or dropped.
+type flow struct {
+	// Builtin chain, where packet enters.
+	base string
+	rule *iptRule
+	m    *match
+	deny bool
+}
+
+var builtinChains = []string{"INPUT", "FORWARD", "OUTPUT"}
+
+func (s *iptState) report(r *iptRule, format string, args ...any) {
+	if !s.reported[r] {
+		s.reported[r] = true
+		s.warn("Can't convert rule in line %d: "+format,
+			append([]any{r.line}, args...)...)
+	}
+}
+
+// Follow jumps into user defined chains and collect rules with
+// terminating target.
+// Conversion of a chain stops at a conditional RETURN and at a rule
+// with target DROP, REJECT, RETURN or user defined chain, that can't
+// be converted. All following
+// rules of this chain and of calling chains are reported.
+// Rules following an unconditional DROP or REJECT are unreachable
+// and silently ignored.
+func (s *iptState) flatten() []*flow {
+	var result []*flow
+	// Returns line of rule, where conversion stopped, -1 if remaining
+	// rules are unreachable and 0 if all rules have been processed.
+	var walk func(base, chain string, ctx *match, stack []string) int
+	walk = func(base, chain string, ctx *match, stack []string) int {
+		rules := s.cfg.chains[chain]
+		skip := func(i, line int) int {
+			if line > 0 {
+				for _, r := range rules[i+1:] {
+					switch r.target {
+					case "", "LOG", "NFLOG", "ULOG":
+					default:
+						if !r.established {
+							s.report(r, "follows unconverted rule in line %d", line)
+						}
+					}
+				}
+			}
+			return line
+		}
+		for i, r := range rules {
+			if r.established {
+				continue
+			}
+			if r.unsupported != nil {
+				s.report(r, "%s", strings.Join(r.unsupported, ", "))
+				if _, found := s.cfg.chains[r.target]; found {
+					return skip(i, r.line)
+				}
+				switch r.target {
+				case "DROP", "REJECT", "RETURN":
+					return skip(i, r.line)
+				}
+				continue
+			}
+			m := ctx.combine(r.m)
+			if m == nil {
+				continue
+			}
+			switch r.target {
+			case "ACCEPT":
+				result = append(result, &flow{base: base, rule: r, m: m})
+			case "DROP", "REJECT":
+				if m.isAny() {
+					return -1
+				}
+				result = append(result,
+					&flow{base: base, rule: r, m: m, deny: true})
+			case "RETURN":
+				if r.m.isAny() {
+					return 0
+				}
+				s.report(r, "conditional RETURN")
+				return skip(i, r.line)
+			case "", "LOG", "NFLOG", "ULOG":
+			default:
+				if _, found := s.cfg.chains[r.target]; !found {
+					s.report(r, "target %s", r.target)
+				} else if slices.Contains(stack, r.target) {
+					s.report(r, "loop at chain %s", r.target)
+				} else if line :=
+					walk(base, r.target, m, append(stack, r.target)); line != 0 {
+					return skip(i, line)
+				}
+			}
+		}
+		return 0
+	}
+	for _, c := range builtinChains {
+		if s.cfg.policy[c] == "ACCEPT" {
+			s.warn("Ignoring policy ACCEPT of chain %s", c)
+		}
+		walk(c, c, new(match), []string{c})
+	}
+	return result
+}
+
+// Collect names of IPv4 and IPv6 interfaces of router.
+func (s *iptState) setupRouter(r *ast.Router) {
+	for _, intf := range r.Interfaces {
+		i := 0
+		if intf.GetAttr("ip6") != nil {
+			i = 1
+		}
+		s.intfNames[i] = append(s.intfNames[i], "interface:"+s.rName+"."+
+			strings.TrimPrefix(intf.Name, "interface:"))
+	}
+}
+
+// Find Netspoc elements for address of rule.
+// Missing address is converted to all zones at router or to all
+// interfaces of router, if packet is sent to or from router itself.
+func (s *iptState) elements(r ipRange, own bool) []string {
+	if !r.isAny() {
+		return []string{s.lookup(r.first, r.last, "")}
+	}
+	v := 0
+	if s.cfg.ipv6 {
+		v = 1
+	}
+	all := "interface:" + s.rName + ".[all]"
+	if s.intfNames[1-v] == nil {
+		if own {
+			return []string{all}
+		}
+		return []string{"any:[" + all + "]"}
+	}
+	// Router has interfaces of other IP version, that must not be
+	// referenced.
+	if own {
+		return s.intfNames[v]
+	}
+	var result []string
+	for _, name := range s.intfNames[v] {
+		result = append(result, "any:["+name+"]")
+	}
+	return result
+}
+
+func (s *iptState) protocols(m *match) []string {
+	switch m.proto {
+	case "":
+		return []string{"ip"}
+	case "tcp", "udp":
+		dports := m.dport
+		if dports == nil {
+			dports = []portRange{{1, 65535}}
+		}
+		var result []string
+		for _, dp := range dports {
+			d := m.proto
+			if dp != (portRange{1, 65535}) {
+				d += " " + dp.String()
+			}
+			if m.sport == nil {
+				result = append(result, d)
+				continue
+			}
+			for _, sp := range m.sport {
+				result = append(result,
+					s.protocol(m.proto+" "+sp.String()+":"+dp.String()))
+			}
+		}
+		return result
+	case "icmp", "icmpv6":
+		if m.icmp != "" {
+			return []string{m.proto + " " + m.icmp}
+		}
+		return []string{m.proto}
+	}
+	return []string{"proto " + m.proto}
+}
+
+// Convert each flow to a separate service.
+func (s *iptState) convertFlow(f *flow) {
+	r := f.rule
+	src := s.elements(f.m.src, f.base == "OUTPUT")
+	dst := s.elements(f.m.dst, f.base == "INPUT")
+	name := fmt.Sprintf("%s-%s-%d", s.rName, r.chain, r.num)
+	if r.chain != f.base {
+		name = fmt.Sprintf("%s-%s-%s-%d", s.rName, f.base, r.chain, r.num)
+	}
+	svc := s.addService(name, f.deny, src, dst, s.protocols(f.m))
+	if svc == nil {
+		s.report(r, "unknown address")
+		return
+	}
+	if f.deny {
+		s.warn("Rule with '%s' from line %d has precedence over"+
+			" all rules with 'permit' in Netspoc", r.target, r.line)
+	}
+	if f.m.comment != "" {
+		svc.Description = &ast.Description{Text: f.m.comment}
+	}
+}
+
+func ImportIptablesMain(d oslink.Data) int {
+	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
+
+	// Setup custom usage function.
+	fs.Usage = func() {
+		fmt.Fprintf(d.Stderr,
+			"Usage: %s [options] FILE|DIR ROUTER IPTABLES-SAVE ...\n%s",
+			d.Args[0], fs.FlagUsages())
+	}
+
+	// Command line flags
+	quiet := fs.BoolP("quiet", "q", false, "Don't show changed files")
+	file := fs.StringP("file", "f", "import-iptables",
+		"Add new definitions to this file, if DIR is given")
+	if err := fs.Parse(d.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 1
+		}
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		fs.Usage()
+		return 1
+	}
+
+	// Argument processing
+	args := fs.Args()
+	if len(args) < 3 {
+		fs.Usage()
+		return 1
+	}
+	netspocPath := args[0]
+	rName := strings.TrimPrefix(args[1], "router:")
+
+	var cfgs []*iptConfig
+	for _, p := range args[2:] {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			fmt.Fprintf(d.Stderr, "Error: Can't %s\n", err)
+			return 1
+		}
+		cfg, err := parseIptables(string(data))
+		if err != nil {
+			fmt.Fprintf(d.Stderr, "Error: %s of %s\n", err, p)
+			return 1
+		}
+		cfgs = append(cfgs, cfg)
+	}
+	ns, err := readNetspoc(netspocPath, d.Stderr)
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	router, ok := ns.FindToplevel("router:" + rName).(*ast.Router)
+	if !ok {
+		fmt.Fprintf(d.Stderr, "Error: Can't find router:%s\n", rName)
+		return 1
+	}
+	for _, cfg := range cfgs {
+		s := &iptState{
+			state:    ns,
+			cfg:      cfg,
+			rName:    rName,
+			reported: make(map[*iptRule]bool),
+		}
+		s.setupRouter(router)
+		for _, w := range cfg.warnings {
+			s.warn("%s", w)
+		}
+		for _, f := range s.flatten() {
+			s.convertFlow(f)
+		}
+	}
+	ns.addNodes(netspocPath, *file)
+	ns.ShowChanged(d.Stderr, *quiet)
+	ns.Print()
+	return 0
+}
@@ -0,0 +1,348 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/ast"
+	"github.com/hknutzen/Netspoc/go/pkg/astset"
+	"github.com/hknutzen/Netspoc/go/pkg/parser"
+)
+
+// State of Netspoc configuration, that is augmented by definitions
+// converted from configuration of some device.
+type state struct {
+	*astset.State
+	stderr io.Writer
+	// Names of toplevel definitions and of hosts.
+	used     map[string]bool
+	networks []*network
+	// Name of host by IP address or by range "IP1-IP2".
+	hosts map[string]string
+	// Name of interface and its router by IP address.
+	intfs      map[netip.Addr]string
+	intfRouter map[netip.Addr]*ast.Router
+	// Name of protocol by its value.
+	protocols map[string]string
+	// Existing definitions, that have been changed.
+	changed map[ast.Toplevel]bool
+	// New definitions.
+	newNodes []ast.Toplevel
+}
+
+// Read Netspoc configuration and collect its networks, hosts and
+// interfaces.
+func readNetspoc(path string, stderr io.Writer) (*state, error) {
+	s := &state{
+		stderr:     stderr,
+		used:       make(map[string]bool),
+		hosts:      make(map[string]string),
+		intfs:      make(map[netip.Addr]string),
+		intfRouter: make(map[netip.Addr]*ast.Router),
+		protocols:  make(map[string]string),
+		changed:    make(map[ast.Toplevel]bool),
+	}
+	var err error
+	s.State, err = astset.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	s.collect()
+	return s, nil
+}
+
+type network struct {
+	name   string
+	prefix netip.Prefix
+	node   *ast.Network
+}
+
+func (s *state) warn(format string, args ...any) {
+	fmt.Fprintf(s.stderr, "Warning: "+format+"\n", args...)
+}
+
+// Replace characters not valid in Netspoc names.
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9',
+			r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// Find name not used by some other definition.
+func (s *state) uniqueName(typ, name string) string {
+	base := typ + ":" + sanitize(name)
+	result := base
+	for i := 2; s.used[result]; i++ {
+		result = base + "_" + strconv.Itoa(i)
+	}
+	s.used[result] = true
+	return result
+}
+
+// Name derived from IP address, e.g. 10_1_2_0_24.
+func ipName(p netip.Prefix) string {
+	n := strings.NewReplacer(".", "_", ":", "_").Replace(p.Addr().String())
+	if !p.IsSingleIP() {
+		n += "_" + strconv.Itoa(p.Bits())
+	}
+	return n
+}
+
+// Attribute name "ip" or "ip6", "range" or "range6".
+func ipAttr(name string, ip netip.Addr) string {
+	if ip.Is6() {
+		return name + "6"
+	}
+	return name
+}
+
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	ip, _ := netip.AddrFromSlice(b)
+	return ip
+}
+
+func elem(name string) ast.Element {
+	l, err := parser.ParseUnion([]byte(name))
+	if err != nil {
+		panic(err)
+	}
+	return l[0]
+}
+
+func elemList(names []string) []ast.Element {
+	var result []ast.Element
+	for _, name := range names {
+		if name != "" {
+			result = append(result, elem(name))
+		}
+	}
+	return result
+}
+
+// Collect networks, hosts and interfaces of existing Netspoc
+// configuration.
+func (s *state) collect() {
+	s.Modify(func(t ast.Toplevel) bool {
+		s.used[t.GetName()] = true
+		switch x := t.(type) {
+		case *ast.Network:
+			for _, attr := range []string{"ip", "ip6"} {
+				if p, err := netip.ParsePrefix(x.GetAttr1(attr)); err == nil {
+					s.networks = append(s.networks,
+						&network{name: x.Name, prefix: p.Masked(), node: x})
+				}
+			}
+			for _, h := range x.Hosts {
+				s.used[h.Name] = true
+				for _, a := range h.ComplexValue {
+					if len(a.ValueList) != 1 {
+						continue
+					}
+					v := strings.ReplaceAll(a.ValueList[0].Value, " ", "")
+					switch a.Name {
+					case "ip", "ip6", "range", "range6":
+						s.hosts[v] = h.Name
+					}
+				}
+			}
+		case *ast.Router:
+			rName := strings.TrimPrefix(x.Name, "router:")
+			for _, intf := range x.Interfaces {
+				name := "interface:" + rName + "." +
+					strings.TrimPrefix(intf.Name, "interface:")
+				for _, a := range intf.ComplexValue {
+					if a.Name != "ip" && a.Name != "ip6" {
+						continue
+					}
+					for _, v := range a.ValueList {
+						if ip, err := netip.ParseAddr(v.Value); err == nil {
+							s.intfs[ip] = name
+							s.intfRouter[ip] = x
+						}
+					}
+				}
+			}
+		}
+		return false
+	})
+}
+
+// Find network with exactly this address.
+func (s *state) exactNetwork(p netip.Prefix) *network {
+	for _, n := range s.networks {
+		if n.prefix == p {
+			return n
+		}
+	}
+	return nil
+}
+
+// Find smallest network, that is larger than p and contains p.
+func (s *state) findNetwork(p netip.Prefix) *network {
+	var result *network
+	for _, n := range s.networks {
+		if n.prefix.Bits() < p.Bits() && n.prefix.Contains(p.Addr()) &&
+			(result == nil || n.prefix.Bits() > result.prefix.Bits()) {
+			result = n
+		}
+	}
+	return result
+}
+
+func (s *state) addNetwork(name string, p netip.Prefix) *network {
+	node := new(ast.Network)
+	node.Name = s.uniqueName("network", name)
+	node.Attributes = []*ast.Attribute{
+		ast.CreateAttr1(ipAttr("ip", p.Addr()), p.String())}
+	n := &network{name: node.Name, prefix: p, node: node}
+	s.networks = append(s.networks, n)
+	s.newNodes = append(s.newNodes, node)
+	return n
+}
+
+func intfAttr(n *network) *ast.Attribute {
+	return &ast.Attribute{
+		Name: "interface:" + strings.TrimPrefix(n.name, "network:")}
+}
+
+// Protocol with source port must be defined as named protocol.
+func (s *state) protocol(prt string) string {
+	if n, found := s.protocols[prt]; found {
+		return n
+	}
+	n := new(ast.Protocol)
+	n.Name = s.uniqueName("protocol", prt)
+	n.Value = prt
+	s.protocols[prt] = n.Name
+	s.newNodes = append(s.newNodes, n)
+	return n.Name
+}
+
+// Find or add Netspoc object for range of IP addresses.
+// Address of known network is converted to this network.
+// Other addresses inside of some network are added as host.
+func (s *state) lookup(first, last netip.Addr, objName string) string {
+	if p, ok := rangePrefix(first, last); ok {
+		if n := s.exactNetwork(p); n != nil {
+			return n.name
+		}
+	}
+	key := first.String()
+	if first != last {
+		key += "-" + last.String()
+	} else if name := s.intfs[first]; name != "" {
+		return name
+	}
+	if name := s.hosts[key]; name != "" {
+		return name
+	}
+	n := s.findNetwork(netip.PrefixFrom(first, first.BitLen()))
+	if n == nil || !n.prefix.Contains(last) {
+		s.warn("No network found for %s", key)
+		return ""
+	}
+	attr := "ip"
+	name := "h_" + ipName(netip.PrefixFrom(first, first.BitLen()))
+	if first != last {
+		attr = "range"
+		name = "r_" + strings.NewReplacer(".", "_", ":", "_", "-", "_").
+			Replace(key)
+	}
+	if objName != "" {
+		name = objName
+	}
+	h := &ast.Attribute{
+		Name: s.uniqueName("host", name),
+		ComplexValue: []*ast.Attribute{
+			ast.CreateAttr1(ipAttr(attr, first), key)},
+	}
+	n.node.Hosts = append(n.node.Hosts, h)
+	s.changed[n.node] = true
+	s.hosts[key] = h.Name
+	return h.Name
+}
+
+// Find prefix, that covers exactly the range from first to last.
+func rangePrefix(first, last netip.Addr) (netip.Prefix, bool) {
+	for bits := first.BitLen(); bits >= 0; bits-- {
+		p := netip.PrefixFrom(first, bits)
+		if p.Masked().Addr() != first {
+			break
+		}
+		if lastAddr(p) == last {
+			return p, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// Add service with single rule.
+// Source and destination are given as names of elements.
+// Returns nil, if source, destination or protocols are empty.
+func (s *state) addService(
+	name string, deny bool, src, dst, prts []string) *ast.Service {
+
+	prts = slices.DeleteFunc(prts, func(p string) bool { return p == "" })
+	srcL, dstL := elemList(src), elemList(dst)
+	if srcL == nil || dstL == nil || prts == nil {
+		return nil
+	}
+	svc := new(ast.Service)
+	svc.Name = s.uniqueName("service", name)
+	svc.User = &ast.NamedUnion{Name: "user", Elements: srcL}
+	svc.Rules = []*ast.Rule{{
+		Deny: deny,
+		Src:  &ast.NamedUnion{Name: "src", Elements: []ast.Element{new(ast.User)}},
+		Dst:  &ast.NamedUnion{Name: "dst", Elements: dstL},
+		Prt:  ast.CreateAttr("prt", prts),
+	}}
+	s.newNodes = append(s.newNodes, svc)
+	return svc
+}
+
+var typeOrder = []string{
+	"network", "router", "group", "protocol", "protocolgroup", "service"}
+
+// Add new definitions ordered by type and mark changed definitions.
+// New definitions are added to file, that is given relative to
+// directory of Netspoc configuration.
+// If Netspoc configuration is a single file, they are added to this file.
+func (s *state) addNodes(netspocPath, file string) {
+	if fi, err := os.Stat(netspocPath); err == nil && !fi.IsDir() {
+		file = ""
+	}
+	rank := func(n ast.Toplevel) int {
+		typ, _, _ := strings.Cut(n.GetName(), ":")
+		return slices.Index(typeOrder, typ)
+	}
+	slices.SortStableFunc(s.newNodes, func(a, b ast.Toplevel) int {
+		return rank(a) - rank(b)
+	})
+	for _, n := range s.newNodes {
+		n.Order()
+		s.CreateToplevel(file, n)
+	}
+	s.Modify(func(t ast.Toplevel) bool {
+		if s.changed[t] {
+			if x, ok := t.(*ast.Network); ok {
+				x.Order()
+			}
+			return true
+		}
+		return false
+	})
+}
@@ -0,0 +1,1090 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Configuration of Cisco ASA as read from "show running-config".
+type asaConfig struct {
+	hostname     string
+	names        map[string]netip.Addr
+	interfaces   []*asaIntf
+	netObjects   map[string]*netObject
+	svcObjects   map[string][]*prt
+	netGroups    map[string]*netGroup
+	svcGroups    map[string]*svcGroup
+	acls         map[string]*acl
+	accessGroups []*accessGroup
+	nats         []*asaNAT
+	routes       []*asaRoute
+	warnings     []string
+}
+
+type asaIntf struct {
+	nameif string
+	ip     netip.Prefix
+	ip6    netip.Prefix
+}
+
+const (
+	anyAddr = iota
+	any4Addr
+	any6Addr
+	prefixAddr
+	rangeAddr
+	objectAddr
+	groupAddr
+	intfAddr
+)
+
+type address struct {
+	kind   int
+	prefix netip.Prefix
+	// Last address of range, first address is in prefix.
+	last netip.Addr
+	// Name of object, object-group or interface.
+	name string
+}
+
+type netObject struct {
+	name        string
+	addr        *address
+	description string
+}
+
+type netGroup struct {
+	name        string
+	elements    []*address
+	description string
+}
+
+// Protocol with optional ports or ICMP type and code.
+type prt struct {
+	// tcp, udp, icmp, icmpv6, ip or protocol number.
+	proto string
+	// Port ranges of tcp, udp, e.g. "80" or "1024-65535".
+	src, dst string
+	// ICMP type with optional code, e.g. "8" or "3/13".
+	icmp string
+}
+
+// Netspoc syntax of protocol.
+func (p *prt) String() string {
+	switch p.proto {
+	case "ip":
+		return p.proto
+	case "tcp", "udp":
+		switch {
+		case p.src != "":
+			dst := p.dst
+			if dst == "" {
+				dst = "1-65535"
+			}
+			return p.proto + " " + p.src + ":" + dst
+		case p.dst != "":
+			return p.proto + " " + p.dst
+		}
+		return p.proto
+	case "icmp", "icmpv6":
+		if p.icmp != "" {
+			return p.proto + " " + p.icmp
+		}
+		return p.proto
+	default:
+		return "proto " + p.proto
+	}
+}
+
+const (
+	serviceGroup  = "service"
+	protocolGroup = "protocol"
+	icmpGroup     = "icmp-type"
+)
+
+type svcGroup struct {
+	name string
+	// One of serviceGroup, protocolGroup, icmpGroup.
+	typ string
+	// Protocols of port-object: tcp, udp or both.
+	portProto []string
+	// Port ranges of port-object or ICMP types of icmp-object.
+	ports []string
+	// Protocols of service-object and protocol-object.
+	prts        []*prt
+	groups      []string
+	description string
+}
+
+type acl struct {
+	name    string
+	entries []*ace
+}
+
+type ace struct {
+	deny     bool
+	prts     []*protoRef
+	src      *address
+	dst      *address
+	remark   string
+	inactive bool
+	line     int
+}
+
+// Reference to protocol in access-list.
+// Either list of protocols or name of object-group.
+type protoRef struct {
+	prt   *prt
+	group string
+}
+
+type accessGroup struct {
+	acl    string
+	nameif string
+	in     bool
+}
+
+type asaNAT struct {
+	real, mapped string
+	dynamic      bool
+	// Real address, if given in object.
+	realAddr *address
+	// Mapped address, if not given as object or "interface".
+	mappedAddr *address
+	line       int
+}
+
+type asaRoute struct {
+	nameif string
+	dst    netip.Prefix
+	hop    netip.Addr
+}
+
+var portNames = map[string]int{
+	"aol":               5190,
+	"bgp":               179,
+	"biff":              512,
+	"bootpc":            68,
+	"bootps":            67,
+	"chargen":           19,
+	"cifs":              3020,
+	"citrix-ica":        1494,
+	"cmd":               514,
+	"ctiqbe":            2748,
+	"daytime":           13,
+	"discard":           9,
+	"dnsix":             195,
+	"domain":            53,
+	"echo":              7,
+	"exec":              512,
+	"finger":            79,
+	"ftp":               21,
+	"ftp-data":          20,
+	"gopher":            70,
+	"h323":              1720,
+	"hostname":          101,
+	"http":              80,
+	"https":             443,
+	"ident":             113,
+	"imap4":             143,
+	"irc":               194,
+	"isakmp":            500,
+	"kerberos":          88,
+	"klogin":            543,
+	"kshell":            544,
+	"ldap":              389,
+	"ldaps":             636,
+	"login":             513,
+	"lotusnotes":        1352,
+	"lpd":               515,
+	"mobile-ip":         434,
+	"nameserver":        42,
+	"netbios-dgm":       138,
+	"netbios-ns":        137,
+	"netbios-ssn":       139,
+	"nfs":               2049,
+	"nntp":              119,
+	"ntp":               123,
+	"pcanywhere-data":   5631,
+	"pcanywhere-status": 5632,
+	"pim-auto-rp":       496,
+	"pop2":              109,
+	"pop3":              110,
+	"pptp":              1723,
+	"radius":            1645,
+	"radius-acct":       1646,
+	"rip":               520,
+	"rsh":               514,
+	"rtsp":              554,
+	"secureid-udp":      5510,
+	"sip":               5060,
+	"smtp":              25,
+	"snmp":              161,
+	"snmptrap":          162,
+	"sqlnet":            1521,
+	"ssh":               22,
+	"sunrpc":            111,
+	"syslog":            514,
+	"tacacs":            49,
+	"talk":              517,
+	"telnet":            23,
+	"tftp":              69,
+	"time":              37,
+	"uucp":              540,
+	"vxlan":             4789,
+	"who":               513,
+	"whois":             43,
+	"www":               80,
+	"xdmcp":             177,
+}
+
+var icmpNames = map[string]int{
+	"echo-reply":           0,
+	"unreachable":          3,
+	"source-quench":        4,
+	"redirect":             5,
+	"alternate-address":    6,
+	"echo":                 8,
+	"router-advertisement": 9,
+	"router-solicitation":  10,
+	"time-exceeded":        11,
+	"parameter-problem":    12,
+	"timestamp-request":    13,
+	"timestamp-reply":      14,
+	"information-request":  15,
+	"information-reply":    16,
+	"mask-request":         17,
+	"mask-reply":           18,
+	"traceroute":           30,
+	"conversion-error":     31,
+	"mobile-redirect":      32,
+}
+
+var icmp6Names = map[string]int{
+	"unreachable":            1,
+	"packet-too-big":         2,
+	"time-exceeded":          3,
+	"parameter-problem":      4,
+	"echo":                   128,
+	"echo-reply":             129,
+	"membership-query":       130,
+	"membership-report":      131,
+	"membership-reduction":   132,
+	"router-solicitation":    133,
+	"router-advertisement":   134,
+	"neighbor-solicitation":  135,
+	"neighbor-advertisement": 136,
+	"neighbor-redirect":      137,
+	"router-renumbering":     138,
+}
+
+var protoNames = map[string]string{
+	"ah":     "51",
+	"eigrp":  "88",
+	"esp":    "50",
+	"gre":    "47",
+	"icmp6":  "icmpv6",
+	"igmp":   "2",
+	"igrp":   "9",
+	"ipinip": "4",
+	"ipsec":  "50",
+	"nos":    "94",
+	"ospf":   "89",
+	"pcp":    "108",
+	"pim":    "103",
+	"pptp":   "47",
+	"snp":    "109",
+}
+
+type parseError struct {
+	line int
+	msg  string
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("%s in line %d", e.msg, e.line)
+}
+
+type asaParser struct {
+	cfg  *asaConfig
+	line int
+	// Tokens of current line.
+	words []string
+	pos   int
+}
+
+func (p *asaParser) errorf(format string, args ...any) {
+	panic(&parseError{line: p.line, msg: fmt.Sprintf(format, args...)})
+}
+
+func (p *asaParser) warnf(format string, args ...any) {
+	p.cfg.warnings = append(p.cfg.warnings,
+		fmt.Sprintf(format+" in line %d", append(args, p.line)...))
+}
+
+func (p *asaParser) atEnd() bool { return p.pos >= len(p.words) }
+
+func (p *asaParser) peek() string {
+	if p.atEnd() {
+		return ""
+	}
+	return p.words[p.pos]
+}
+
+func (p *asaParser) next() string {
+	if p.atEnd() {
+		p.errorf("Unexpected end of line")
+	}
+	w := p.words[p.pos]
+	p.pos++
+	return w
+}
+
+func (p *asaParser) rest() string {
+	s := strings.Join(p.words[p.pos:], " ")
+	p.pos = len(p.words)
+	return s
+}
+
+func parseConfig(data string) (cfg *asaConfig, err error) {
+	cfg = &asaConfig{
+		names:      make(map[string]netip.Addr),
+		netObjects: make(map[string]*netObject),
+		svcObjects: make(map[string][]*prt),
+		netGroups:  make(map[string]*netGroup),
+		svcGroups:  make(map[string]*svcGroup),
+		acls:       make(map[string]*acl),
+	}
+	p := &asaParser{cfg: cfg}
+	defer func() {
+		if e := recover(); e != nil {
+			pe, ok := e.(*parseError)
+			if !ok {
+				panic(e)
+			}
+			err = pe
+		}
+	}()
+	// Handler for sub commands of current command.
+	var sub func()
+	var remark string
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		p.line++
+		line := scanner.Text()
+		p.words = strings.Fields(line)
+		p.pos = 0
+		if len(p.words) == 0 || p.words[0] == "!" || p.words[0] == ":" {
+			continue
+		}
+		if line[0] == ' ' {
+			if sub != nil {
+				sub()
+			}
+			continue
+		}
+		sub = nil
+		cmd := p.next()
+		switch cmd {
+		case "hostname":
+			cfg.hostname = p.next()
+		case "name":
+			ip := p.getIP()
+			cfg.names[p.next()] = ip
+		case "interface":
+			intf := new(asaIntf)
+			cfg.interfaces = append(cfg.interfaces, intf)
+			sub = func() { p.interfaceCmd(intf) }
+		case "object":
+			sub = p.object()
+		case "object-group":
+			sub = p.objectGroup()
+		case "access-list":
+			remark = p.accessList(remark)
+		case "access-group":
+			p.accessGroup()
+		case "nat":
+			p.twiceNAT()
+		case "route":
+			p.route(false)
+		case "ipv6":
+			if p.peek() == "route" {
+				p.next()
+				p.route(true)
+			}
+		}
+	}
+	return
+}
+
+func (p *asaParser) interfaceCmd(intf *asaIntf) {
+	switch p.next() {
+	case "nameif":
+		intf.nameif = p.next()
+	case "ip":
+		if p.next() == "address" {
+			ip := p.getIP()
+			mask := p.getIP()
+			intf.ip = p.prefix(ip, mask)
+		}
+	case "ipv6":
+		if p.next() == "address" {
+			s := p.next()
+			if pr, err := netip.ParsePrefix(s); err == nil && p.atEnd() {
+				intf.ip6 = pr
+			}
+		}
+	}
+}
+
+func (p *asaParser) getIP() netip.Addr {
+	s := p.next()
+	if ip, found := p.cfg.names[s]; found {
+		return ip
+	}
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		p.errorf("Expected IP address, got '%s'", s)
+	}
+	return ip
+}
+
+func (p *asaParser) prefix(ip, mask netip.Addr) netip.Prefix {
+	bits := 0
+	b := mask.AsSlice()
+	for bits < len(b)*8 && b[bits/8]&(1<<(7-bits%8)) != 0 {
+		bits++
+	}
+	if netip.PrefixFrom(mask, bits).Masked().Addr() != mask {
+		p.errorf("Invalid mask %s", mask)
+	}
+	return netip.PrefixFrom(ip, bits)
+}
+
+// Read "object network|service NAME" and return handler of sub commands.
+func (p *asaParser) object() func() {
+	cfg := p.cfg
+	typ := p.next()
+	name := p.next()
+	switch typ {
+	case "network":
+		// Object is shown a second time in running-config,
+		// if it has NAT.
+		obj := cfg.netObjects[name]
+		if obj == nil {
+			obj = &netObject{name: name}
+			cfg.netObjects[name] = obj
+		}
+		return func() {
+			switch cmd := p.next(); cmd {
+			case "host":
+				ip := p.getIP()
+				obj.addr = &address{kind: prefixAddr,
+					prefix: netip.PrefixFrom(ip, ip.BitLen())}
+			case "subnet":
+				obj.addr = p.subnet()
+			case "range":
+				ip1 := p.getIP()
+				ip2 := p.getIP()
+				obj.addr = &address{kind: rangeAddr,
+					prefix: netip.PrefixFrom(ip1, ip1.BitLen()), last: ip2}
+			case "description":
+				obj.description = p.rest()
+			case "nat":
+				p.objectNAT(obj)
+			default:
+				p.warnf("Ignoring '%s' of object %s", cmd, name)
+			}
+		}
+	case "service":
+		return func() {
+			if cmd := p.next(); cmd == "service" {
+				cfg.svcObjects[name] = append(cfg.svcObjects[name], p.serviceSpec())
+			}
+		}
+	}
+	p.warnf("Ignoring object %s of type %s", name, typ)
+	return nil
+}
+
+// Read IPv4 address and mask or IPv6 prefix.
+func (p *asaParser) subnet() *address {
+	s := p.peek()
+	if pr, err := netip.ParsePrefix(s); err == nil {
+		p.next()
+		return &address{kind: prefixAddr, prefix: pr}
+	}
+	ip := p.getIP()
+	mask := p.getIP()
+	return &address{kind: prefixAddr, prefix: p.prefix(ip, mask)}
+}
+
+// Read "PROTO [source OP PORT] [destination OP PORT]"
+// or "icmp [TYPE [CODE]]".
+func (p *asaParser) serviceSpec() *prt {
+	return p.serviceWith(p.protoName(p.next()))
+}
+
+// Read rest of service with given protocol.
+func (p *asaParser) serviceWith(proto string) *prt {
+	result := &prt{proto: proto}
+	switch result.proto {
+	case "tcp", "udp":
+		for !p.atEnd() {
+			switch w := p.next(); w {
+			case "source":
+				result.src = p.portRange(p.next())
+			case "destination":
+				result.dst = p.portRange(p.next())
+			default:
+				p.errorf("Unexpected '%s' in service", w)
+			}
+		}
+	case "icmp", "icmpv6":
+		if !p.atEnd() {
+			result.icmp = p.icmpType(result.proto, p.next())
+			if !p.atEnd() {
+				result.icmp += "/" + p.next()
+			}
+		}
+	}
+	return result
+}
+
+func (p *asaParser) protoName(s string) string {
+	switch s {
+	case "ip", "tcp", "udp", "icmp":
+		return s
+	case "tcp-udp":
+		p.errorf("Unexpected '%s'", s)
+	}
+	if n, found := protoNames[s]; found {
+		return n
+	}
+	if _, err := strconv.Atoi(s); err != nil {
+		p.errorf("Unknown protocol '%s'", s)
+	}
+	return s
+}
+
+func (p *asaParser) port(s string) int {
+	if n, found := portNames[s]; found {
+		return n
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 65535 {
+		p.errorf("Unknown port '%s'", s)
+	}
+	return n
+}
+
+// Read port range after operator op.
+// Operator "neq" is not supported.
+func (p *asaParser) portRange(op string) string {
+	switch op {
+	case "eq":
+		return strconv.Itoa(p.port(p.next()))
+	case "lt":
+		return fmt.Sprintf("1-%d", p.port(p.next())-1)
+	case "gt":
+		return fmt.Sprintf("%d-65535", p.port(p.next())+1)
+	case "range":
+		n1 := p.port(p.next())
+		n2 := p.port(p.next())
+		if n1 == n2 {
+			return strconv.Itoa(n1)
+		}
+		return fmt.Sprintf("%d-%d", n1, n2)
+	}
+	p.errorf("Unsupported port operator '%s'", op)
+	return ""
+}
+
+func (p *asaParser) icmpType(proto, s string) string {
+	names := icmpNames
+	if proto == "icmpv6" {
+		names = icmp6Names
+	}
+	if n, found := names[s]; found {
+		return strconv.Itoa(n)
+	}
+	if _, err := strconv.Atoi(s); err != nil {
+		p.errorf("Unknown ICMP type '%s'", s)
+	}
+	return s
+}
+
+// Read "object-group TYPE NAME" and return handler of sub commands.
+func (p *asaParser) objectGroup() func() {
+	cfg := p.cfg
+	typ := p.next()
+	name := p.next()
+	switch typ {
+	case "network":
+		g := &netGroup{name: name}
+		cfg.netGroups[name] = g
+		return func() {
+			switch cmd := p.next(); cmd {
+			case "network-object":
+				var a *address
+				switch p.peek() {
+				case "host":
+					p.next()
+					ip := p.getIP()
+					a = &address{kind: prefixAddr,
+						prefix: netip.PrefixFrom(ip, ip.BitLen())}
+				case "object":
+					p.next()
+					a = &address{kind: objectAddr, name: p.next()}
+				default:
+					a = p.subnet()
+				}
+				g.elements = append(g.elements, a)
+			case "group-object":
+				g.elements = append(g.elements,
+					&address{kind: groupAddr, name: p.next()})
+			case "description":
+				g.description = p.rest()
+			default:
+				p.warnf("Ignoring '%s' of object-group %s", cmd, name)
+			}
+		}
+	case serviceGroup, protocolGroup, icmpGroup:
+		g := &svcGroup{name: name, typ: typ}
+		cfg.svcGroups[name] = g
+		if typ == serviceGroup && !p.atEnd() {
+			switch w := p.next(); w {
+			case "tcp", "udp":
+				g.portProto = []string{w}
+			case "tcp-udp":
+				g.portProto = []string{"tcp", "udp"}
+			default:
+				p.errorf("Unexpected '%s' in object-group %s", w, name)
+			}
+		}
+		return func() {
+			switch cmd := p.next(); cmd {
+			case "port-object":
+				if g.portProto == nil {
+					p.errorf("Unexpected port-object in object-group %s", name)
+				}
+				g.ports = append(g.ports, p.portRange(p.next()))
+			case "service-object":
+				if p.peek() == "object" {
+					p.next()
+					obj := p.next()
+					l, found := cfg.svcObjects[obj]
+					if !found {
+						p.errorf("Unknown object %s", obj)
+					}
+					g.prts = append(g.prts, l...)
+				} else if p.peek() == "tcp-udp" {
+					p.next()
+					pr := p.serviceWith("tcp")
+					g.prts = append(g.prts, pr, &prt{
+						proto: "udp", src: pr.src, dst: pr.dst})
+				} else {
+					g.prts = append(g.prts, p.serviceSpec())
+				}
+			case "protocol-object":
+				g.prts = append(g.prts, &prt{proto: p.protoName(p.next())})
+			case "icmp-object":
+				g.ports = append(g.ports, p.icmpType("icmp", p.next()))
+			case "group-object":
+				g.groups = append(g.groups, p.next())
+			case "description":
+				g.description = p.rest()
+			default:
+				p.warnf("Ignoring '%s' of object-group %s", cmd, name)
+			}
+		}
+	}
+	p.warnf("Ignoring object-group %s of type %s", name, typ)
+	return nil
+}
+
+// Read line of access-list.
+// Return remark, that is used as description of next entry.
+func (p *asaParser) accessList(remark string) string {
+	cfg := p.cfg
+	name := p.next()
+	a := cfg.acls[name]
+	if a == nil {
+		a = &acl{name: name}
+		cfg.acls[name] = a
+	}
+	typ := p.next()
+	switch typ {
+	case "remark":
+		if remark != "" {
+			remark += " "
+		}
+		return remark + p.rest()
+	case "extended":
+	default:
+		p.warnf("Ignoring access-list %s of type %s", name, typ)
+		return ""
+	}
+	e := &ace{remark: remark, line: p.line}
+	switch w := p.next(); w {
+	case "permit":
+	case "deny":
+		e.deny = true
+	default:
+		p.errorf("Expected 'permit' or 'deny'")
+	}
+	var proto string
+	switch w := p.next(); w {
+	case "object-group":
+		e.prts = []*protoRef{{group: p.next()}}
+	case "object":
+		obj := p.next()
+		l, found := cfg.svcObjects[obj]
+		if !found {
+			p.errorf("Unknown object %s", obj)
+		}
+		for _, pr := range l {
+			e.prts = append(e.prts, &protoRef{prt: pr})
+		}
+	default:
+		proto = p.protoName(w)
+		e.prts = []*protoRef{{prt: &prt{proto: proto}}}
+	}
+	// Protocol group consisting only of tcp and udp may be used together
+	// with ports.
+	withPorts := proto == "tcp" || proto == "udp"
+	if g := e.prts[0].group; g != "" {
+		if sg := cfg.svcGroups[g]; sg != nil && sg.typ == protocolGroup {
+			withPorts = true
+		}
+	}
+	e.src = p.aclAddress()
+	srcPorts := p.aclPorts(withPorts)
+	e.dst = p.aclAddress()
+	dstPorts := p.aclPorts(withPorts)
+	if proto == "icmp" || proto == "icmpv6" {
+		if w := p.peek(); w != "" && w != "log" && w != "inactive" &&
+			w != "time-range" {
+
+			if w == "object-group" {
+				p.next()
+				dstPorts = []string{"group:" + p.next()}
+			} else {
+				p.next()
+				t := p.icmpType(proto, w)
+				if n := p.peek(); n != "" && n[0] >= '0' && n[0] <= '9' {
+					t += "/" + p.next()
+				}
+				dstPorts = []string{t}
+			}
+		}
+	}
+	for !p.atEnd() {
+		switch w := p.next(); w {
+		case "log":
+			// Ignore optional level and interval of log.
+			for !p.atEnd() && p.peek() != "inactive" && p.peek() != "time-range" {
+				p.next()
+			}
+		case "inactive":
+			e.inactive = true
+		case "time-range":
+			p.warnf("Ignoring time-range %s", p.next())
+		default:
+			p.errorf("Unexpected '%s'", w)
+		}
+	}
+	if srcPorts != nil || dstPorts != nil {
+		e.prts = p.applyPorts(e.prts, srcPorts, dstPorts)
+	}
+	a.entries = append(a.entries, e)
+	return ""
+}
+
+// Read address of access-list.
+func (p *asaParser) aclAddress() *address {
+	switch w := p.next(); w {
+	case "any":
+		return &address{kind: anyAddr}
+	case "any4":
+		return &address{kind: any4Addr}
+	case "any6":
+		return &address{kind: any6Addr}
+	case "host":
+		ip := p.getIP()
+		return &address{kind: prefixAddr, prefix: netip.PrefixFrom(ip, ip.BitLen())}
+	case "object":
+		return &address{kind: objectAddr, name: p.next()}
+	case "object-group":
+		return &address{kind: groupAddr, name: p.next()}
+	case "interface":
+		return &address{kind: intfAddr, name: p.next()}
+	default:
+		p.pos--
+		return p.subnet()
+	}
+}
+
+// Read optional ports of access-list.
+// Port group is returned as "group:NAME".
+func (p *asaParser) aclPorts(withPorts bool) []string {
+	if !withPorts {
+		return nil
+	}
+	switch w := p.peek(); w {
+	case "eq", "lt", "gt", "range":
+		p.next()
+		return []string{p.portRange(w)}
+	case "neq":
+		p.next()
+		n := p.port(p.next())
+		var l []string
+		if n > 1 {
+			l = append(l, fmt.Sprintf("1-%d", n-1))
+		}
+		if n < 65535 {
+			l = append(l, fmt.Sprintf("%d-65535", n+1))
+		}
+		return l
+	case "object-group":
+		// Port group or group of addresses.
+		if p.pos+1 == len(p.words) {
+			break
+		}
+		if g := p.cfg.svcGroups[p.words[p.pos+1]]; g != nil &&
+			g.typ == serviceGroup {
+
+			p.next()
+			return []string{"group:" + p.next()}
+		}
+	}
+	return nil
+}
+
+// Combine protocols with source and destination ports.
+func (p *asaParser) applyPorts(l []*protoRef, srcPorts, dstPorts []string,
+) []*protoRef {
+	var result []*protoRef
+	// Expand protocol group, if ports are given.
+	var prts []*prt
+	for _, r := range l {
+		if r.group != "" {
+			prts = append(prts, p.cfg.expandSvcGroup(r.group, p)...)
+		} else {
+			prts = append(prts, r.prt)
+		}
+	}
+	if srcPorts == nil {
+		srcPorts = []string{""}
+	}
+	if dstPorts == nil {
+		dstPorts = []string{""}
+	}
+	for _, pr := range prts {
+		for _, s := range srcPorts {
+			for _, d := range dstPorts {
+				if strings.HasPrefix(d, "group:") {
+					name := d[len("group:"):]
+					if s == "" && p.cfg.isPortGroupFor(name, pr.proto) {
+						result = append(result, &protoRef{group: name})
+						continue
+					}
+					for _, d2 := range p.cfg.expandPorts(name, pr.proto, p) {
+						cp := *pr
+						cp.src, cp.dst, cp.icmp = s, "", ""
+						setPort(&cp, d2)
+						result = append(result, &protoRef{prt: &cp})
+					}
+					continue
+				}
+				cp := *pr
+				cp.src = s
+				setPort(&cp, d)
+				result = append(result, &protoRef{prt: &cp})
+			}
+		}
+	}
+	return result
+}
+
+func setPort(pr *prt, port string) {
+	if pr.proto == "icmp" || pr.proto == "icmpv6" {
+		pr.icmp = port
+	} else {
+		pr.dst = port
+	}
+}
+
+// Group of ports can be used unchanged as protocolgroup,
+// if group is used together with its own protocol.
+func (cfg *asaConfig) isPortGroupFor(name, proto string) bool {
+	g := cfg.svcGroups[name]
+	if g == nil || len(g.groups) != 0 {
+		return false
+	}
+	switch g.typ {
+	case serviceGroup:
+		return len(g.portProto) == 1 && g.portProto[0] == proto
+	case icmpGroup:
+		return proto == "icmp"
+	}
+	return false
+}
+
+// Expand ports of port group or types of icmp-type group.
+func (cfg *asaConfig) expandPorts(name, proto string, p *asaParser) []string {
+	g := cfg.svcGroups[name]
+	if g == nil {
+		p.errorf("Unknown object-group %s", name)
+	}
+	var result []string
+	if g.typ == icmpGroup || slices.Contains(g.portProto, proto) {
+		result = append(result, g.ports...)
+	}
+	for _, sub := range g.groups {
+		result = append(result, cfg.expandPorts(sub, proto, p)...)
+	}
+	return result
+}
+
+// Expand protocols of service-object and protocol-object.
+func (cfg *asaConfig) expandSvcGroup(name string, p *asaParser) []*prt {
+	g := cfg.svcGroups[name]
+	if g == nil {
+		p.errorf("Unknown object-group %s", name)
+	}
+	result := g.prts
+	for _, proto := range g.portProto {
+		for _, port := range g.ports {
+			result = append(result, &prt{proto: proto, dst: port})
+		}
+	}
+	if g.typ == icmpGroup {
+		for _, t := range g.ports {
+			result = append(result, &prt{proto: "icmp", icmp: t})
+		}
+	}
+	for _, sub := range g.groups {
+		result = append(result, cfg.expandSvcGroup(sub, p)...)
+	}
+	return result
+}
+
+// Read "access-group ACL in|out interface NAMEIF" or
+// "access-group ACL global".
+func (p *asaParser) accessGroup() {
+	name := p.next()
+	g := &accessGroup{acl: name}
+	switch w := p.next(); w {
+	case "in", "out":
+		g.in = w == "in"
+		if p.next() != "interface" {
+			p.errorf("Expected 'interface'")
+		}
+		g.nameif = p.next()
+	case "global":
+	default:
+		p.errorf("Unexpected '%s'", w)
+	}
+	p.cfg.accessGroups = append(p.cfg.accessGroups, g)
+}
+
+// Read pair of interfaces "(REAL,MAPPED)".
+func (p *asaParser) natInterfaces() (string, string) {
+	w := strings.Trim(p.next(), "()")
+	real, mapped, found := strings.Cut(w, ",")
+	if !found {
+		p.errorf("Expected '(real,mapped)'")
+	}
+	return real, mapped
+}
+
+// Read object NAT
+// "nat (REAL,MAPPED) static|dynamic ADDRESS|OBJECT|interface".
+func (p *asaParser) objectNAT(obj *netObject) {
+	n := &asaNAT{line: p.line}
+	n.real, n.mapped = p.natInterfaces()
+	p.natType(n)
+	n.realAddr = &address{kind: objectAddr, name: obj.name}
+	p.natMapped(n)
+	p.natRest(n)
+}
+
+func (p *asaParser) natType(n *asaNAT) {
+	switch w := p.next(); w {
+	case "static":
+	case "dynamic":
+		n.dynamic = true
+	default:
+		p.errorf("Unexpected '%s'", w)
+	}
+}
+
+func (p *asaParser) natMapped(n *asaNAT) {
+	w := p.next()
+	switch {
+	case w == "interface":
+		n.mappedAddr = &address{kind: intfAddr, name: n.mapped}
+	case p.cfg.netObjects[w] != nil:
+		n.mappedAddr = &address{kind: objectAddr, name: w}
+	case p.cfg.netGroups[w] != nil:
+		p.errorf("Unsupported object-group %s in NAT", w)
+	default:
+		p.pos--
+		ip := p.getIP()
+		n.mappedAddr = &address{kind: prefixAddr,
+			prefix: netip.PrefixFrom(ip, ip.BitLen())}
+	}
+}
+
+func (p *asaParser) natRest(n *asaNAT) {
+	if !p.atEnd() {
+		p.warnf("Ignoring NAT with '%s'", p.rest())
+		return
+	}
+	p.cfg.nats = append(p.cfg.nats, n)
+}
+
+// Read twice NAT
+// "nat (REAL,MAPPED) source static|dynamic OBJECT OBJECT|interface".
+func (p *asaParser) twiceNAT() {
+	n := &asaNAT{line: p.line}
+	n.real, n.mapped = p.natInterfaces()
+	if w := p.next(); w != "source" {
+		p.warnf("Ignoring NAT with '%s'", w)
+		return
+	}
+	p.natType(n)
+	w := p.next()
+	if w == "any" {
+		n.realAddr = &address{kind: anyAddr}
+	} else {
+		n.realAddr = &address{kind: objectAddr, name: w}
+	}
+	p.natMapped(n)
+	p.natRest(n)
+}
+
+// Read "route NAMEIF ADDRESS MASK HOP [DISTANCE]" or
+// "ipv6 route NAMEIF PREFIX HOP [DISTANCE]".
+func (p *asaParser) route(v6 bool) {
+	r := &asaRoute{nameif: p.next()}
+	if v6 {
+		s := p.next()
+		pr, err := netip.ParsePrefix(s)
+		if err != nil {
+			p.errorf("Expected IPv6 prefix, got '%s'", s)
+		}
+		r.dst = pr
+	} else {
+		ip := p.getIP()
+		mask := p.getIP()
+		r.dst = p.prefix(ip, mask)
+	}
+	r.hop = p.getIP()
+	p.cfg.routes = append(p.cfg.routes, r)
+}
@@ -0,0 +1,454 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Rules of table "filter" as read from output of iptables-save or
+// ip6tables-save.
+type iptConfig struct {
+	ipv6   bool
+	chains map[string][]*iptRule
+	// Policy of builtin chains.
+	policy   map[string]string
+	warnings []string
+}
+
+type iptRule struct {
+	line  int
+	chain string
+	// Position of rule in its chain, starting at 1.
+	num    int
+	m      *match
+	target string
+	// Options, that can't be converted.
+	unsupported []string
+	// Rule matches only packets of already established connections.
+	established bool
+}
+
+// Range of IP addresses. Zero value matches any address.
+type ipRange struct {
+	first, last netip.Addr
+}
+
+func (r ipRange) isAny() bool { return !r.first.IsValid() }
+
+type portRange struct {
+	lo, hi int
+}
+
+func (r portRange) String() string {
+	if r.lo == r.hi {
+		return strconv.Itoa(r.lo)
+	}
+	return fmt.Sprintf("%d-%d", r.lo, r.hi)
+}
+
+// Conditions of a rule. Empty value matches anything.
+type match struct {
+	src, dst ipRange
+	// tcp, udp, icmp, icmpv6 or protocol number.
+	proto        string
+	sport, dport []portRange
+	// ICMP type with optional code, e.g. "8" or "3/13".
+	icmp    string
+	comment string
+}
+
+func (m *match) isAny() bool {
+	return m.src.isAny() && m.dst.isAny() && m.proto == "" &&
+		m.sport == nil && m.dport == nil && m.icmp == ""
+}
+
+// Combine conditions of rule with jump to user defined chain and
+// conditions of rule inside this chain.
+// Returns nil, if combined conditions can't match any packet.
+func (m *match) combine(o *match) *match {
+	result := *o
+	if result.comment == "" {
+		result.comment = m.comment
+	}
+	var ok bool
+	if result.src, ok = intersectRange(m.src, o.src); !ok {
+		return nil
+	}
+	if result.dst, ok = intersectRange(m.dst, o.dst); !ok {
+		return nil
+	}
+	if m.proto != "" {
+		if o.proto != "" && o.proto != m.proto {
+			return nil
+		}
+		result.proto = m.proto
+	}
+	if result.sport, ok = intersectPorts(m.sport, o.sport); !ok {
+		return nil
+	}
+	if result.dport, ok = intersectPorts(m.dport, o.dport); !ok {
+		return nil
+	}
+	if m.icmp != "" {
+		if o.icmp != "" && o.icmp != m.icmp {
+			return nil
+		}
+		result.icmp = m.icmp
+	}
+	return &result
+}
+
+func intersectRange(a, b ipRange) (ipRange, bool) {
+	if a.isAny() {
+		return b, true
+	}
+	if b.isAny() {
+		return a, true
+	}
+	if a.first.Is4() != b.first.Is4() {
+		return ipRange{}, false
+	}
+	first, last := a.first, a.last
+	if b.first.Compare(first) > 0 {
+		first = b.first
+	}
+	if b.last.Compare(last) < 0 {
+		last = b.last
+	}
+	return ipRange{first, last}, first.Compare(last) <= 0
+}
+
+func intersectPorts(a, b []portRange) ([]portRange, bool) {
+	if a == nil {
+		return b, true
+	}
+	if b == nil {
+		return a, true
+	}
+	var result []portRange
+	for _, r1 := range a {
+		for _, r2 := range b {
+			r := portRange{max(r1.lo, r2.lo), min(r1.hi, r2.hi)}
+			if r.lo <= r.hi {
+				result = append(result, r)
+			}
+		}
+	}
+	return result, result != nil
+}
+
+var iptProtoNames = map[string]string{
+	"ah":        "51",
+	"esp":       "50",
+	"gre":       "47",
+	"icmp":      "icmp",
+	"icmpv6":    "icmpv6",
+	"ipv6-icmp": "icmpv6",
+	"ospf":      "89",
+	"sctp":      "132",
+	"tcp":       "tcp",
+	"udp":       "udp",
+	"vrrp":      "112",
+}
+
+var iptICMPNames = map[string]string{
+	"echo-reply":              "0",
+	"destination-unreachable": "3",
+	"redirect":                "5",
+	"echo-request":            "8",
+	"time-exceeded":           "11",
+	"parameter-problem":       "12",
+	"packet-too-big":          "2",
+}
+
+var iptICMPv6Names = map[string]string{
+	"destination-unreachable": "1",
+	"packet-too-big":          "2",
+	"time-exceeded":           "3",
+	"parameter-problem":       "4",
+	"echo-request":            "128",
+	"echo-reply":              "129",
+	"router-solicitation":     "133",
+	"router-advertisement":    "134",
+	"neighbour-solicitation":  "135",
+	"neighbour-advertisement": "136",
+}
+
+// Split line into words. Double quoted strings are a single word.
+func splitWords(line string) ([]string, error) {
+	var result []string
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return result, nil
+		}
+		if line[0] == '"' {
+			var b strings.Builder
+			i := 1
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				b.WriteByte(line[i])
+			}
+			if i == len(line) {
+				return nil, fmt.Errorf("Missing closing '\"'")
+			}
+			result = append(result, b.String())
+			line = line[i+1:]
+			continue
+		}
+		w, rest, _ := strings.Cut(line, " ")
+		result = append(result, w)
+		line = rest
+	}
+}
+
+func parseIptables(data string) (*iptConfig, error) {
+	cfg := &iptConfig{
+		chains: make(map[string][]*iptRule),
+		policy: make(map[string]string),
+	}
+	table := ""
+	lineNum := 0
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		// Ignore packet and byte counters of iptables-save -c.
+		if strings.HasPrefix(line, "[") {
+			if _, rest, found := strings.Cut(line, "] "); found {
+				line = rest
+			}
+		}
+		switch {
+		case line == "":
+		case line[0] == '#':
+			if strings.Contains(line, "ip6tables-save") {
+				cfg.ipv6 = true
+			}
+		case line[0] == '*':
+			table = line[1:]
+			if table != "filter" {
+				cfg.warnings = append(cfg.warnings,
+					fmt.Sprintf("Ignoring table %s in line %d", table, lineNum))
+			}
+		case line == "COMMIT":
+			table = ""
+		case table != "filter":
+		case line[0] == ':':
+			l := strings.Fields(line[1:])
+			if len(l) < 2 {
+				return nil, fmt.Errorf("Invalid chain in line %d", lineNum)
+			}
+			if _, found := cfg.chains[l[0]]; !found {
+				cfg.chains[l[0]] = nil
+			}
+			if l[1] != "-" {
+				cfg.policy[l[0]] = l[1]
+			}
+		default:
+			words, err := splitWords(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s in line %d", err, lineNum)
+			}
+			r, err := cfg.parseRule(words)
+			if err != nil {
+				return nil, fmt.Errorf("%s in line %d", err, lineNum)
+			}
+			r.line = lineNum
+			r.num = len(cfg.chains[r.chain]) + 1
+			cfg.chains[r.chain] = append(cfg.chains[r.chain], r)
+		}
+	}
+	return cfg, nil
+}
+
+func (cfg *iptConfig) parseRule(words []string) (*iptRule, error) {
+	if len(words) < 2 || words[0] != "-A" && words[0] != "--append" {
+		return nil, fmt.Errorf("Expected '-A CHAIN'")
+	}
+	r := &iptRule{chain: words[1], m: new(match)}
+	m := r.m
+	l := words[2:]
+	i := 0
+	next := func() (string, error) {
+		if i >= len(l) {
+			return "", fmt.Errorf("Missing value after '%s'", l[i-1])
+		}
+		i++
+		return l[i-1], nil
+	}
+	// Collect option with its values as unsupported.
+	unsupported := func(opt string) {
+		for i < len(l) && !strings.HasPrefix(l[i], "-") {
+			opt += " " + l[i]
+			i++
+		}
+		r.unsupported = append(r.unsupported, opt)
+	}
+	for i < len(l) {
+		opt := l[i]
+		i++
+		if opt == "!" {
+			if i < len(l) {
+				opt = l[i]
+				i++
+			}
+			next() // Value of negated option.
+			unsupported("! " + opt + " " + l[i-1])
+			continue
+		}
+		var err error
+		var v string
+		switch opt {
+		case "-s", "--source", "-d", "--destination":
+			if v, err = next(); err == nil {
+				var rg ipRange
+				if rg, err = cfg.parseAddr(v); err == nil {
+					if opt[1] == 's' || opt == "--source" {
+						m.src = rg
+					} else {
+						m.dst = rg
+					}
+				}
+			}
+		case "--src-range", "--dst-range":
+			if v, err = next(); err == nil {
+				a1, a2, _ := strings.Cut(v, "-")
+				var rg ipRange
+				if rg.first, err = netip.ParseAddr(a1); err == nil {
+					if rg.last, err = netip.ParseAddr(a2); err == nil {
+						if opt == "--src-range" {
+							m.src = rg
+						} else {
+							m.dst = rg
+						}
+					}
+				}
+			}
+		case "-p", "--protocol":
+			if v, err = next(); err == nil {
+				m.proto, err = cfg.parseProto(v)
+			}
+		case "-m", "--match":
+			_, err = next()
+		case "--sport", "--source-port", "--dport", "--destination-port",
+			"--sports", "--source-ports", "--dports", "--destination-ports":
+			if v, err = next(); err == nil {
+				var ports []portRange
+				if ports, err = parsePorts(v); err == nil {
+					if strings.Contains(opt, "-s") {
+						m.sport = ports
+					} else {
+						m.dport = ports
+					}
+				}
+			}
+		case "--icmp-type", "--icmpv6-type":
+			if v, err = next(); err == nil {
+				names := iptICMPNames
+				if opt == "--icmpv6-type" {
+					names = iptICMPv6Names
+				}
+				if n, found := names[v]; found {
+					v = n
+				}
+				m.icmp = v
+			}
+		case "--state", "--ctstate":
+			if v, err = next(); err == nil {
+				r.established = !slices.Contains(strings.Split(v, ","), "NEW")
+			}
+		case "--comment":
+			m.comment, err = next()
+		case "-j", "--jump", "-g", "--goto":
+			r.target, err = next()
+			// Ignore options of target, e.g. --reject-with.
+			for i < len(l) && l[i] != "-m" && l[i] != "--match" {
+				i++
+			}
+		default:
+			unsupported(opt)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m.proto == "" && (m.sport != nil || m.dport != nil) {
+		return nil, fmt.Errorf("Ports without protocol")
+	}
+	return r, nil
+}
+
+func (cfg *iptConfig) parseAddr(v string) (ipRange, error) {
+	if !strings.Contains(v, "/") {
+		ip, err := netip.ParseAddr(v)
+		if err != nil {
+			return ipRange{}, err
+		}
+		v = netip.PrefixFrom(ip, ip.BitLen()).String()
+	}
+	p, err := netip.ParsePrefix(v)
+	if err != nil {
+		return ipRange{}, err
+	}
+	if p.Addr().Is6() {
+		cfg.ipv6 = true
+	}
+	if p.Bits() == 0 {
+		return ipRange{}, nil
+	}
+	p = p.Masked()
+	return ipRange{p.Addr(), lastAddr(p)}, nil
+}
+
+func (cfg *iptConfig) parseProto(v string) (string, error) {
+	v = strings.ToLower(v)
+	if v == "all" || v == "0" {
+		return "", nil
+	}
+	if p, found := iptProtoNames[v]; found {
+		v = p
+	} else if _, err := strconv.Atoi(v); err != nil {
+		return "", fmt.Errorf("Unknown protocol '%s'", v)
+	}
+	switch v {
+	case "6":
+		v = "tcp"
+	case "17":
+		v = "udp"
+	case "icmpv6", "58":
+		cfg.ipv6 = true
+		v = "icmpv6"
+	}
+	return v, nil
+}
+
+// Parse list of ports "P1,P2:P3" of multiport or single port range.
+func parsePorts(v string) ([]portRange, error) {
+	var result []portRange
+	for _, p := range strings.Split(v, ",") {
+		lo, hi, found := strings.Cut(p, ":")
+		if !found {
+			hi = lo
+		}
+		if lo == "" {
+			lo = "0"
+		}
+		if hi == "" {
+			hi = "65535"
+		}
+		n1, err1 := strconv.Atoi(lo)
+		n2, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || n1 > n2 || n2 > 65535 {
+			return nil, fmt.Errorf("Invalid port '%s'", p)
+		}
+		result = append(result, portRange{max(n1, 1), n2})
+	}
+	return result, nil
+}
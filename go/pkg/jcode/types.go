@@ -37,6 +37,7 @@ type Rule struct {
 	SrcRange     string   `json:"src_range,omitempty"`
 	Log          string   `json:"log,omitempty"`
 	OptSecondary bool     `json:"opt_secondary,omitempty"`
+	Services     []string `json:"services,omitempty"`
 }
 
 // GenPortName is used to create name of protocol with ports printed
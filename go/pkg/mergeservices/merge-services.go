@@ -0,0 +1,323 @@
+package mergeservices
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/ast"
+	"github.com/hknutzen/Netspoc/go/pkg/astset"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/spf13/pflag"
+)
+
+type state struct {
+	*astset.State
+}
+
+// Attributes with list of services as value.
+// Values of merged services are combined.
+var svcListAttr = map[string]bool{
+	"overlaps":       true,
+	"identical_body": true,
+}
+
+// Read groups of services from warning messages of Netspoc:
+//
+//	Warning: These services have identical rule definitions.
+//	 A single service should be created instead, with merged users.
+//	 - service:s1
+//	 - service:s2
+//
+// Only lines starting with "-" that directly follow this message
+// are read as group of services. Other messages are ignored.
+func readGroups(path string) ([][]string, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Can't %s", err)
+	}
+	defer fd.Close()
+	var result [][]string
+	var group []string
+	inGroup := false
+	add := func() {
+		if len(group) > 1 {
+			result = append(result, group)
+		}
+		group = nil
+		inGroup = false
+	}
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasSuffix(line,
+			"These services have identical rule definitions.") {
+			add()
+			inGroup = true
+		} else if !inGroup {
+			continue
+		} else if name, found := strings.CutPrefix(line, "- "); found {
+			group = append(group, strings.TrimSpace(name))
+		} else if group == nil &&
+			strings.HasPrefix(line, "A single service should be created") {
+			continue
+		} else {
+			add()
+		}
+	}
+	add()
+	return result, scanner.Err()
+}
+
+func Main(d oslink.Data) int {
+	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
+
+	// Setup custom usage function.
+	fs.Usage = func() {
+		fmt.Fprintf(d.Stderr,
+			"Usage: %s [options] FILE|DIR [service:]NAME ...\n%s",
+			d.Args[0], fs.FlagUsages())
+	}
+
+	// Command line flags
+	quiet := fs.BoolP("quiet", "q", false, "Don't show changed files")
+	fromFile := fs.StringP("file", "f", "",
+		"Read groups of identical services from warnings in file")
+	if err := fs.Parse(d.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 1
+		}
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		fs.Usage()
+		return 1
+	}
+
+	// Argument processing
+	args := fs.Args()
+	if len(args) == 0 || *fromFile == "" && len(args) < 3 {
+		fs.Usage()
+		return 1
+	}
+	var groups [][]string
+	if *fromFile != "" {
+		l, err := readGroups(*fromFile)
+		if err != nil {
+			fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		groups = l
+	}
+	if len(args) > 1 {
+		groups = append(groups, args[1:])
+	}
+
+	s := new(state)
+	var err error
+	s.State, err = astset.Read(args[0])
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error while reading netspoc files: %s\n", err)
+		return 1
+	}
+	for _, names := range groups {
+		if err := s.merge(names); err != nil {
+			fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+	s.ShowChanged(d.Stderr, *quiet)
+	s.Print()
+	return 0
+}
+
+// Merge services into first service of names.
+func (s *state) merge(names []string) error {
+	var l []*ast.Service
+	for _, name := range names {
+		if !strings.HasPrefix(name, "service:") {
+			name = "service:" + name
+		}
+		svc, ok := s.FindToplevel(name).(*ast.Service)
+		if !ok {
+			return fmt.Errorf("Can't find %s", name)
+		}
+		if !slices.Contains(l, svc) {
+			l = append(l, svc)
+		}
+	}
+	if len(l) < 2 {
+		return nil
+	}
+	first := l[0]
+	for _, svc := range l[1:] {
+		if err := checkMerge(first, svc); err != nil {
+			return err
+		}
+	}
+	// Combine lists of users, descriptions and attributes.
+	seen := make(map[string]bool)
+	for _, el := range first.User.Elements {
+		seen[el.String()] = true
+	}
+	var descr []string
+	addDescr := func(svc *ast.Service) {
+		if d := svc.Description; d != nil {
+			t := strings.TrimRight(d.Text, " \t\r;")
+			if t != "" && !slices.Contains(descr, t) {
+				descr = append(descr, t)
+			}
+		}
+	}
+	addDescr(first)
+	for _, svc := range l[1:] {
+		for _, el := range svc.User.Elements {
+			if key := el.String(); !seen[key] {
+				seen[key] = true
+				first.User.Elements = append(first.User.Elements, el)
+			}
+		}
+		addDescr(svc)
+		for _, a := range svc.Attributes {
+			if svcListAttr[a.Name] {
+				s.addValues(first, a)
+			}
+		}
+	}
+	first.User.Order()
+	if len(descr) > 0 {
+		if first.Description == nil {
+			first.Description = new(ast.Description)
+		}
+		first.Description.Text = strings.Join(descr, "; ")
+	}
+	s.Modify(func(t ast.Toplevel) bool { return t == first })
+
+	// Change references to merged services, then remove them.
+	for _, svc := range l[1:] {
+		s.replaceRefs(svc.Name, first.Name)
+		s.DeleteToplevelNode(svc)
+	}
+	// Service must not reference itself.
+	for name := range svcListAttr {
+		if a := first.GetAttr(name); a != nil {
+			a.RemoveFromList(first.Name)
+			if len(a.ValueList) == 0 {
+				first.RemoveAttr(name)
+			}
+		}
+	}
+	return nil
+}
+
+// Check that both services have identical rules and compatible
+// attributes.
+func checkMerge(s1, s2 *ast.Service) error {
+	errorf := func(format string, args ...any) error {
+		return fmt.Errorf("Can't merge %s and %s: "+format,
+			append([]any{s1.Name, s2.Name}, args...)...)
+	}
+	if s1.Foreach != s2.Foreach {
+		return errorf("only one uses 'foreach'")
+	}
+	if rulesKey(s1) != rulesKey(s2) {
+		return errorf("rules differ")
+	}
+	attrNames := make(map[string]bool)
+	for _, a := range s1.Attributes {
+		attrNames[a.Name] = true
+	}
+	for _, a := range s2.Attributes {
+		attrNames[a.Name] = true
+	}
+	for name := range attrNames {
+		if svcListAttr[name] {
+			continue
+		}
+		if attrKey(s1.GetAttr(name)) != attrKey(s2.GetAttr(name)) {
+			return errorf("values of attribute '%s' differ", name)
+		}
+	}
+	return nil
+}
+
+// Textual representation of rules, independent of order of elements.
+func rulesKey(svc *ast.Service) string {
+	var b strings.Builder
+	elements := func(u *ast.NamedUnion) {
+		var l []string
+		for _, el := range u.Elements {
+			l = append(l, el.String())
+		}
+		slices.Sort(l)
+		b.WriteString(strings.Join(l, ",") + ";")
+	}
+	for _, r := range svc.Rules {
+		if r.Deny {
+			b.WriteString("deny ")
+		} else {
+			b.WriteString("permit ")
+		}
+		elements(r.Src)
+		elements(r.Dst)
+		b.WriteString(attrKey(r.Prt) + ";" + attrKey(r.Log) + "\n")
+	}
+	return b.String()
+}
+
+func attrKey(a *ast.Attribute) string {
+	if a == nil {
+		return ""
+	}
+	var l []string
+	for _, v := range a.ValueList {
+		l = append(l, v.Value)
+	}
+	slices.Sort(l)
+	return strings.Join(l, ",")
+}
+
+// Add values of attribute to attribute of same name in service.
+func (s *state) addValues(svc *ast.Service, a *ast.Attribute) {
+	b := svc.GetAttr(a.Name)
+	if b == nil {
+		b = &ast.Attribute{Name: a.Name}
+		svc.Attributes = append(svc.Attributes, b)
+	}
+	for _, v := range a.ValueList {
+		if !slices.ContainsFunc(b.ValueList, func(w *ast.Value) bool {
+			return w.Value == v.Value
+		}) {
+			b.ValueList = append(b.ValueList, &ast.Value{Value: v.Value})
+		}
+	}
+}
+
+// Replace references to service 'from' by 'to' in attributes of
+// other services.
+func (s *state) replaceRefs(from, to string) {
+	s.Modify(func(t ast.Toplevel) bool {
+		svc, ok := t.(*ast.Service)
+		if !ok {
+			return false
+		}
+		changed := false
+		for _, a := range svc.Attributes {
+			if !svcListAttr[a.Name] {
+				continue
+			}
+			for _, v := range a.ValueList {
+				if v.Value == from {
+					a.RemoveFromList(from)
+					if svc.Name != to && !slices.ContainsFunc(a.ValueList,
+						func(w *ast.Value) bool { return w.Value == to }) {
+						a.ValueList = append(a.ValueList, &ast.Value{Value: to})
+					}
+					changed = true
+					break
+				}
+			}
+		}
+		return changed
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 type Data struct {
 	Args     []string
+	Stdin    io.Reader
 	Stdout   io.Writer
 	Stderr   io.Writer
 	ShowDiag bool
@@ -15,6 +16,7 @@ type Data struct {
 func Get() Data {
 	return Data{
 		Args:     os.Args,
+		Stdin:    os.Stdin,
 		Stdout:   os.Stdout,
 		Stderr:   os.Stderr,
 		ShowDiag: os.Getenv("SHOW_DIAG") != "",
@@ -5,6 +5,7 @@ package parser
 
 import (
 	"strings"
+	"unicode"
 
 	"github.com/hknutzen/Netspoc/go/pkg/ast"
 	"github.com/hknutzen/Netspoc/go/pkg/scanner"
@@ -14,18 +15,40 @@ type Mode uint
 
 const (
 	ParseComments Mode = 1 << iota // parse comments and add them to AST
+	AllErrors                      // report all errors, not only the first one
 )
 
+// ErrorList is returned by ParseFile in mode AllErrors.
+// It holds all syntax errors found in a file.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	var b strings.Builder
+	for i, e := range l {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+func (l ErrorList) Unwrap() []error { return l }
+
 // The parser structure holds the parser's internal state.
 type parser struct {
 	scanner       scanner.Scanner
 	fileName      string
 	parseComments bool
+	allErrors     bool
+	errors        ErrorList
 
 	// Next token
 	pos   int    // token position
 	isSep bool   // token is single separator character
 	tok   string // token literal, one token look-ahead
+
+	prevEnd int // position after previous token
 }
 
 func (p *parser) init(src []byte, fname string, mode Mode) {
@@ -34,6 +57,7 @@ func (p *parser) init(src []byte, fname string, mode Mode) {
 	p.scanner.Init(src, fname, ah)
 	p.fileName = fname
 	p.parseComments = mode&ParseComments != 0
+	p.allErrors = mode&AllErrors != 0
 
 	p.next()
 }
@@ -41,31 +65,47 @@ func (p *parser) init(src []byte, fname string, mode Mode) {
 // ----------------------------------------------------------------------------
 // Parsing support
 
+func (p *parser) setToken(pos int, isSep bool, tok string) {
+	p.prevEnd = p.pos + len(p.tok)
+	p.pos, p.isSep, p.tok = pos, isSep, tok
+}
+
 // Advance to the next token.
 func (p *parser) next() {
-	p.pos, p.isSep, p.tok = p.scanner.Token()
+	p.setToken(p.scanner.Token())
 }
 
 // Advance to the next token, but take "-", "/" and ":" as separator.
 func (p *parser) nextProto() {
-	p.pos, p.isSep, p.tok = p.scanner.ProtoToken()
+	p.setToken(p.scanner.ProtoToken())
 }
 
 // Advance to the next token, but take "-" as separator.
 func (p *parser) nextIPRange() {
-	p.pos, p.isSep, p.tok = p.scanner.IPRangeToken()
+	p.setToken(p.scanner.IPRangeToken())
 }
 
 // Advance to the next token, but token contains any character except
 // ';', '#', '\n'. A single ";" may be returned.
 func (p *parser) nextSingle() {
-	p.pos, p.isSep, p.tok = p.scanner.TokenToSemicolon()
+	p.setToken(p.scanner.TokenToSemicolon())
 }
 
 // Advance to the next token, but token contains any character except
 // ',', ';', '#', '\n'. A single "," or ";" may be returned.
 func (p *parser) nextMulti() {
-	p.pos, p.isSep, p.tok = p.scanner.TokenToComma()
+	p.setToken(p.scanner.TokenToComma())
+}
+
+func (p *parser) position(pos int) ast.Position {
+	line, col := p.scanner.LineColumn(pos)
+	return ast.Position{Offset: pos, Line: line, Column: col}
+}
+
+// Set position of node a from position start up to end of
+// previously read token.
+func (p *parser) setPos(a ast.Node, start int) {
+	a.SetPos(p.position(start), p.position(p.prevEnd))
 }
 
 func (p *parser) syntaxErr(format string, args ...any) {
@@ -279,6 +319,7 @@ func init() {
 }
 
 func (p *parser) extendedName() ast.Element {
+	start := p.pos
 	preCmt := p.readPreCmt("&")
 	postCmt := p.readPostCmtAfter(",;&!")
 	var result ast.Element
@@ -304,16 +345,19 @@ func (p *parser) extendedName() ast.Element {
 	if result.PostComment() == "" {
 		result.SetPostComment(postCmt)
 	}
+	p.setPos(result, start)
 	return result
 }
 
 func (p *parser) complement() ast.Element {
+	start := p.pos
 	if p.check("!") {
 		a := new(ast.Complement)
 		c := p.readPreCmt("&!")
 		el := p.extendedName()
 		el.SetPreComment(c)
 		a.Element = el
+		p.setPos(a, start)
 		return a
 	} else {
 		return p.extendedName()
@@ -321,6 +365,7 @@ func (p *parser) complement() ast.Element {
 }
 
 func (p *parser) intersection() ast.Element {
+	start := p.pos
 	intersection := []ast.Element{p.complement()}
 	for p.check("&") {
 		intersection = append(intersection, p.complement())
@@ -328,6 +373,7 @@ func (p *parser) intersection() ast.Element {
 	if len(intersection) > 1 {
 		a := new(ast.Intersection)
 		a.Elements = intersection
+		p.setPos(a, start)
 		return a
 	} else {
 		return intersection[0]
@@ -356,12 +402,15 @@ func (p *parser) union(stopToken string) ([]ast.Element, int) {
 
 func (p *parser) description() *ast.Description {
 	preCmt := p.readPreCmt("")
+	start := p.pos
 	if p.check("description") {
 		p.expectLeave("=")
 		p.pos, p.tok = p.scanner.ToEOL()
 		a := new(ast.Description)
 		a.Text = strings.TrimSpace(p.tok)
 		a.SetPreComment(preCmt)
+		end := p.pos + len(strings.TrimRightFunc(p.tok, unicode.IsSpace))
+		a.SetPos(p.position(start), p.position(end))
 		p.next()
 		return a
 	}
@@ -376,10 +425,12 @@ func (p *parser) name() string {
 
 func (p *parser) value(nextSpecial func(*parser)) *ast.Value {
 	a := new(ast.Value)
+	start := p.pos
 	a.SetPreComment(p.readPreCmt(""))
 	a.SetPostComment(p.readPostCmtAfter(",;}"))
 	a.Value = p.getNonSep()
 	nextSpecial(p)
+	p.setPos(a, start)
 	return a
 }
 
@@ -394,6 +445,7 @@ func (p *parser) addMulti(a *ast.Value, nextSpecial func(*parser)) {
 		nextSpecial(p)
 	}
 	a.SetPostComment(p.readPostCmtAfter(",;}"))
+	p.setPos(a, a.Start().Offset)
 }
 
 func (p *parser) multiValue(nextSpecial func(*parser)) *ast.Value {
@@ -454,10 +506,12 @@ var specialValueAttr = map[string]func(*parser, func(*parser)) *ast.Value{
 
 func (p *parser) specialAttribute(nextSpecial func(*parser)) *ast.Attribute {
 	a := new(ast.Attribute)
+	start := p.pos
 	a.SetPreComment(p.readPreCmt(""))
 	a.SetPostComment(p.readPostCmtAfter(";={}"))
 	a.Name = p.name()
 	if p.check(";") {
+		p.setPos(a, start)
 		return a
 	}
 	if n := specialTokenAttr[a.Name]; n != nil {
@@ -476,6 +530,7 @@ func (p *parser) specialAttribute(nextSpecial func(*parser)) *ast.Attribute {
 		}
 		a.ValueList = p.valueList(getValue, nextSpecial)
 	}
+	p.setPos(a, start)
 	return a
 }
 
@@ -534,16 +589,19 @@ func (p *parser) protocol() ast.Toplevel {
 
 func (p *parser) namedUnion() *ast.NamedUnion {
 	a := new(ast.NamedUnion)
+	start := p.pos
 	a.SetPreComment(p.readPreCmt(""))
 	a.SetPostComment(p.readPostCmtAfter("=;"))
 	a.Name = p.name()
 	p.expect("=")
 	a.Elements, _ = p.union(";")
+	p.setPos(a, start)
 	return a
 }
 
 func (p *parser) rule() *ast.Rule {
 	a := new(ast.Rule)
+	start := p.pos
 	a.SetPreComment(p.readPreCmt(""))
 	switch p.tok {
 	case "deny":
@@ -563,6 +621,7 @@ func (p *parser) rule() *ast.Rule {
 	default:
 		p.syntaxErr("Expected 'permit' or 'deny'")
 	}
+	p.setPos(a, start)
 	return a
 }
 
@@ -588,6 +647,7 @@ func (p *parser) service() ast.Toplevel {
 		a.Attributes = append(a.Attributes, p.attribute())
 	}
 	u := new(ast.NamedUnion)
+	start := p.pos
 	u.SetPreComment(p.readPreCmt(""))
 	u.SetPostComment(p.readPostCmtAfter("=;"))
 	p.expectLeave("user")
@@ -597,6 +657,7 @@ func (p *parser) service() ast.Toplevel {
 		a.Foreach = true
 	}
 	u.Elements, _ = p.union(";")
+	p.setPos(u, start)
 	a.User = u
 	for !p.check("}") {
 		a.Rules = append(a.Rules, p.rule())
@@ -678,6 +739,7 @@ func init() {
 }
 
 func (p *parser) toplevel() ast.Toplevel {
+	start := p.pos
 	typ, _ := p.typedName()
 	m, found := globalType[typ]
 	if !found {
@@ -685,6 +747,7 @@ func (p *parser) toplevel() ast.Toplevel {
 	}
 	n := m(p)
 	n.SetFileName(p.fileName)
+	p.setPos(n, start)
 	return n
 }
 
@@ -692,11 +755,45 @@ func (p *parser) toplevel() ast.Toplevel {
 func (p *parser) file() []ast.Toplevel {
 	var result []ast.Toplevel
 	for p.tok != "" {
-		result = append(result, p.toplevel())
+		if !p.allErrors {
+			result = append(result, p.toplevel())
+			continue
+		}
+		start := p.pos
+		err := handlePanic(func() { result = append(result, p.toplevel()) })
+		if err != nil {
+			p.errors = append(p.errors, err)
+			p.sync(start)
+		}
 	}
 	return result
 }
 
+// Skip tokens after syntax error up to start of next toplevel
+// definition. A toplevel definition is recognized by its typed name,
+// found at start of line or after ';' or '}'.
+// Parameter start is position of the definition, where the error was
+// found. It must not be taken again.
+func (p *parser) sync(start int) {
+	isToplevel := func() bool {
+		typ, name, found := strings.Cut(p.tok, ":")
+		return found && name != "" && globalType[typ] != nil && p.pos > start
+	}
+	if isToplevel() && p.scanner.AtLineStart(p.pos) {
+		return
+	}
+	for p.tok != "" {
+		afterEnd := p.tok == ";" || p.tok == "}"
+		if err := handlePanic(p.next); err != nil {
+			p.errors = append(p.errors, err)
+			continue
+		}
+		if isToplevel() && (afterEnd || p.scanner.AtLineStart(p.pos)) {
+			return
+		}
+	}
+}
+
 // A bailout panic is raised to indicate early termination.
 type bailout struct {
 	err error
@@ -717,11 +814,14 @@ func handlePanic(f func()) (err error) {
 	return
 }
 
+// ParseFile parses the source code of a single Netspoc file.
+// In mode AllErrors, parsing continues after a syntax error at the
+// next toplevel definition and an ErrorList is returned.
 func ParseFile(
 	src []byte, fName string, mode Mode) (f *ast.File, err error) {
 
+	p := new(parser)
 	err = handlePanic(func() {
-		p := new(parser)
 		p.init(src, fName, mode)
 		f = new(ast.File)
 		f.Nodes = p.file()
@@ -729,6 +829,12 @@ func ParseFile(
 			f.BottomCmt = p.scanner.PreCmt(len(src), "")
 		}
 	})
+	if err != nil && p.allErrors {
+		p.errors = append(p.errors, err)
+	}
+	if len(p.errors) != 0 {
+		err = p.errors
+	}
 	return
 }
 
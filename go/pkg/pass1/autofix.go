@@ -0,0 +1,159 @@
+package pass1
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hknutzen/Netspoc/go/pkg/ast"
+)
+
+// Collect jobs for Netspoc-API, that fix some warnings mechanically.
+// Each job deletes some attribute, value, element, rule or toplevel
+// definition. Warnings that could only be fixed by replacing or
+// moving elements between rules or services aren't handled.
+type autofix struct {
+	mutex sync.Mutex
+	jobs  []fixJob
+}
+
+type fixJob struct {
+	path   string
+	values []string
+	// Number of rule, if complete rule is deleted.
+	ruleNum int
+}
+
+// Rank of job, used for ordering of jobs.
+// Jobs that delete complete rules must be applied after jobs that
+// change elements of rules.
+// Jobs that delete toplevel definitions are applied last.
+func (j fixJob) rank() int {
+	if j.ruleNum != 0 {
+		return 1
+	}
+	if !strings.Contains(j.path, ",") {
+		return 2
+	}
+	return 0
+}
+
+func (c *spoc) addFix(path string, values ...string) {
+	c.addFixJob(fixJob{path: path, values: values})
+}
+
+func (c *spoc) addFixJob(j fixJob) {
+	if c.autofix == nil {
+		return
+	}
+	c.autofix.mutex.Lock()
+	defer c.autofix.mutex.Unlock()
+	c.autofix.jobs = append(c.autofix.jobs, j)
+}
+
+// Add fix for warning "Useless 'ATTR' at service:NAME"
+// or "Useless 'ATTR = service:OTHER' at service:NAME".
+func (c *spoc) fixUselessSvcAttr(attr string, svc *service) {
+	if name, val, found := strings.Cut(attr, " = "); found {
+		c.addFix(svc.name+","+name, val)
+	} else {
+		c.addFix(svc.name + "," + attr)
+	}
+}
+
+// Add fix for duplicate elements found in expanded list of elements.
+// Only elements that are directly given in l are removed.
+// An element is removed only as often as it occurs in some other way.
+func (c *spoc) fixDuplicates(
+	path string, l []ast.Element, count map[groupObj]int) {
+
+	if path == "" {
+		return
+	}
+	del := make(map[string]int)
+	for obj, n := range count {
+		if n < 2 {
+			continue
+		}
+		name := obj.String()
+		k := 0
+		for _, el := range l {
+			if el.String() == name {
+				k++
+			}
+		}
+		del[name] = max(del[name], min(k, n-1))
+	}
+	var values []string
+	for name, n := range del {
+		for range n {
+			values = append(values, name)
+		}
+	}
+	if values != nil {
+		slices.Sort(values)
+		c.addFix(path, values...)
+	}
+}
+
+func (c *spoc) writeAutofix(stdout io.Writer) {
+	path := c.conf.Autofix
+	if path == "" {
+		return
+	}
+	jobs := c.autofix.jobs
+	slices.SortFunc(jobs, func(a, b fixJob) int {
+		if r := cmp.Compare(a.rank(), b.rank()); r != 0 {
+			return r
+		}
+		if a.ruleNum != 0 {
+			// Delete rules of same service in descending order,
+			// so numbers of remaining rules are unchanged.
+			svc1, _, _ := strings.Cut(a.path, ",")
+			svc2, _, _ := strings.Cut(b.path, ",")
+			if r := cmp.Compare(svc1, svc2); r != 0 {
+				return r
+			}
+			return cmp.Compare(b.ruleNum, a.ruleNum)
+		}
+		if r := cmp.Compare(a.path, b.path); r != 0 {
+			return r
+		}
+		return slices.Compare(a.values, b.values)
+	})
+	// Remove identical jobs, e.g. from multiple expansion of same group.
+	jobs = slices.CompactFunc(jobs, func(a, b fixJob) bool {
+		return a.path == b.path && a.ruleNum == b.ruleNum &&
+			slices.Equal(a.values, b.values)
+	})
+	result := make([]jsonMap, len(jobs))
+	for i, j := range jobs {
+		params := jsonMap{"path": j.path}
+		if j.ruleNum != 0 {
+			params["path"] = fmt.Sprintf("%s,rules,%d", j.path, j.ruleNum)
+		}
+		switch len(j.values) {
+		case 0:
+		case 1:
+			params["value"] = j.values[0]
+		default:
+			params["value"] = j.values
+		}
+		result[i] = jsonMap{"method": "delete", "params": params}
+	}
+	data := jsonMap{
+		"method": "multi_job",
+		"params": jsonMap{"jobs": result},
+	}
+	if path == "-" {
+		enc := json.NewEncoder(stdout)
+		enc.SetEscapeHTML(false)
+		enc.Encode(data)
+	} else {
+		c.writeJson(path, data)
+	}
+}
@@ -1,7 +1,10 @@
 package pass1
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
+	"sync"
 )
 
 type spocWait chan struct{}
@@ -16,6 +19,7 @@ func (c *spoc) startWithBackground(fg func(*spoc), bg func(*spoc)) {
 	}
 	// Start background job.
 	// Channel is used to signal that background job has finished.
+	c.profileConcurrent(true)
 	ch := make(spocWait)
 	c2 := c.bufferedSpoc()
 	go handleBailout(
@@ -32,6 +36,7 @@ func (c *spoc) startWithBackground(fg func(*spoc), bg func(*spoc)) {
 	// Wait until background job has finished, i.e. channel is closed,
 	// then forward messages of background job to main job.
 	<-ch
+	c.profileConcurrent(false)
 	if c.conf.TimeStamps {
 		c.progress("Output of background job:")
 		re := regexp.MustCompile(`^\d+s `)
@@ -43,3 +48,84 @@ func (c *spoc) startWithBackground(fg func(*spoc), bg func(*spoc)) {
 	}
 	c.sendBuf(c2)
 }
+
+// Run n independent jobs on a pool of workers.
+// Size of pool is given by option --concurrency_pass1.
+// Jobs are run sequentially if concurrency is disabled.
+// Each concurrent job writes its messages into a separate buffer.
+// Buffers are forwarded in order of jobs after all jobs have finished.
+// Hence messages are shown in the same order as from sequential run.
+func (c *spoc) parallel(n int, job func(c *spoc, i int)) {
+	workers := min(c.conf.ConcurrencyPass1, n)
+	if workers <= 1 {
+		for i := range n {
+			job(c, i)
+		}
+		return
+	}
+	buf := make([]*spoc, n)
+	for i := range buf {
+		buf[i] = c.bufferedSpoc()
+		buf[i].concurrent = true
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				c2 := buf[i]
+				handleBailout(func() { job(c2, i) }, func() {})
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	for _, c2 := range buf {
+		c.sendJobBuf(c2)
+	}
+}
+
+// Forward messages of concurrent job like sendBuf.
+// Each job has counted its errors starting from the same value.
+// Hence errors are counted again over all jobs and forwarding stops,
+// when maximum number of errors is reached, as in sequential run.
+func (c *spoc) sendJobBuf(c2 *spoc) {
+	msgs := c2.messages
+	for i, msg := range msgs {
+		if strings.HasPrefix(msg, "Aborted with ") {
+			msg = fmt.Sprintf("Aborted with %d error(s)", c.errCount)
+		}
+		c.toStderr(msg)
+		if !strings.HasPrefix(msg, "Error: ") {
+			continue
+		}
+		c.errCount++
+		if c.errCount >= c.conf.MaxErrors {
+			// Error from c.abort is followed by its own message.
+			if i+1 < len(msgs) && msgs[i+1] == "Aborted" {
+				c.toStderr(msgs[i+1])
+			} else {
+				c.toStderrf("Aborted after %d errors", c.errCount)
+			}
+			c.terminate()
+		}
+	}
+	if c2.aborted {
+		c.terminate()
+	}
+}
+
+// Split n elements into contiguous chunks and process each chunk
+// as separate job of c.parallel.
+// More chunks than workers are used to balance chunks of different cost.
+func (c *spoc) parallelChunks(n int, job func(c *spoc, from, to int)) {
+	count := min(n, 4*c.conf.ConcurrencyPass1)
+	c.parallel(count, func(c *spoc, i int) {
+		job(c, i*n/count, (i+1)*n/count)
+	})
+}
@@ -0,0 +1,135 @@
+package pass1
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Check that algorithms and lifetimes of isakmp and ipsec used in
+// crypto definitions comply with crypto policy from configuration.
+func (c *spoc) checkCryptoPolicy() {
+	printType := c.conf.CheckCryptoPolicy
+	if printType == "" {
+		return
+	}
+	cnf := c.conf
+	clean := func(l []string) []string {
+		var result []string
+		for _, v := range l {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+		return result
+	}
+	encryption := clean(cnf.CryptoPolicyEncryption)
+	hash := clean(cnf.CryptoPolicyHash)
+
+	// Collect violations of policy as "attribute = value".
+	var violations []string
+	checkList := func(allowed []string, attr, v string) {
+		if v == "" {
+			v = "none"
+		}
+		if allowed != nil && !slices.Contains(allowed, v) {
+			violations = append(violations, attr+" = "+v)
+		}
+	}
+	checkGroup := func(attr, v string) {
+		if n, _ := strconv.Atoi(v); v != "" && n < cnf.CryptoPolicyMinGroup {
+			violations = append(violations, attr+" = "+v)
+		}
+	}
+	checkLifetime := func(sec, min int) {
+		if sec != -1 && sec < min {
+			violations = append(violations, fmt.Sprintf("lifetime = %d sec", sec))
+		}
+	}
+
+	// Check each isakmp and ipsec definition only once.
+	isakmpMsg := make(map[*isakmp]string)
+	ipsecMsg := make(map[*ipsec]string)
+	getMsg := func(name string) string {
+		if violations == nil {
+			return ""
+		}
+		msg := " " + name + ": " + strings.Join(violations, ", ")
+		violations = nil
+		return msg
+	}
+	checkIsakmp := func(is *isakmp) string {
+		if msg, found := isakmpMsg[is]; found {
+			return msg
+		}
+		if is.ikeVersion < cnf.CryptoPolicyMinIkeVersion {
+			violations = append(violations,
+				"ike_version = "+strconv.Itoa(is.ikeVersion))
+		}
+		checkList(encryption, "encryption", is.encryption)
+		checkList(hash, "hash", is.hash)
+		checkGroup("group", is.group)
+		checkLifetime(is.lifetime, cnf.CryptoPolicyMinIsakmpLifetime)
+		msg := getMsg(is.name)
+		isakmpMsg[is] = msg
+		return msg
+	}
+	checkIpsec := func(is *ipsec) string {
+		if msg, found := ipsecMsg[is]; found {
+			return msg
+		}
+		if is.ah != "" {
+			checkList(hash, "ah", is.ah)
+		}
+		if is.ah == "" || is.espEncryption != "" {
+			checkList(encryption, "esp_encryption", is.espEncryption)
+		}
+		if is.espAuthentication != "" {
+			checkList(hash, "esp_authentication", is.espAuthentication)
+		}
+		checkGroup("pfs_group", is.pfsGroup)
+		if pair := is.lifetime; pair != nil {
+			checkLifetime(pair[0], cnf.CryptoPolicyMinIpsecLifetime)
+		}
+		msg := getMsg(is.name)
+		ipsecMsg[is] = msg
+		return msg
+	}
+
+	l := slices.SortedFunc(maps.Values(c.symTable.crypto),
+		func(a, b *crypto) int { return cmp.Compare(a.name, b.name) })
+	for _, cr := range l {
+		ipsec := cr.ipsec
+		if ipsec == nil || ipsec.isakmp == nil {
+			continue
+		}
+		var msgs stringList
+		for _, msg := range []string{checkIpsec(ipsec), checkIsakmp(ipsec.isakmp)} {
+			if msg != "" {
+				msgs.push(msg)
+			}
+		}
+		if msgs == nil {
+			continue
+		}
+		var routers stringList
+		for _, tunnel := range cr.tunnels {
+			for _, intf := range tunnel.interfaces {
+				name := intf.router.name
+				if !slices.Contains(routers, name) {
+					routers.push(name)
+				}
+			}
+		}
+		slices.Sort(routers)
+		msg := cr.name + " doesn't comply with crypto policy:\n" +
+			strings.Join(msgs, "\n")
+		if routers != nil {
+			msg += "\n Used at:\n - " + strings.Join(routers, "\n - ")
+		}
+		c.warnOrErr(printType, "%s", msg)
+	}
+}
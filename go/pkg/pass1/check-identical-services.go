@@ -204,7 +204,7 @@ func (c *spoc) checkIdenticalServices(sRules *serviceRules) {
 			s.rules = sorted
 		}
 		svcEq := func(s1, s2 *service) bool {
-			if s1.disableAt != s2.disableAt {
+			if s1.disableAt != s2.disableAt || s1.enableAt != s2.enableAt {
 				return false
 			}
 			l1 := s1.rules
@@ -12,6 +12,61 @@ type redundInfo struct {
 	hasSameDupl        map[*service][]*service
 	overlapsUsed       map[[2]*service]bool
 	overlapsRestricted map[*service]bool
+	// Count expanded and covered rules of each rule,
+	// used to fix redundant rules.
+	expandedCount map[*unexpRule]int
+	coveredCount  map[*unexpRule]int
+	// Count expanded, duplicate and redundant rules of each service.
+	// Counters are added to service after concurrent processing.
+	ruleCount      map[*service]int
+	duplicateCount map[*service]int
+	redundantCount map[*service]int
+}
+
+func newRedundInfo() *redundInfo {
+	return &redundInfo{
+		hasSameDupl:        make(map[*service][]*service),
+		overlapsUsed:       make(map[[2]*service]bool),
+		overlapsRestricted: make(map[*service]bool),
+		expandedCount:      make(map[*unexpRule]int),
+		coveredCount:       make(map[*unexpRule]int),
+		ruleCount:          make(map[*service]int),
+		duplicateCount:     make(map[*service]int),
+		redundantCount:     make(map[*service]int),
+	}
+}
+
+// Add results of ri2 to ri.
+func (c *spoc) mergeRedundInfo(ri, ri2 *redundInfo) {
+	ri.duplicate = append(ri.duplicate, ri2.duplicate...)
+	ri.redundant = append(ri.redundant, ri2.redundant...)
+	for sv, l := range ri2.hasSameDupl {
+		ri.hasSameDupl[sv] = append(ri.hasSameDupl[sv], l...)
+	}
+	for pair := range ri2.overlapsUsed {
+		ri.overlapsUsed[pair] = true
+	}
+	for sv := range ri2.overlapsRestricted {
+		if !ri.overlapsRestricted[sv] {
+			ri.overlapsRestricted[sv] = true
+			c.warn("Attribute 'overlaps' is blocked at %s", sv)
+		}
+	}
+	for ru, n := range ri2.expandedCount {
+		ri.expandedCount[ru] += n
+	}
+	for ru, n := range ri2.coveredCount {
+		ri.coveredCount[ru] += n
+	}
+	for sv, n := range ri2.ruleCount {
+		sv.ruleCount += n
+	}
+	for sv, n := range ri2.duplicateCount {
+		sv.duplicateCount += n
+	}
+	for sv, n := range ri2.redundantCount {
+		sv.redundantCount += n
+	}
 }
 
 type expandedRule struct {
@@ -20,6 +75,9 @@ type expandedRule struct {
 	dst       someObj
 	prt       *proto
 	redundant bool
+	// Rule is duplicate or redundant compared to some other rule
+	// of same service.
+	covered bool
 }
 
 func fillExpandedRule(rule *groupedRule) *expandedRule {
@@ -108,7 +166,8 @@ func getOrigPrt(ru *expandedRule) *proto {
 // New relation is used in findRedundantRules.
 // We get better performance compared to original relation, because
 // transient chain from some protocol to largest protocol becomes shorter.
-func setLocalPrtRelation(rules []*groupedRule) {
+// Relation is returned as map, because rules are processed concurrently.
+func getLocalPrtRelation(rules []*groupedRule) map[*proto]*proto {
 	prtMap := make(map[*proto]bool)
 	for _, rule := range rules {
 		prtList := rule.prt
@@ -116,31 +175,29 @@ func setLocalPrtRelation(rules []*groupedRule) {
 			prtMap[prt] = true
 		}
 	}
+	localUp := make(map[*proto]*proto)
 	for prt := range prtMap {
-		var localUp *proto
 		for up := prt.up; up != nil; up = up.up {
 			if prtMap[up] {
-				localUp = up
+				localUp[prt] = up
 				break
 			}
 		}
-		prt.localUp = localUp
 	}
+	return localUp
 }
 
 // Returns true, if overlap should be ignored.
 func (c *spoc) checkAttrOverlaps(
 	sv, osv *service, rule *expandedRule, ri *redundInfo) bool {
 
-	srcAttr := getAttr(rule.src, overlapsAttr)
-	dstAttr := getAttr(rule.dst, overlapsAttr)
+	srcAttr := lookupAttr(rule.src, overlapsAttr)
+	dstAttr := lookupAttr(rule.dst, overlapsAttr)
 	if slices.Contains(sv.overlaps, osv) {
 		ri.overlapsUsed[[2]*service{sv, osv}] = true
 		if srcAttr == restrictVal && dstAttr == restrictVal {
-			if !ri.overlapsRestricted[sv] {
-				ri.overlapsRestricted[sv] = true
-				c.warn("Attribute 'overlaps' is blocked at %s", sv)
-			}
+			// Warning is shown in mergeRedundInfo.
+			ri.overlapsRestricted[sv] = true
 			return false
 		}
 		return true
@@ -164,10 +221,10 @@ func (c *spoc) collectDuplicateRules(
 	// because it must only be counted once, if it is both duplicate
 	// and redundandant.
 	rule.redundant = true
-	sv.duplicateCount++
+	ri.duplicateCount[sv]++
 	osv := other.rule.service
 	if !other.redundant {
-		osv.duplicateCount++
+		ri.duplicateCount[osv]++
 		other.redundant = true
 	}
 
@@ -192,6 +249,16 @@ func (c *spoc) collectDuplicateRules(
 
 	if c.conf.CheckDuplicateRules != "" {
 		ri.duplicate = append(ri.duplicate, [2]*expandedRule{rule, other})
+		// Mark only one of two identical rules.
+		if sv == osv && rule.rule != other.rule {
+			i1 := slices.Index(sv.rules, rule.rule)
+			i2 := slices.Index(sv.rules, other.rule)
+			if i1 > i2 {
+				rule.covered = true
+			} else {
+				other.covered = true
+			}
+		}
 	}
 }
 
@@ -224,7 +291,7 @@ func (c *spoc) collectRedundantRules(
 	if !rule.redundant {
 		rule.redundant = true
 		count++
-		sv.redundantCount++
+		ri.redundantCount[sv]++
 	}
 
 	if rule.overlaps && other.overlaps {
@@ -233,6 +300,11 @@ func (c *spoc) collectRedundantRules(
 
 	if !c.checkAttrOverlaps(sv, other.rule.service, rule, ri) {
 		ri.redundant = append(ri.redundant, [2]*expandedRule{rule, other})
+		if sv == other.rule.service && rule.rule != other.rule &&
+			c.conf.CheckRedundantRules != "" {
+
+			rule.covered = true
+		}
 	}
 	return count
 }
@@ -286,6 +358,32 @@ func (c *spoc) showFullyRedundantRules(ri *redundInfo) {
 	}
 }
 
+// Count expanded rules and those covered by other rules of same service.
+func (c *spoc) countCoveredRules(rules []*expandedRule, ri *redundInfo) {
+	if c.autofix == nil {
+		return
+	}
+	for _, rule := range rules {
+		ru := rule.rule
+		ri.expandedCount[ru]++
+		if rule.covered {
+			ri.coveredCount[ru]++
+		}
+	}
+}
+
+// Remove rule, if all its expanded rules are duplicate or redundant
+// compared to other rules of same service.
+func (c *spoc) fixRedundantRules(ri *redundInfo) {
+	for ru, n := range ri.coveredCount {
+		if n == ri.expandedCount[ru] {
+			sv := ru.service
+			c.addFixJob(
+				fixJob{path: sv.name, ruleNum: slices.Index(sv.rules, ru) + 1})
+		}
+	}
+}
+
 func (c *spoc) warnUnusedOverlaps(ri *redundInfo) {
 	for _, sv := range c.ascendingServices {
 		if sv.disabled {
@@ -300,7 +398,7 @@ func (c *spoc) warnUnusedOverlaps(ri *redundInfo) {
 	}
 }
 
-func expandRules(rules []*groupedRule) []*expandedRule {
+func expandRules(rules []*groupedRule, ri *redundInfo) []*expandedRule {
 	var result []*expandedRule
 	for _, rule := range rules {
 		sv := rule.rule.service
@@ -312,11 +410,67 @@ func expandRules(rules []*groupedRule) []*expandedRule {
 					e.dst = dst
 					e.prt = prt
 					result = append(result, e)
-					sv.ruleCount++
+					ri.ruleCount[sv]++
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Split rules into batches, such that expanded rules of different
+// batches can't be duplicate or redundant to each other.
+// This holds for rules, where src or dst objects have no common
+// enclosing object. Hence rules are grouped by pair of
+// outermost enclosing objects of src and dst.
+// Batches are used in low-memory mode, where only expanded rules
+// of a single batch are held in memory.
+func splitRules(rules []*groupedRule) [][]*groupedRule {
+	getTop := func(obj someObj) someObj {
+		for up := obj.getUp(); up != nil; up = up.getUp() {
+			obj = up
+		}
+		return obj
+	}
+	group := func(l []someObj) ([]someObj, map[someObj][]someObj) {
+		var tops []someObj
+		top2objs := make(map[someObj][]someObj)
+		for _, obj := range l {
+			top := getTop(obj)
+			if _, found := top2objs[top]; !found {
+				tops = append(tops, top)
+			}
+			top2objs[top] = append(top2objs[top], obj)
+		}
+		return tops, top2objs
+	}
+	type topPair [2]someObj
+	var pairs []topPair
+	pair2rules := make(map[topPair][]*groupedRule)
+	for _, rule := range rules {
+		srcTops, top2src := group(rule.src)
+		dstTops, top2dst := group(rule.dst)
+		for _, sTop := range srcTops {
+			for _, dTop := range dstTops {
+				key := topPair{sTop, dTop}
+				if _, found := pair2rules[key]; !found {
+					pairs = append(pairs, key)
+				}
+				part := rule
+				if len(srcTops) > 1 || len(dstTops) > 1 {
+					cp := *rule
+					cp.src = top2src[sTop]
+					cp.dst = top2dst[dTop]
+					part = &cp
 				}
+				pair2rules[key] = append(pair2rules[key], part)
 			}
 		}
 	}
+	result := make([][]*groupedRule, len(pairs))
+	for i, key := range pairs {
+		result[i] = pair2rules[key]
+	}
 	return result
 }
 
@@ -419,7 +573,9 @@ func (c *spoc) buildRuleTree(
 	return ruleTree, count
 }
 
-func (c *spoc) findRedundantRules(cmpHash ruleTree, ri *redundInfo) int {
+func (c *spoc) findRedundantRules(
+	cmpHash ruleTree, localUp map[*proto]*proto, ri *redundInfo) int {
+
 	chgHash := cmpHash
 	count := 0
 	for stateless, chgHash := range chgHash {
@@ -448,7 +604,7 @@ func (c *spoc) findRedundantRules(cmpHash ruleTree, ri *redundInfo) int {
 																				count += c.collectRedundantRules(chgRule, cmpRule, ri)
 																			}
 																		}
-																		prt = prt.localUp
+																		prt = localUp[prt]
 																		if prt == nil {
 																			break
 																		}
@@ -498,11 +654,7 @@ func (c *spoc) checkRedundantRules() {
 	count := 0
 	dcount := 0
 	rcount := 0
-	ri := &redundInfo{
-		hasSameDupl:        make(map[*service][]*service),
-		overlapsUsed:       make(map[[2]*service]bool),
-		overlapsRestricted: make(map[*service]bool),
-	}
+	ri := newRedundInfo()
 	// Sorts error messages before output.
 	c.sortedSpoc(func(c *spoc) {
 		// Process rules in chunks to reduce memory usage and allow
@@ -510,27 +662,68 @@ func (c *spoc) checkRedundantRules() {
 		// can't be redundant to each other.
 		type pathPair [2]pathStore
 		path2rules := make(map[pathPair][]*groupedRule)
+		var pairs []pathPair
 		add := func(rules []*groupedRule) {
 			for _, rule := range rules {
 				key := pathPair{rule.srcPath, rule.dstPath}
+				if _, found := path2rules[key]; !found {
+					pairs = append(pairs, key)
+				}
 				path2rules[key] = append(path2rules[key], rule)
 			}
 		}
 		add(c.allPathRules.deny)
 		add(c.allPathRules.permit)
-		for _, rules := range path2rules {
-			expandedRules := expandRules(rules)
-			count += len(expandedRules)
-			ruleTree, deleted := c.buildRuleTree(expandedRules, ri)
-			dcount += deleted
-			setLocalPrtRelation(rules)
-			rcount += c.findRedundantRules(ruleTree, ri)
+		type result struct {
+			ri                    *redundInfo
+			count, dcount, rcount int
+		}
+		// Result of each chunk is stored at index of its first element.
+		results := make([]*result, len(pairs))
+		check := func(c *spoc, from, to int) {
+			r := &result{ri: newRedundInfo()}
+			for _, key := range pairs[from:to] {
+				rules := path2rules[key]
+				localUp := getLocalPrtRelation(rules)
+				batches := [][]*groupedRule{rules}
+				if c.conf.MemoryLimit > 0 {
+					batches = splitRules(rules)
+				}
+				for _, batch := range batches {
+					expandedRules := expandRules(batch, r.ri)
+					r.count += len(expandedRules)
+					ruleTree, deleted := c.buildRuleTree(expandedRules, r.ri)
+					r.dcount += deleted
+					r.rcount += c.findRedundantRules(ruleTree, localUp, r.ri)
+					c.countCoveredRules(expandedRules, r.ri)
+				}
+			}
+			results[from] = r
+		}
+		if c.conf.MemoryLimit == 0 {
+			c.parallelChunks(len(pairs), check)
+		} else if len(pairs) != 0 {
+			// In low-memory mode, process all pairs sequentially
+			// and rules of each pair in batches.
+			// Hence expanded rules of only a single batch are held in memory.
+			check(c, 0, len(pairs))
+		}
+		for _, r := range results {
+			if r == nil {
+				continue
+			}
+			count += r.count
+			dcount += r.dcount
+			rcount += r.rcount
+			c.mergeRedundInfo(ri, r.ri)
 		}
 		c.showDuplicateRules(ri)
 		c.showRedundantRules(ri)
+		c.fixRedundantRules(ri)
 	})
 	c.warnUnusedOverlaps(ri)
 	c.showFullyRedundantRules(ri)
 	c.info("Expanded rule count: %d; duplicate: %d; redundant: %d",
 		count, dcount, rcount)
+	c.profileExpandedRules(count)
 }
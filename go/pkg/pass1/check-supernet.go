@@ -3,6 +3,7 @@ package pass1
 import (
 	"fmt"
 	"net/netip"
+	"sync"
 )
 
 // Two zones are zoneEq, if
@@ -75,6 +76,9 @@ type checkInfo struct {
 //                Additional rule required: "permit src->any:Y".
 //#############################################################################
 
+// Protects cache zone.ipPrefix2net, that is used by concurrent checks.
+var zoneNetCache sync.Mutex
+
 // Find aggregate in zone with address equal to ip/mask
 // or find networks in zone with address in subnet or supernet relation
 // to ip/mask.
@@ -87,7 +91,7 @@ type checkInfo struct {
 //
 // Result: List of found networks or aggregates.
 func findZoneNetworks(
-	z *zone, isAgg bool, ipp netip.Prefix, natMap natMap,
+	z *zone, isAgg bool, ipp netip.Prefix, natDom *natDomain,
 	netMap map[*network]bool) (*network, netList) {
 
 	// Check if argument or some supernet of argument is member of netMap.
@@ -127,39 +131,46 @@ func findZoneNetworks(
 	}
 
 	// Use cached result.
-	l, found := z.ipPrefix2net[ipp]
-
+	// Cache is shared by concurrent checks and hence must not depend
+	// on netMap of current rule.
+	key := zoneNetKey{ipp: ipp, isAgg: isAgg, natDomain: natDom}
+	zoneNetCache.Lock()
+	all, found := z.ipPrefix2net[key]
 	if !found {
 		// Check real networks in zone without aggregates and without subnets.
 		bits := ipp.Bits()
 		for _, net := range z.networks {
-			if inNetMap(net) {
-				continue
-			}
-			natNet := getNatNetwork(net, natMap)
+			natNet := getNatNetwork(net, natDom.natMap)
 			if natNet.hidden {
 				continue
 			}
 			if natNet.ipp.Bits() >= bits && ipp.Contains(natNet.ipp.Addr()) ||
 				isAgg && natNet.ipp.Bits() < bits && natNet.ipp.Contains(ipp.Addr()) {
 
-				l.push(net)
+				all.push(net)
 			}
 		}
 		// Check aggregates in zone.
 		for _, agg := range z.ipPrefix2aggregate {
 			// Igore aggregate with networks, because these networks
 			// have already been checked above.
-			if !agg.invisible && len(agg.networks) == 0 && !inNetMap(agg) {
+			if !agg.invisible && len(agg.networks) == 0 {
 				if agg.ipp.Bits() >= bits && ipp.Contains(agg.ipp.Addr()) {
-					l.push(agg)
+					all.push(agg)
 				}
 			}
 		}
 		if z.ipPrefix2net == nil {
-			z.ipPrefix2net = make(map[netip.Prefix]netList)
+			z.ipPrefix2net = make(map[zoneNetKey]netList)
+		}
+		z.ipPrefix2net[key] = all
+	}
+	zoneNetCache.Unlock()
+	var l netList
+	for _, net := range all {
+		if !inNetMap(net) {
+			l.push(net)
 		}
-		z.ipPrefix2net[ipp] = l
 	}
 	return nil, l
 }
@@ -187,15 +198,15 @@ func (c *spoc) checkSupernetInZone1(
 	} else {
 		supernet = rule.dst[0].(*network)
 	}
-	natMap := intf.natMap
-	natSuper := getNatNetwork(supernet, natMap)
+	natDom := intf.zone.natDomain
+	natSuper := getNatNetwork(supernet, natDom.natMap)
 	if natSuper.hidden {
 		return
 	}
 	ipp := natSuper.ipp
 	netMap := info.netMap
 	agg, networks :=
-		findZoneNetworks(z, supernet.isAggregate, ipp, natMap, netMap)
+		findZoneNetworks(z, supernet.isAggregate, ipp, natDom, netMap)
 
 	if agg == nil && networks == nil {
 		return
@@ -549,80 +560,90 @@ func (c *spoc) checkMissingSupernetRules(
 	rules ruleList, what string,
 	worker func(c *spoc, r *groupedRule, i, o *routerIntf, inf checkInfo)) {
 
-	for _, rule := range rules {
-		if rule.noCheckSupernetRules {
-			continue
-		}
-		var list []someObj
-		var oList []someObj
-		if what == "src" {
-			list = rule.src
-			oList = rule.dst
-		} else {
-			list = rule.dst
-			oList = rule.src
+	// Rules are checked independently of each other.
+	c.parallelChunks(len(rules), func(c *spoc, from, to int) {
+		for _, rule := range rules[from:to] {
+			c.checkMissingSupernetRule(rule, what, worker)
 		}
-		var supernets netList
-		for _, obj := range list {
-			if x, ok := obj.(*network); ok {
-				if x.hasOtherSubnet {
-					supernets.push(x)
-				}
+	})
+}
+
+func (c *spoc) checkMissingSupernetRule(
+	rule *groupedRule, what string,
+	worker func(c *spoc, r *groupedRule, i, o *routerIntf, inf checkInfo)) {
+
+	if rule.noCheckSupernetRules {
+		return
+	}
+	var list []someObj
+	var oList []someObj
+	if what == "src" {
+		list = rule.src
+		oList = rule.dst
+	} else {
+		list = rule.dst
+		oList = rule.src
+	}
+	var supernets netList
+	for _, obj := range list {
+		if x, ok := obj.(*network); ok {
+			if x.hasOtherSubnet {
+				supernets.push(x)
 			}
 		}
-		if supernets == nil {
-			continue
-		}
+	}
+	if supernets == nil {
+		return
+	}
 
-		// Build mapping for all src/dst networks and aggregates of
-		// current rule.
-		netMap := make(map[*network]bool)
-		for _, obj := range list {
-			if x, ok := obj.(*network); ok {
-				netMap[x] = true
-			}
+	// Build mapping for all src/dst networks and aggregates of
+	// current rule.
+	netMap := make(map[*network]bool)
+	for _, obj := range list {
+		if x, ok := obj.(*network); ok {
+			netMap[x] = true
+		}
+	}
+	info := checkInfo{netMap: netMap}
+
+	localClusterSeen := make(map[*zone]bool)
+	groupInfo := splitRuleGroup(oList)
+	checkRule := new(groupedRule)
+	checkRule.serviceRule = rule.serviceRule
+	for _, supernet := range supernets {
+		z1 := supernet.zone
+		cl := z1.managedLocalCluster
+		// Show warnings again for each supernet.
+		info.seen = make(map[*zone]bool)
+		if what == "src" {
+			checkRule.src = []someObj{supernet}
+			checkRule.srcPath = supernet.zone
+		} else {
+			checkRule.dst = []someObj{supernet}
+			checkRule.dstPath = supernet.zone
 		}
-		info := checkInfo{netMap: netMap}
-
-		localClusterSeen := make(map[*zone]bool)
-		groupInfo := splitRuleGroup(oList)
-		checkRule := new(groupedRule)
-		checkRule.serviceRule = rule.serviceRule
-		for _, supernet := range supernets {
-			z1 := supernet.zone
-			cl := z1.managedLocalCluster
-			// Show warnings again for each supernet.
-			info.seen = make(map[*zone]bool)
+		for _, gi := range groupInfo {
+			pathObj := gi.path.getZone()
+			if zoneEq(z1, pathObj) {
+				continue
+			}
 			if what == "src" {
-				checkRule.src = []someObj{supernet}
-				checkRule.srcPath = supernet.zone
+				checkRule.dstPath = gi.path
+				checkRule.dst = gi.group
 			} else {
-				checkRule.dst = []someObj{supernet}
-				checkRule.dstPath = supernet.zone
+				checkRule.srcPath = gi.path
+				checkRule.src = gi.group
 			}
-			for _, gi := range groupInfo {
-				pathObj := gi.path.getZone()
-				if zoneEq(z1, pathObj) {
-					continue
-				}
-				if what == "src" {
-					checkRule.dstPath = gi.path
-					checkRule.dst = gi.group
-				} else {
-					checkRule.srcPath = gi.path
-					checkRule.src = gi.group
-				}
 
-				if cl != nil && !localClusterSeen[cl.zones[0]] {
-					c.checkManagedLocalSupernets(
-						checkRule, what, supernet, gi, info, localClusterSeen)
-				}
-				c.pathWalk(checkRule,
-					func(r *groupedRule, i, o *routerIntf) {
-						worker(c, r, i, o, info)
-					},
-					"Router")
+			if cl != nil && !localClusterSeen[cl.zones[0]] {
+				c.checkManagedLocalSupernets(
+					checkRule, what, supernet, gi, info, localClusterSeen)
 			}
+			c.pathWalk(checkRule,
+				func(r *groupedRule, i, o *routerIntf) {
+					worker(c, r, i, o, info)
+				},
+				"Router")
 		}
 	}
 }
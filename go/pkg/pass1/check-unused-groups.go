@@ -6,11 +6,13 @@ func (c *spoc) checkUnused() {
 			for _, group := range c.symTable.group {
 				if !group.isUsed {
 					c.warnOrErr(printType, "unused %s", group)
+					c.addFix(group.name)
 				}
 			}
 			for _, group := range c.symTable.protocolgroup {
 				if !group.isUsed {
 					c.warnOrErr(printType, "unused %s", group.name)
+					c.addFix(group.name)
 				}
 			}
 		}
@@ -263,8 +263,9 @@ func (c *spoc) addRouterAcls() {
 						netList := []someObj{intf.network}
 
 						// Permit multicast packets from current network.
-						mcast := getMulticastObjects(routing, ipv6)
-						hw.intfRules.push(newRule(netList, mcast, prtList))
+						if mcast := getMulticastObjects(routing, ipv6); len(mcast) > 0 {
+							hw.intfRules.push(newRule(netList, mcast, prtList))
+						}
 
 						// Additionally permit unicast packets.
 						// We use the network address as destination
@@ -272,6 +273,15 @@ func (c *spoc) addRouterAcls() {
 						// because we get fewer rules if the interface has
 						// multiple addresses.
 						hw.intfRules.push(newRule(netList, netList, prtList))
+
+						// BGP uses TCP. Stateless ACL needs additional
+						// rule for answer of peer.
+						if routing.name == "BGP" {
+							bgp := c.prt.BGP
+							rule := newRule(netList, netList, []*proto{bgp.main})
+							rule.srcRange = bgp.modifiers.srcRange
+							hw.intfRules.push(rule)
+						}
 					}
 				}
 
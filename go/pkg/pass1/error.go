@@ -11,7 +11,7 @@ func debug(f string, l ...interface{}) { fmt.Fprintf(os.Stderr, f+"\n", l...) }
 func (l stringerList[E]) nameList() string {
 	var names stringList
 	for _, x := range l {
-		names.push((*x).String())
+		names.push(nameOf(x))
 	}
 	return names.nameList()
 }
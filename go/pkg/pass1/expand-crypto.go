@@ -348,8 +348,11 @@ func (c *spoc) expandCrypto() {
 			}
 
 			if managed != "" {
-				if router.model.crypto == "ASA" {
+				switch router.model.crypto {
+				case "ASA":
 					c.verifyAsaTrustpoint(router, cr)
+				case "Linux":
+					c.verifyLinuxIpsec(router, cr)
 				}
 				if cr.detailedCryptoAcl {
 					c.err("Attribute 'detailed_crypto_acl' is not"+
@@ -433,6 +436,12 @@ func (c *spoc) expandCrypto() {
 					c.verifyAsaTrustpoint(r, intf.getCrypto())
 				}
 			}
+		case "Linux":
+			for _, intf := range r.interfaces {
+				if intf.ipType == tunnelIP {
+					c.verifyLinuxIpsec(r, intf.getCrypto())
+				}
+			}
 		}
 	}
 
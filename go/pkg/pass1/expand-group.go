@@ -136,22 +136,26 @@ func (c *spoc) getNetworkAutoIntf(n *network, managed bool) *autoIntf {
 }
 
 // Remove duplicate elements in place and warn about them.
-func (c *spoc) removeDuplicates(list groupObjList, ctx string) groupObjList {
-	seen := make(map[groupObj]bool)
+// Elements l and path are used to fix duplicates in source files.
+func (c *spoc) removeDuplicates(
+	list groupObjList, l []ast.Element, ctx, path string) groupObjList {
+
+	seen := make(map[groupObj]int)
 	var duplicates stringList
 	j := 0
 	for _, obj := range list {
-		if seen[obj] {
+		if seen[obj] != 0 {
 			duplicates.push(obj.String())
 		} else {
-			seen[obj] = true
 			list[j] = obj
 			j++
 		}
+		seen[obj]++
 	}
 	list = list[:j]
 	if duplicates != nil {
 		c.warn("Duplicate elements in %s:\n"+duplicates.nameList(), ctx)
+		c.fixDuplicates(path, l, seen)
 	}
 	return list
 }
@@ -720,7 +724,8 @@ func (c *spoc) expandGroupObj(
 		grp.recursive = false
 
 		// Detect and remove duplicate values in group.
-		elements = c.removeDuplicates(elements, grp.name)
+		elements = c.removeDuplicates(
+			elements, grp.elements, grp.name, grp.name+",elements")
 	}
 
 	// Cache result for further references to the same group
@@ -737,11 +742,14 @@ func (c *spoc) expandGroupObj(
 //     Crosslink networks are no longer suppressed.
 //  2. interface:[..].[all]:
 //     Unnumbered and bridged interfaces are no longer suppressed.
+//
+// Parameter path is used to fix duplicate elements.
+// It is the path of l in Netspoc-API or "" if not applicable.
 func (c *spoc) expandGroup(
-	l []ast.Element, ctx string, showAll bool) groupObjList {
+	l []ast.Element, ctx, path string, showAll bool) groupObjList {
 
 	result := c.expandGroup1(l, ctx, !showAll, showAll)
-	return c.removeDuplicates(result, ctx)
+	return c.removeDuplicates(result, l, ctx, path)
 }
 
 func (c *spoc) expandUser(sv *service) groupObjList {
@@ -752,13 +760,15 @@ func (c *spoc) expandUser(sv *service) groupObjList {
 			c.warnOrErr(errType, ctx+" is empty")
 		}
 	} else {
-		user = c.expandGroup(sv.user, ctx, false)
+		user = c.expandGroup(sv.user, ctx, sv.name+",user", false)
 	}
 	return user
 }
 
-func (c *spoc) expandGroupInRule(l []ast.Element, ctx string) groupObjList {
-	list := c.expandGroup(l, ctx, false)
+func (c *spoc) expandGroupInRule(
+	l []ast.Element, ctx, path string) groupObjList {
+
+	list := c.expandGroup(l, ctx, path, false)
 
 	// Ignore unusable objects.
 	j := 0
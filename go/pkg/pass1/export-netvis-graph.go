@@ -0,0 +1,348 @@
+package pass1
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// getVisFilter returns a function, that tells if object with given
+// name is exported. Export may be limited to objects inside an area
+// and to objects on path between two objects.
+func (c *spoc) getVisFilter(
+	area string, pathParams []string) func(string) bool {
+
+	var inPath map[string]bool
+	if pathParams != nil {
+		c.setPath()
+		inPath = c.markPathObjects(pathParams)
+	}
+	var inArea map[string]bool
+	if area != "" {
+		a := c.symTable.area[strings.TrimPrefix(area, "area:")]
+		if a == nil {
+			c.abort("Unknown area:%s", strings.TrimPrefix(area, "area:"))
+		}
+		isInside := func(z *zone) bool {
+			for a2 := z.inArea; a2 != nil; a2 = a2.inArea {
+				if a2 == a || a2 == a.combined46 {
+					return true
+				}
+			}
+			return false
+		}
+		inArea = make(map[string]bool)
+		for _, n := range c.allNetworks {
+			if isInside(n.zone) {
+				inArea[n.name] = true
+				for _, intf := range n.interfaces {
+					inArea[getVisOrigRouter(intf.router).name] = true
+				}
+			}
+		}
+	}
+	return func(name string) bool {
+		return (inPath == nil || inPath[name]) &&
+			(inArea == nil || inArea[name])
+	}
+}
+
+func getVisOrigRouter(r *router) *router {
+	if r.origRouter != nil {
+		return r.origRouter
+	}
+	return r
+}
+
+// visNode is a node of exported graph.
+// Zones and areas are nodes, that contain other nodes as children.
+type visNode struct {
+	id        string
+	typ       string
+	address   string
+	model     string
+	crosslink bool
+	parent    *visNode
+	children  []*visNode
+}
+
+type visEdge struct {
+	from     string
+	to       string
+	isTunnel bool
+}
+
+type visGraph struct {
+	nodes map[string]*visNode
+	edges []visEdge
+}
+
+func (c *spoc) getVisGraph(keep func(string) bool) *visGraph {
+	g := &visGraph{nodes: make(map[string]*visNode)}
+
+	var getArea func(a *area) *visNode
+	getArea = func(a *area) *visNode {
+		if a == nil {
+			return nil
+		}
+		node := g.nodes[a.name]
+		if node == nil {
+			node = &visNode{id: a.name, typ: "area"}
+			g.nodes[a.name] = node
+			node.parent = getArea(a.inArea)
+		}
+		return node
+	}
+	getZone := func(z *zone) *visNode {
+		node := g.nodes[z.name]
+		if node == nil {
+			node = &visNode{id: z.name, typ: "zone"}
+			g.nodes[z.name] = node
+			node.parent = getArea(z.inArea)
+		}
+		return node
+	}
+
+	// Add networks.
+	for _, n := range c.allNetworks {
+		if n.ipType == tunnelIP || !keep(n.name) {
+			continue
+		}
+		node := g.nodes[n.name]
+		if node == nil {
+			node = &visNode{id: n.name, typ: "network"}
+			g.nodes[n.name] = node
+			node.parent = getZone(n.zone)
+		}
+		if n.ipType != unnumberedIP {
+			if node.address == "" {
+				node.address = n.ipp.String()
+			} else {
+				node.address += " " + n.ipp.String()
+			}
+		}
+		node.crosslink = n.crosslink
+	}
+
+	// Add routers and edges to networks and tunnel peers.
+	seen := make(map[visEdge]bool)
+	addEdge := func(e visEdge) {
+		if e.from > e.to {
+			e.from, e.to = e.to, e.from
+		}
+		if !seen[e] {
+			seen[e] = true
+			g.edges = append(g.edges, e)
+		}
+	}
+	for _, r := range c.symTable.router {
+		if !keep(r.name) {
+			continue
+		}
+		node := &visNode{id: r.name, typ: getVisRouterType(r)}
+		if r.managed != "" {
+			node.model = r.model.class
+		}
+		g.nodes[r.name] = node
+		getIntfs := func(r *router) intfList {
+			if r.origIntfs != nil {
+				return r.origIntfs
+			}
+			return r.interfaces
+		}
+		intfs := getIntfs(r)
+		if r.isCombined46() {
+			intfs = append(slices.Clone(intfs), getIntfs(r.combined46)...)
+		}
+		// Router is placed into zone, if all its interfaces are
+		// located in this zone. Otherwise it is placed into the
+		// innermost area, that contains all its interfaces.
+		var zoneName string
+		var routerArea *area
+		isFirst := true
+		for _, intf := range intfs {
+			n := intf.network
+			if n.ipType == tunnelIP {
+				for _, intf2 := range n.interfaces {
+					r2 := getVisOrigRouter(intf2.router)
+					if r2 != r && keep(r2.name) {
+						addEdge(visEdge{from: r.name, to: r2.name, isTunnel: true})
+					}
+				}
+				continue
+			}
+			if isFirst {
+				zoneName = n.zone.name
+				routerArea = n.zone.inArea
+				isFirst = false
+			} else {
+				if n.zone.name != zoneName {
+					zoneName = ""
+				}
+				if a := n.zone.inArea; a == nil ||
+					routerArea == nil || a.name != routerArea.name {
+					routerArea = nil
+				}
+			}
+			if keep(n.name) {
+				addEdge(visEdge{from: r.name, to: n.name})
+			}
+		}
+		if z := g.nodes[zoneName]; z != nil && r.managed == "" &&
+			!r.routingOnly {
+			node.parent = z
+		} else if routerArea != nil {
+			node.parent = getArea(routerArea)
+		}
+	}
+
+	// Link children to parents.
+	for _, node := range g.nodes {
+		if p := node.parent; p != nil {
+			p.children = append(p.children, node)
+		}
+	}
+	for _, node := range g.nodes {
+		slices.SortFunc(node.children, func(a, b *visNode) int {
+			return cmp.Compare(a.id, b.id)
+		})
+	}
+	slices.SortFunc(g.edges, func(a, b visEdge) int {
+		return cmp.Or(cmp.Compare(a.from, b.from), cmp.Compare(a.to, b.to))
+	})
+	return g
+}
+
+// topNodes returns nodes without parent, sorted by name.
+func (g *visGraph) topNodes() []*visNode {
+	var result []*visNode
+	for _, node := range g.nodes {
+		if node.parent == nil {
+			result = append(result, node)
+		}
+	}
+	slices.SortFunc(result, func(a, b *visNode) int {
+		return cmp.Compare(a.id, b.id)
+	})
+	return result
+}
+
+// Fill color of managed routers, taken from model.
+var visModelColor = map[string]string{
+	"ASA":    "lightcoral",
+	"IOS":    "lightblue",
+	"Linux":  "palegreen",
+	"NSX":    "plum",
+	"PAN-OS": "orange",
+}
+
+func (g *visGraph) printDot(w io.Writer) {
+	fmt.Fprintln(w, "graph netspoc {")
+	var printNode func(node *visNode, indent string)
+	printNode = func(node *visNode, indent string) {
+		switch node.typ {
+		case "zone", "area":
+			fmt.Fprintf(w, "%ssubgraph %q {\n", indent, "cluster_"+node.id)
+			in := indent + " "
+			fmt.Fprintf(w, "%slabel=%q;\n", in, node.id)
+			if node.typ == "zone" {
+				fmt.Fprintf(w, "%sstyle=dotted;\n", in)
+			}
+			for _, child := range node.children {
+				printNode(child, in)
+			}
+			fmt.Fprintf(w, "%s}\n", indent)
+			return
+		case "network":
+			label := node.id
+			if node.address != "" {
+				label += "\n" + node.address
+			}
+			attr := fmt.Sprintf("shape=ellipse, label=%q", label)
+			if node.crosslink {
+				attr += ", style=dashed, xlabel=\"crosslink\""
+			}
+			fmt.Fprintf(w, "%s%q [%s];\n", indent, node.id, attr)
+		default:
+			attr := "shape=box"
+			if node.model != "" {
+				color := cmp.Or(visModelColor[node.model], "lightgray")
+				attr += fmt.Sprintf(", label=%q, style=filled, fillcolor=%s",
+					node.id+"\n"+node.model, color)
+			} else if node.typ != "router" {
+				attr += ", style=dashed"
+			}
+			fmt.Fprintf(w, "%s%q [%s];\n", indent, node.id, attr)
+		}
+	}
+	for _, node := range g.topNodes() {
+		printNode(node, " ")
+	}
+	for _, e := range g.edges {
+		attr := ""
+		if e.isTunnel {
+			attr = " [style=dashed, label=\"tunnel\"]"
+		}
+		fmt.Fprintf(w, " %q -- %q%s;\n", e.from, e.to, attr)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (g *visGraph) printGraphML(w io.Writer) {
+	esc := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+ <key id="type" for="node" attr.name="type" attr.type="string"/>
+ <key id="address" for="node" attr.name="address" attr.type="string"/>
+ <key id="model" for="node" attr.name="model" attr.type="string"/>
+ <key id="crosslink" for="node" attr.name="crosslink" attr.type="boolean"/>
+ <key id="is_tunnel" for="edge" attr.name="is_tunnel" attr.type="boolean"/>
+ <graph id="netspoc" edgedefault="undirected">
+`)
+	var printNode func(node *visNode, indent string)
+	printNode = func(node *visNode, indent string) {
+		in := indent + " "
+		fmt.Fprintf(w, "%s<node id=\"%s\">\n", indent, esc(node.id))
+		fmt.Fprintf(w, "%s<data key=\"type\">%s</data>\n", in, esc(node.typ))
+		if node.address != "" {
+			fmt.Fprintf(w, "%s<data key=\"address\">%s</data>\n",
+				in, esc(node.address))
+		}
+		if node.model != "" {
+			fmt.Fprintf(w, "%s<data key=\"model\">%s</data>\n",
+				in, esc(node.model))
+		}
+		if node.crosslink {
+			fmt.Fprintf(w, "%s<data key=\"crosslink\">true</data>\n", in)
+		}
+		if node.children != nil {
+			fmt.Fprintf(w, "%s<graph id=\"%s:\" edgedefault=\"undirected\">\n",
+				in, esc(node.id))
+			for _, child := range node.children {
+				printNode(child, in+" ")
+			}
+			fmt.Fprintf(w, "%s</graph>\n", in)
+		}
+		fmt.Fprintf(w, "%s</node>\n", indent)
+	}
+	for _, node := range g.topNodes() {
+		printNode(node, "  ")
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(w, "  <edge source=\"%s\" target=\"%s\"",
+			esc(e.from), esc(e.to))
+		if e.isTunnel {
+			fmt.Fprintf(w, ">\n   <data key=\"is_tunnel\">true</data>\n  </edge>\n")
+		} else {
+			fmt.Fprintln(w, "/>")
+		}
+	}
+	fmt.Fprint(w, " </graph>\n</graphml>\n")
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 
 	"github.com/hknutzen/Netspoc/go/pkg/conf"
 	"github.com/hknutzen/Netspoc/go/pkg/oslink"
@@ -23,6 +25,11 @@ func ExportNetvisMain(d oslink.Data) int {
 
 	// Command line flags
 	quiet := fs.BoolP("quiet", "q", false, "Don't print progress messages")
+	format := fs.StringP("format", "f", "json",
+		"Output format: json, dot or graphml")
+	area := fs.StringP("area", "a", "", "Export only objects of this area")
+	from := fs.String("from", "", "Export only path starting at this object")
+	to := fs.String("to", "", "Export only path ending at this object")
 	if err := fs.Parse(d.Args[1:]); err != nil {
 		if errors.Is(err, pflag.ErrHelp) {
 			return 1
@@ -39,12 +46,29 @@ func ExportNetvisMain(d oslink.Data) int {
 		return 1
 	}
 	path := args[0]
+	switch *format {
+	case "json", "dot", "graphml":
+	default:
+		fmt.Fprintf(d.Stderr, "Error: Unknown format '%s'\n", *format)
+		fs.Usage()
+		return 1
+	}
+	var pathParams []string
+	if *from != "" || *to != "" {
+		if *from == "" || *to == "" {
+			fmt.Fprintf(d.Stderr,
+				"Error: Options --from and --to must be used together\n")
+			fs.Usage()
+			return 1
+		}
+		pathParams = []string{*from, *to}
+	}
 
 	cnf := conf.ConfigFromFile(path)
 	cnf.Quiet = *quiet
 
 	return toplevelSpoc(d, cnf, func(c *spoc) {
-		c.exportNetvis(d.Stdout, path)
+		c.exportNetvis(d.Stdout, path, *format, *area, pathParams)
 	})
 }
 
@@ -69,17 +93,36 @@ type visNetwork struct {
 
 type visRouter = visBase
 
-func (c *spoc) exportNetvis(stdout io.Writer, path string) {
+func (c *spoc) exportNetvis(
+	stdout io.Writer, path, format, area string, pathParams []string) {
+
 	c.readNetspoc(path)
 	c.setZone()
+	keep := c.getVisFilter(area, pathParams)
+	c.stopOnErr()
+	switch format {
+	case "dot":
+		c.getVisGraph(keep).printDot(stdout)
+		return
+	case "graphml":
+		c.getVisGraph(keep).printGraphML(stdout)
+		return
+	}
 	networks := make(map[string]visNetwork)
 	routers := make(map[string]visRouter)
 
 	for _, n := range c.symTable.network {
-		getVisNetwork(n, networks)
+		if keep(n.name) {
+			getVisNetwork(n, networks)
+		}
 	}
 	for _, r := range c.symTable.router {
-		getVisRouter(r, routers)
+		if keep(r.name) {
+			getVisRouter(r, routers)
+		}
+	}
+	if area != "" || pathParams != nil {
+		removeVisNeighbors(networks, routers)
 	}
 	data := struct {
 		Network map[string]visNetwork `json:"network"`
@@ -92,6 +135,28 @@ func (c *spoc) exportNetvis(stdout io.Writer, path string) {
 
 }
 
+// removeVisNeighbors removes references to networks and routers
+// that are not part of exported data.
+func removeVisNeighbors(
+	networks map[string]visNetwork, routers map[string]visRouter) {
+
+	isKnown := func(id string) bool {
+		_, found1 := networks[id]
+		_, found2 := routers[id]
+		return found1 || found2 || strings.HasPrefix(id, "area:")
+	}
+	for id, node := range networks {
+		node.Neighbors = slices.DeleteFunc(node.Neighbors,
+			func(n visNeighbor) bool { return !isKnown(n.Id) })
+		networks[id] = node
+	}
+	for id, node := range routers {
+		node.Neighbors = slices.DeleteFunc(node.Neighbors,
+			func(n visNeighbor) bool { return !isKnown(n.Id) })
+		routers[id] = node
+	}
+}
+
 func getVisNetwork(net *network, networks map[string]visNetwork) {
 	var node visNetwork
 	node.Id = net.name
@@ -124,6 +189,18 @@ func getVisNetwork(net *network, networks map[string]visNetwork) {
 	networks[net.name] = node
 }
 
+func getVisRouterType(r *router) string {
+	typ := "router"
+	if r.managed == "" {
+		if r.routingOnly {
+			typ += ": routing_only"
+		}
+	} else {
+		typ += ": " + r.managed
+	}
+	return typ
+}
+
 func getVisRouter(r *router, routers map[string]visRouter) {
 	var node visRouter
 	node.Id = r.name
@@ -139,17 +216,7 @@ func getVisRouter(r *router, routers map[string]visRouter) {
 	intfs = getIntfs(r)
 
 	routerArea := intfs[0].network.zone.inArea
-
-	var typ = "router"
-	if r.managed == "" {
-		if r.routingOnly {
-			typ += ": routing_only"
-		}
-	} else {
-		typ += ": " + r.managed
-	}
-
-	node.Type = typ
+	node.Type = getVisRouterType(r)
 
 	seen := make(map[string]bool)
 
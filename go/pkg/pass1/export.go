@@ -341,6 +341,7 @@ type exportedSvc struct {
 	description  string
 	disableAt    string
 	disabled     bool
+	enableAt     string
 	user         srvObjList
 	objMap       map[srvObj]bool
 	jsonRules    []jsonMap
@@ -525,6 +526,7 @@ func (c *spoc) normalizeServicesForExport() []*exportedSvc {
 				description: sv.description,
 				disableAt:   sv.disableAt,
 				disabled:    sv.disabled,
+				enableAt:    sv.enableAt,
 				user:        userList,
 				objMap:      objMap,
 				jsonRules:   jsonRules,
@@ -1150,6 +1152,7 @@ func (c *spoc) exportServices(dir string, list []*exportedSvc) {
 		}
 		add("description", s.description)
 		add("disable_at", s.disableAt)
+		add("enable_at", s.enableAt)
 		if s.disabled {
 			details["disabled"] = 1
 		}
@@ -755,13 +755,16 @@ func (c *spoc) findSubnetsInNatDomain(domains []*natDomain) {
 
 	// Sorts error messages before output.
 	c.sortedSpoc(func(c *spoc) {
-		for _, part := range slices.Sorted(maps.Keys(part2Doms)) {
-			domains := part2Doms[part]
-			networks := part2Nets[part]
+		// NAT partitions are independent of each other
+		// and hence are analyzed concurrently.
+		parts := slices.Sorted(maps.Keys(part2Doms))
+		c.parallel(len(parts), func(c *spoc, i int) {
+			domains := part2Doms[parts[i]]
+			networks := part2Nets[parts[i]]
 			findUnstableNat(domains, networks)
 			c.findSubnetsInNatDomain0(domains, networks)
 			setMaxSecondaryNet(networks)
-		}
+		})
 	})
 	c.findUselessSubnetAttr()
 }
@@ -20,44 +20,60 @@ type attrKey int
 type attrVal byte
 type attrStore [maxAttr]attrVal
 
-func getAttrFromArea(k attrKey, obj *area) attrVal {
+// Inherited values are cached at smaller areas and networks,
+// if cache is set.
+func getAttrFromArea(k attrKey, obj *area, cache bool) attrVal {
 	if v := obj.attr[k]; v != unsetVal {
 		return v
 	}
 	if a := obj.inArea; a != nil {
-		v := getAttrFromArea(k, a)
-		obj.attr[k] = v // Cache inherited value at smaller area.
+		v := getAttrFromArea(k, a, cache)
+		if cache {
+			obj.attr[k] = v // Cache inherited value at smaller area.
+		}
 		return v
 	}
 	return enableVal
 }
 
-func getAttrFromZone(k attrKey, obj *zone) attrVal {
+func getAttrFromZone(k attrKey, obj *zone, cache bool) attrVal {
 	if a := obj.inArea; a != nil {
-		return getAttrFromArea(k, a)
+		return getAttrFromArea(k, a, cache)
 	}
 	return enableVal
 }
 
-func getAttrFromNetwork(k attrKey, obj *network) attrVal {
+func getAttrFromNetwork(k attrKey, obj *network, cache bool) attrVal {
 	if v := obj.attr[k]; v != unsetVal {
 		return v
 	}
+	var v attrVal
 	if up := obj.up; up != nil {
-		v := getAttrFromNetwork(k, up)
+		v = getAttrFromNetwork(k, up, cache)
+	} else {
+		v = getAttrFromZone(k, obj.zone, cache)
+	}
+	if cache {
 		obj.attr[k] = v // Cache inherited value at smaller network.
-		return v
 	}
-	v := getAttrFromZone(k, obj.zone)
-	obj.attr[k] = v
 	return v
 }
 
-func getAttr(obj withAttr, k attrKey) attrVal {
+func getAttrFromObj(obj withAttr, k attrKey, cache bool) attrVal {
 	if o := obj.getOwner(); o != nil {
 		if v := o.attr[k]; v != unsetVal {
 			return v
 		}
 	}
-	return getAttrFromNetwork(k, obj.getNetwork())
+	return getAttrFromNetwork(k, obj.getNetwork(), cache)
+}
+
+func getAttr(obj withAttr, k attrKey) attrVal {
+	return getAttrFromObj(obj, k, true)
+}
+
+// Get attribute like getAttr, but don't cache inherited values.
+// This is used by concurrent jobs.
+func lookupAttr(obj withAttr, k attrKey) attrVal {
+	return getAttrFromObj(obj, k, false)
 }
@@ -222,9 +222,10 @@ func (c *spoc) normalizeSrcDstList(
 	r *unexpRule, l groupObjList, s *service) ([][2]srvObjList, bool) {
 
 	ctx := s.name
+	path := fmt.Sprintf("%s,rules,%d,", ctx, slices.Index(s.rules, r)+1)
 	c.userObj.elements = l
-	srcList := c.expandGroupInRule(r.src, "src of rule in "+ctx)
-	dstList := c.expandGroupInRule(r.dst, "dst of rule in "+ctx)
+	srcList := c.expandGroupInRule(r.src, "src of rule in "+ctx, path+"src")
+	dstList := c.expandGroupInRule(r.dst, "dst of rule in "+ctx, path+"dst")
 	c.userObj.elements = nil
 
 	srcList4, srcList6, srcHas46 := c.splitCombined46(srcList, s)
@@ -9,6 +9,7 @@ import (
 
 type stdProto struct {
 	Ah      *proto
+	BGP     *proto
 	Bootpc  *proto
 	Bootps  *proto
 	Esp     *proto
@@ -49,6 +50,8 @@ func (c *spoc) initStdProtocols() {
 
 	prt.Bootps = define("udp 67")
 	prt.Bootpc = define("udp 68")
+	// Answer of BGP peer.
+	prt.BGP = defineX("tcp 179:1-65535")
 }
 
 // Order protocols. We need this to simplify optimization.
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 )
 
 // getPathNode provides path node objects for objects specified as src or dst.
@@ -845,6 +846,34 @@ func (c *spoc) pathWalk(
 	rule *groupedRule,
 	fun func(r *groupedRule, i, o *routerIntf),
 	where string,
+) {
+	if !c.concurrent {
+		c.pathWalk0(rule, fun, where)
+		return
+	}
+	// Path is marked lazily at shared objects.
+	// Hence concurrent jobs must not walk at the same time.
+	// Given function is called after lock has been released.
+	var pairs intfPairs
+	func() {
+		pathLock.Lock()
+		defer pathLock.Unlock()
+		c.pathWalk0(rule, func(_ *groupedRule, i, o *routerIntf) {
+			pairs.push(intfPair{i, o})
+		}, where)
+	}()
+	for _, p := range pairs {
+		fun(rule, p[0], p[1])
+	}
+}
+
+// Protects path marks of pathWalk, if called from concurrent jobs.
+var pathLock sync.Mutex
+
+func (c *spoc) pathWalk0(
+	rule *groupedRule,
+	fun func(r *groupedRule, i, o *routerIntf),
+	where string,
 ) {
 	atZone := where == "Zone"
 
@@ -1,7 +1,10 @@
 package pass1
 
 import (
+	"bufio"
+	"bytes"
 	"cmp"
+	"encoding/json"
 	"fmt"
 	"maps"
 	"net"
@@ -198,6 +201,18 @@ func (c *spoc) printRoutes(fh *os.File, r *router) {
 		}
 	}
 
+	if c.conf.AggregateRoutes {
+		var connected []netip.Prefix
+		for _, intf := range r.interfaces {
+			connected = append(connected, intf.network.address(intf.natMap))
+		}
+		aggregateRoutes(prefix2ip2net, net2hop, connected,
+			func(hop *routerIntf) bool {
+				// Don't combine peers of ASA with site-to-site VPN.
+				return asaCrypto && hop2intf[hop].hub != nil
+			})
+	}
+
 	// Find and remove duplicate and redundant routes.
 	// Go from small to larger networks.
 	prefixes := slices.SortedFunc(maps.Keys(prefix2ip2net), func(a, b int) int {
@@ -302,6 +317,21 @@ func (c *spoc) printRoutes(fh *os.File, r *router) {
 	if vrf != "" && model.routing == "IOS" {
 		iosVrf = "vrf " + vrf + " "
 	}
+	linuxVrf := ""
+	if vrf != "" {
+		linuxVrf = " vrf " + vrf
+	}
+	switch model.routing {
+	case "FRR":
+		// Static routes are configured in staticd of FRRouting.
+		fmt.Fprintln(fh, "vtysh <<EOF")
+		fmt.Fprintln(fh, "configure terminal")
+		if vrf != "" {
+			fmt.Fprintln(fh, "vrf", vrf)
+		}
+	case "ip-batch":
+		fmt.Fprintln(fh, "ip -batch - <<EOF")
+	}
 
 	for _, hop := range hops {
 		intf := hop2intf[hop]
@@ -340,10 +370,33 @@ func (c *spoc) printRoutes(fh *os.File, r *router) {
 			case "iproute":
 				adr := prefixCode(netinfo.Prefix)
 				fmt.Fprintln(fh, "ip route add", adr, "via", hopAddr)
-
+			case "ip-batch":
+				adr := prefixCode(netinfo.Prefix)
+				fmt.Fprintln(fh, "route add", adr, "via", hopAddr+linuxVrf)
+			case "FRR":
+				ip := "ip"
+				if ipv6 {
+					ip += "v6"
+				}
+				indent := ""
+				if vrf != "" {
+					indent = " "
+				}
+				adr := prefixCode(netinfo.Prefix)
+				fmt.Fprintln(fh, indent+ip, "route", adr, hopAddr)
 			}
 		}
 	}
+	switch model.routing {
+	case "FRR":
+		if vrf != "" {
+			fmt.Fprintln(fh, "exit-vrf")
+		}
+		fmt.Fprintln(fh, "end")
+		fmt.Fprintln(fh, "EOF")
+	case "ip-batch":
+		fmt.Fprintln(fh, "EOF")
+	}
 }
 
 func printAclPlaceholder(fh *os.File, r *router, aclName string) {
@@ -1035,8 +1088,10 @@ func collectAclsFromIORules(r *router) {
 }
 
 func printIptablesAcls(fh *os.File, r *router) {
+	printSwanFilterAcls(fh, r)
+	n := len(r.aclList)
 	collectAclsFromIORules(r)
-	for _, acl := range r.aclList {
+	for _, acl := range r.aclList[n:] {
 		acl.addDeny = true
 		name := acl.name
 		inHw, outHw, _ := strings.Cut(name, "_")
@@ -1225,14 +1280,13 @@ func (c *spoc) genCryptoRules(local, remote []*network) []*groupedRule {
 	)}
 }
 
-// Print crypto ACL.
-// It controls which traffic needs to be encrypted.
-func (c *spoc) printCryptoAcl(fh *os.File, intf *routerIntf, suffix string, crypto *crypto) string {
-	cryptoAclName := "crypto-" + suffix
+// Find local and remote networks of traffic, that needs to be
+// encrypted at tunnel interface:
+// - either generic from remote networks to any or
+// - detailed to all networks which are used in rules.
+func (c *spoc) getCryptoNetworks(
+	intf *routerIntf, crypto *crypto) (local, remote []*network) {
 
-	// Generate crypto ACL entries.
-	// - either generic from remote networks to any or
-	// - detailed to all networks which are used in rules.
 	isHub := intf.isHub
 	var hub *routerIntf
 	if isHub {
@@ -1240,18 +1294,24 @@ func (c *spoc) printCryptoAcl(fh *os.File, intf *routerIntf, suffix string, cryp
 	} else {
 		hub = intf.peer
 	}
-	r := intf.router
-	var local []*network
 	if crypto.detailedCryptoAcl {
 		local = getSplitTunnelNets(hub)
 	} else {
-		local = []*network{c.getNetwork00(r.ipV6)}
+		local = []*network{c.getNetwork00(intf.router.ipV6)}
 	}
-	remote := hub.peerNetworks
+	remote = hub.peerNetworks
 	if !isHub {
 		local, remote = remote, local
 	}
-	cryptoRules := c.genCryptoRules(local, remote)
+	return
+}
+
+// Print crypto ACL.
+// It controls which traffic needs to be encrypted.
+func (c *spoc) printCryptoAcl(fh *os.File, intf *routerIntf, suffix string, crypto *crypto) string {
+	cryptoAclName := "crypto-" + suffix
+	r := intf.router
+	cryptoRules := c.genCryptoRules(c.getCryptoNetworks(intf, crypto))
 	aclInfo := &aclInfo{
 		name:        cryptoAclName,
 		rules:       cryptoRules,
@@ -1527,6 +1587,10 @@ func (c *spoc) printCrypto(fh *os.File, r *router) {
 		c.printAsavpn(fh, r)
 		return
 	}
+	if cryptoType == "Linux" {
+		c.printSwanctl(fh, r)
+		return
+	}
 
 	isakmpCount := 0
 	for _, isakmp := range isakmpList {
@@ -1672,7 +1736,7 @@ func (c *spoc) printCrypto(fh *os.File, r *router) {
 	}
 }
 
-func printRouterIntf(fh *os.File, r *router) {
+func (c *spoc) printRouterIntf(fh *os.File, r *router) {
 	model := r.model
 	if !model.printRouterIntf {
 		return
@@ -1730,6 +1794,9 @@ func printRouterIntf(fh *os.File, r *router) {
 			subcmd.push("ip inspect X in")
 		}
 
+		if c.conf.GenerateRedundancy {
+			subcmd = append(subcmd, iosRedundancyCmds(hw, ipv6)...)
+		}
 		subcmd = append(subcmd, hw.subcmd...)
 
 		fmt.Fprintln(fh, "interface "+name)
@@ -1858,6 +1925,19 @@ func (c *spoc) setupStdAddr() {
 	}
 }
 
+// Get sorted names of services, that contributed to rule.
+func getServiceNames(rule *groupedRule) []string {
+	var names stringList
+	if rule.rule != nil {
+		names.push(rule.rule.service.name)
+	}
+	for _, sv := range rule.dupServices {
+		names.push(sv.name)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
 // Optimization: Use precomputed stdAddr.
 func getAddr(o someObj, natMap natMap) string {
 	switch x := o.(type) {
@@ -1885,8 +1965,42 @@ func getAddrList(l []someObj, natMap natMap) []string {
 	return result
 }
 
-func (c *spoc) printAcls(path string, vrfMembers []*router) {
-	var aclList []*jcode.ACLInfo
+// Print ACLs of vrfMembers as jcode.RouterData.
+// Fields are written explicitly in order of declaration in
+// jcode.RouterData. Each ACL is encoded and written as soon as it
+// has been processed, hence only a single ACL is held in memory.
+// Returns number of rules in ACLs.
+func (c *spoc) printAcls(path string, vrfMembers []*router) int {
+	fd, err := os.Create(path)
+	if err != nil {
+		c.abort("Can't %v", err)
+	}
+	defer fd.Close()
+	w := bufio.NewWriter(fd)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	write := func(v any) {
+		buf.Reset()
+		if err := enc.Encode(v); err != nil {
+			c.abort("Can't encode %s: %v", path, err)
+		}
+		w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	}
+	writeKey := func(sep, key string) {
+		w.WriteString(sep)
+		write(key)
+		w.WriteString(":")
+	}
+	r0 := vrfMembers[0]
+	model := r0.model
+	writeKey("{", "model")
+	write(model.class)
+	writeKey(",", "acls")
+
+	// Like json.Encoder, write "null" if no ACL is found.
+	sep := "["
+	count := 0
 	for _, r := range vrfMembers {
 		managed := r.managed
 		secondaryFilter := managed == "secondary"
@@ -2071,6 +2185,9 @@ func (c *spoc) printAcls(path string, vrfMembers []*router) {
 					if srcRange := rule.srcRange; srcRange != nil {
 						newRule.SrcRange = srcRange.name
 					}
+					if c.conf.ServiceMap {
+						newRule.Services = getServiceNames(rule)
+					}
 				}
 				return jRules
 			}
@@ -2132,18 +2249,30 @@ func (c *spoc) printAcls(path string, vrfMembers []*router) {
 				result.NoOptAddrs =
 					append(result.NoOptAddrs, subResult.NoOptAddrs...)
 			}
-			aclList = append(aclList, result)
+			w.WriteString(sep)
+			write(result)
+			sep = ","
+			count += len(result.Rules) + len(result.IntfRules)
 		}
 	}
-
-	r := vrfMembers[0]
-	model := r.model
-	result := &jcode.RouterData{
-		Model:         model.class,
-		ACLs:          aclList,
-		DoObjectgroup: model.canObjectgroup && !r.noGroupCode,
+	if sep == "[" {
+		w.WriteString("null")
+	} else {
+		w.WriteString("]")
+	}
+	// Attribute has "omitempty".
+	if model.canObjectgroup && !r0.noGroupCode {
+		writeKey(",", "do_objectgroup")
+		write(true)
+	}
+	w.WriteString("}\n")
+	if err := w.Flush(); err != nil {
+		c.abort("Can't %v", err)
+	}
+	if err := fd.Close(); err != nil {
+		c.abort("Can't %v", err)
 	}
-	c.writeJson(path, result)
+	return count
 }
 
 // Make output directory available.
@@ -2305,6 +2434,25 @@ func (c *spoc) printRouter(r *router, dir string) string {
 		for _, vrouter := range vrfMembers {
 			c.printCiscoAcls(fd, vrouter)
 		}
+	} else if model.filter == "iptables" && len(vrfMembers) > 1 {
+		// Rules of all VRFs are loaded by a single call of
+		// iptables-restore. Hence print routes and crypto first.
+		var managed []*router
+		for _, vrouter := range vrfMembers {
+			c.printRoutes(fd, vrouter)
+			if vrouter.managed != "" {
+				c.printCrypto(fd, vrouter)
+				managed = append(managed, vrouter)
+			}
+		}
+		if managed != nil {
+			printAclPrefix(fd, managed[0])
+			for _, vrouter := range managed {
+				c.generateAcls(fd, vrouter)
+			}
+			printAclSuffix(fd, managed[0])
+			c.printKeepalived(fd, managed)
+		}
 	} else {
 		for _, vrouter := range vrfMembers {
 			c.printRoutes(fd, vrouter)
@@ -2315,48 +2463,85 @@ func (c *spoc) printRouter(r *router, dir string) string {
 			printAclPrefix(fd, vrouter)
 			c.generateAcls(fd, vrouter)
 			printAclSuffix(fd, vrouter)
-			printRouterIntf(fd, vrouter)
+			c.printRouterIntf(fd, vrouter)
+			c.printKeepalived(fd, []*router{vrouter})
 		}
 	}
 
 	// Print ACLs in machine independent format into separate file.
 	// Collect ACLs from VRF parts.
 	aclFile := filepath.Join(dir, path+".rules")
-	c.printAcls(aclFile, vrfMembers)
+	c.profileACLLines(path, c.printAcls(aclFile, vrfMembers))
 	return path
 }
 
-func (c *spoc) printConcurrent(devices []*router, dir, prev string) {
+// Print code of pass1 on pool of workers of pass1,
+// then generate code of pass2.
+// Raw files are copied before pass2 is started, hence
+// profile of pass2 only contains pass2.
+func (c *spoc) printCode(inDir, dir string) {
+	var devices []*router
+	prev := path.Join(dir, ".prev")
 	var reused int32 = 0
-	pass2Code := func(r *router) {
-		path := c.printRouter(r, dir)
-		if pass2.File(path, dir, prev) {
+	var reuse *pass2.Reuse
+	pass2Code := func(path string) {
+		if reuse.File(path, dir) {
 			atomic.AddInt32(&reused, 1)
 			c.diag("Reused .prev/" + path)
 		}
 	}
-	if c.conf.ConcurrencyPass2 <= 1 {
-		for _, r := range devices {
-			pass2Code(r)
+	var paths []string
+	c.phase("printCode", func() {
+		c.progress("Printing code")
+		c.setupStdAddr()
+		devices = c.getDevices()
+		c.checkOutputDir(dir, prev, devices)
+		reuse = pass2.NewReuse(prev)
+		if c.conf.MemoryLimit > 0 {
+			// In low-memory mode, generate code device by device.
+			// Pass 2 is run directly after intermediate code has been
+			// written, hence data of only a single device is held in
+			// memory. Pass 2 is included in this phase of pass1.
+			for _, r := range devices {
+				pass2Code(c.printRouter(r, dir))
+			}
+			return
+		}
+		paths = make([]string, len(devices))
+		c.parallel(len(devices), func(c *spoc, i int) {
+			paths[i] = c.printRouter(devices[i], dir)
+		})
+	})
+	c.phase("copyRaw", func() { c.copyRaw(inDir, dir) })
+	c.startProfile("pass2")
+	c.phase("pass2", func() {
+		if c.conf.ConcurrencyPass2 <= 1 {
+			for _, path := range paths {
+				pass2Code(path)
+			}
+		} else {
+			concurrentGoroutines :=
+				make(chan struct{}, c.conf.ConcurrencyPass2)
+			var wg sync.WaitGroup
+			for _, path := range paths {
+				concurrentGoroutines <- struct{}{}
+				wg.Add(1)
+				go func(path string) {
+					defer wg.Done()
+					pass2Code(path)
+					<-concurrentGoroutines
+				}(path)
+			}
+			wg.Wait()
+		}
+		if err := reuse.WriteManifest(dir); err != nil {
+			c.abort("Can't %v", err)
 		}
-	} else {
-		concurrentGoroutines := make(chan struct{}, c.conf.ConcurrencyPass2)
-		var wg sync.WaitGroup
-		for _, r := range devices {
-			concurrentGoroutines <- struct{}{}
-			wg.Add(1)
-			go func(r *router) {
-				defer wg.Done()
-				pass2Code(r)
-				<-concurrentGoroutines
-			}(r)
-		}
-		wg.Wait()
-	}
-	// Remove directory '.prev' created by pass1
-	// or remove symlink '.prev' created by newpolicy.pl.
-	// Error is ignored; would use unneeded space only.
-	os.RemoveAll(prev)
+		// Remove directory '.prev' created by pass1
+		// or remove symlink '.prev' created by newpolicy.pl.
+		// Error is ignored; would use unneeded space only.
+		os.RemoveAll(prev)
+	})
 
 	generated := int32(len(devices)) - reused
 	if generated != 0 {
@@ -2366,12 +2551,3 @@ func (c *spoc) printConcurrent(devices []*router, dir, prev string) {
 		c.info("Reused files for %d devices from previous run", reused)
 	}
 }
-
-func (c *spoc) printCode(dir string) {
-	c.progress("Printing code")
-	c.setupStdAddr()
-	devices := c.getDevices()
-	prev := path.Join(dir, ".prev")
-	c.checkOutputDir(dir, prev, devices)
-	c.printConcurrent(devices, dir, prev)
-}
@@ -146,7 +146,7 @@ func (c *spoc) printGroup(
 	}
 
 	// Expand group definition.
-	elements := c.expandGroup(parsed, "print-group", true)
+	elements := c.expandGroup(parsed, "print-group", "", true)
 
 	if showUnused {
 		j := 0
@@ -1,6 +1,7 @@
 package pass1
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,6 +27,8 @@ func PrintPathMain(d oslink.Data) int {
 
 	// Command line flags
 	quiet := fs.BoolP("quiet", "q", false, "Don't print progress messages")
+	detailed := fs.BoolP("detailed", "d", false,
+		"Show hops with interfaces, zones and routes of each path")
 	if err := fs.Parse(d.Args[1:]); err != nil {
 		if errors.Is(err, pflag.ErrHelp) {
 			return 1
@@ -47,22 +50,43 @@ func PrintPathMain(d oslink.Data) int {
 	cnf := conf.ConfigFromFile(path)
 	cnf.Quiet = *quiet
 	return toplevelSpoc(d, cnf, func(c *spoc) {
-		c.printPath(d.Stdout, path, params)
+		c.printPath(d.Stdout, path, params, *detailed)
 	})
 }
 
-func (c *spoc) printPath(stdout io.Writer, path string, params []string) {
+func (c *spoc) printPath(
+	stdout io.Writer, path string, params []string, detailed bool) {
+
 	c.readNetspoc(path)
 	c.setZone()
 	c.setPath()
+	if detailed {
+		c.printDetailedPath(stdout, params)
+		return
+	}
+	isUsed := c.markPathObjects(params)
+
+	var used []string
+	for e := range isUsed {
+		if !strings.HasPrefix(e, "interface") {
+			used = append(used, e)
+		}
+	}
+	slices.Sort(used)
+	used = slices.Compact(used)
+	out, _ := json.Marshal(used)
+	fmt.Fprintln(stdout, string(out))
+}
 
+// getPathEnds returns networks of the two objects given in params.
+func (c *spoc) getPathEnds(params []string) [2]*network {
 	var l [2]*network
 	for i, obj := range params {
 		parsed, err := parser.ParseUnion([]byte(obj))
 		if err != nil {
 			c.abort("%v", err)
 		}
-		elements := c.expandGroup(parsed, "print-path", false)
+		elements := c.expandGroup(parsed, "print-path", "", false)
 		c.stopOnErr()
 		if len(elements) != 1 {
 			if !(len(elements) == 2 && elements[0].isCombined46()) {
@@ -87,7 +111,13 @@ func (c *spoc) printPath(stdout io.Writer, path string, params []string) {
 			l[i] = l[i].combined46
 		}
 	}
+	return l
+}
 
+// markPathObjects returns names of networks, routers and interfaces
+// on path between the two objects given in params.
+func (c *spoc) markPathObjects(params []string) map[string]bool {
+	l := c.getPathEnds(params)
 	znl := make(map[*zone]netList)
 	isUsed := make(map[string]bool)
 	isUsed[l[0].name] = true
@@ -114,15 +144,142 @@ func (c *spoc) printPath(stdout io.Writer, path string, params []string) {
 	for _, list := range znl {
 		markPathInZone(list)
 	}
+	return isUsed
+}
 
-	var used []string
-	for e := range isUsed {
-		if !strings.HasPrefix(e, "interface") {
-			used = append(used, e)
+type pathHop struct {
+	Router  string `json:"router"`
+	In      string `json:"in"`
+	Out     string `json:"out"`
+	InZone  string `json:"in_zone"`
+	OutZone string `json:"out_zone"`
+	NextHop string `json:"next_hop,omitempty"`
+	Routing string `json:"routing,omitempty"`
+	in, out *routerIntf
+}
+
+// printDetailedPath prints all paths between the two objects given in
+// params. Each path is an ordered list of hops at managed routers.
+// Multiple paths are found, if the topology has loops and
+// pathrestrictions or redundancy groups allow alternative paths.
+func (c *spoc) printDetailedPath(stdout io.Writer, params []string) {
+	l := c.getPathEnds(params)
+	c.setRoutesInZones()
+	rule := &groupedRule{
+		serviceRule: &serviceRule{
+			prt: []*proto{c.prt.IP},
+		},
+		src:     []someObj{l[0]},
+		dst:     []someObj{l[1]},
+		srcPath: l[0].getPathNode(),
+		dstPath: l[1].getPathNode(),
+	}
+
+	// Collect hops, indexed by zone, where the hop is entered.
+	zone2hops := make(map[*zone][]*pathHop)
+	c.pathWalk(rule, func(r *groupedRule, i, o *routerIntf) {
+		zone2hops[i.zone] = append(zone2hops[i.zone], &pathHop{
+			Router:  i.router.name,
+			In:      i.name,
+			Out:     o.name,
+			InZone:  i.zone.name,
+			OutZone: o.zone.name,
+			in:      i,
+			out:     o,
+		})
+	}, "Router")
+	c.stopOnErr()
+
+	// Generate static routes for path from source to destination.
+	t := make(routingTree)
+	c.generateRoutingTree1(rule, t)
+	c.generateRoutingInfo(t)
+
+	// Find all paths from zone of source to zone of destination.
+	// Hops found by pathWalk are combined from all valid paths.
+	// Hence pathrestrictions must be checked again for each path.
+	src, dst := l[0].zone, l[1].zone
+	var paths [][]pathHop
+	seen := make(map[*zone]bool)
+	active := make(map[*pathRestriction]bool)
+	var path []*pathHop
+	isBlocked := func(h *pathHop) bool {
+		list := slices.Concat(h.in.pathRestrict, h.out.pathRestrict)
+		for i, restrict := range list {
+			if active[restrict] || slices.Contains(list[:i], restrict) {
+				return true
+			}
 		}
+		return false
 	}
-	slices.Sort(used)
-	used = slices.Compact(used)
-	out, _ := json.Marshal(used)
+	setActive := func(h *pathHop, b bool) {
+		for _, restrict := range h.in.pathRestrict {
+			active[restrict] = b
+		}
+		for _, restrict := range h.out.pathRestrict {
+			active[restrict] = b
+		}
+	}
+	var walk func(z *zone)
+	walk = func(z *zone) {
+		if z == dst {
+			paths = append(paths, getPathRoutes(path, l[1]))
+			return
+		}
+		seen[z] = true
+		for _, h := range zone2hops[z] {
+			if !seen[h.out.zone] && !isBlocked(h) {
+				path = append(path, h)
+				setActive(h, true)
+				walk(h.out.zone)
+				setActive(h, false)
+				path = path[:len(path)-1]
+			}
+		}
+		seen[z] = false
+	}
+	walk(src)
+	slices.SortFunc(paths, func(a, b []pathHop) int {
+		return cmp.Or(
+			cmp.Compare(len(a), len(b)),
+			slices.CompareFunc(a, b, func(h1, h2 pathHop) int {
+				return cmp.Or(cmp.Compare(h1.In, h2.In), cmp.Compare(h1.Out, h2.Out))
+			}))
+	})
+
+	result := struct {
+		Source      string      `json:"source"`
+		Destination string      `json:"destination"`
+		Paths       [][]pathHop `json:"paths"`
+	}{
+		Source:      l[0].name,
+		Destination: l[1].name,
+		Paths:       paths,
+	}
+	out, _ := json.Marshal(result)
 	fmt.Fprintln(stdout, string(out))
 }
+
+// getPathRoutes returns a copy of hops of path, where the next hop is
+// added to each hop. The next hop is taken from static routes, that
+// have been generated at outgoing interface of each hop.
+func getPathRoutes(path []*pathHop, dst *network) []pathHop {
+	result := make([]pathHop, len(path))
+	for i, h := range path {
+		result[i] = *h
+		out := h.out
+		if out.routing != nil {
+			result[i].Routing = out.routing.name
+			continue
+		}
+		// Multiple next hops are found, if alternative paths exist.
+		// Take next hop of current path.
+		hops := out.routes[getNatNetwork(dst, out.natMap)]
+		if i+1 < len(path) && slices.Contains(hops, path[i+1].in) {
+			result[i].NextHop = path[i+1].in.name
+		} else if len(hops) > 0 {
+			result[i].NextHop = hops[0].name
+		}
+	}
+	return result
+}
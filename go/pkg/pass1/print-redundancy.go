@@ -0,0 +1,143 @@
+package pass1
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+)
+
+// Get virtual interfaces with redundancy protocol of hardware interface.
+func getRedundancyIntfs(hw *hardware) intfList {
+	var result intfList
+	for _, intf := range hw.interfaces {
+		if intf.redundant && intf.redundancyType != nil {
+			result.push(intf)
+		}
+	}
+	return result
+}
+
+// Configuration of redundancy protocol can only be generated,
+// if attribute 'id' is known. Linux supports VRRP only.
+func (c *spoc) checkRedundancyConfig() {
+	if !c.conf.GenerateRedundancy {
+		return
+	}
+	for _, r := range c.managedRouters {
+		class := r.model.class
+		if class != "IOS" && class != "Linux" {
+			continue
+		}
+		for _, hw := range r.hardware {
+			for _, intf := range getRedundancyIntfs(hw) {
+				if intf.redundancyId == "" {
+					c.err("Missing 'id' in 'virtual' of %s,"+
+						" needed to generate redundancy configuration", intf)
+				}
+				if t := intf.redundancyType.name; class == "Linux" && t != "VRRP" {
+					c.err("Can't generate configuration of %s"+
+						" for model Linux at %s", t, intf)
+				}
+			}
+		}
+	}
+}
+
+// Get subcommands of IOS interface for HSRP or VRRP.
+// HSRP with IPv6 needs version 2 and uses link-local address
+// derived from virtual MAC address.
+// VRRP with IPv6 is configured as VRRPv3 in separate submode.
+// Link-local address is derived from virtual MAC address 00-00-5E-00-02-ID.
+func iosRedundancyCmds(hw *hardware, ipv6 bool) []string {
+	var result []string
+	version2 := false
+	for _, intf := range getRedundancyIntfs(hw) {
+		id := intf.redundancyId
+		prio := intf.redundancyPrio
+		switch t := intf.redundancyType.name; t {
+		case "HSRP", "HSRPv2":
+			if (t == "HSRPv2" || ipv6) && !version2 {
+				result = append(result, "standby version 2")
+				version2 = true
+			}
+			cmd := "standby " + id
+			if ipv6 {
+				result = append(result,
+					cmd+" ipv6 autoconfig",
+					cmd+" ipv6 "+intf.ipPrefix().String())
+			} else {
+				result = append(result, cmd+" ip "+intf.ip.String())
+			}
+			if prio != "" {
+				result = append(result, cmd+" priority "+prio)
+			}
+		case "VRRP":
+			cmd := "vrrp " + id
+			if ipv6 {
+				num, _ := strconv.Atoi(id)
+				result = append(result,
+					cmd+" address-family ipv6",
+					fmt.Sprintf(" address fe80::200:5eff:fe00:2%02x primary", num),
+					" address "+intf.ipPrefix().String())
+				if prio != "" {
+					result = append(result, " priority "+prio)
+				}
+			} else {
+				result = append(result, cmd+" ip "+intf.ip.String())
+				if prio != "" {
+					result = append(result, cmd+" priority "+prio)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Print configuration of keepalived for VRRP at Linux.
+// Configuration of all VRF members is written into a single file.
+// Separate files are used for IPv4 and IPv6, because code of
+// dual stack router is generated into two separate files.
+// Both files must be included from keepalived.conf.
+// Names of IPv6 instances get suffix "_v6" to be unique.
+func (c *spoc) printKeepalived(fh *os.File, vrfMembers []*router) {
+	if !c.conf.GenerateRedundancy || vrfMembers[0].model.class != "Linux" {
+		return
+	}
+	var l intfList
+	for _, r := range vrfMembers {
+		for _, hw := range r.hardware {
+			l = append(l, getRedundancyIntfs(hw)...)
+		}
+	}
+	if l == nil {
+		return
+	}
+	r := vrfMembers[0]
+	file, suffix := "ipv4.conf", ""
+	if r.ipV6 {
+		file, suffix = "ipv6.conf", "_v6"
+	}
+	fmt.Fprintln(fh, r.model.commentChar, "[ Redundancy ]")
+	fmt.Fprintln(fh, "cat > /etc/keepalived/conf.d/"+file+" <<EOF")
+	for _, intf := range l {
+		hw := intf.hardware.name
+		id := intf.redundancyId
+		fmt.Fprintln(fh, "vrrp_instance "+hw+"_"+id+suffix+" {")
+		fmt.Fprintln(fh, " interface", hw)
+		fmt.Fprintln(fh, " virtual_router_id", id)
+		if p := intf.redundancyPrio; p != "" {
+			fmt.Fprintln(fh, " priority", p)
+		}
+		fmt.Fprintln(fh, " virtual_ipaddress {")
+		fmt.Fprintln(fh, "  "+intf.ipPrefix().String())
+		fmt.Fprintln(fh, " }")
+		fmt.Fprintln(fh, "}")
+	}
+	fmt.Fprintln(fh, "EOF")
+}
+
+// Get address of virtual interface with prefix length of its network.
+func (intf *routerIntf) ipPrefix() netip.Prefix {
+	return netip.PrefixFrom(intf.ip, intf.network.ipp.Bits())
+}
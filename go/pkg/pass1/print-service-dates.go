@@ -0,0 +1,103 @@
+package pass1
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/conf"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/spf13/pflag"
+)
+
+func (c *spoc) printServiceDates(
+	stdout io.Writer, path string, onlyDated bool, expiring int) {
+
+	c.readNetspoc(path)
+	c.setZone()
+	c.setPath()
+	c.distributeNatInfo()
+	c.stopOnErr()
+
+	// Owners are taken from split parts of service.
+	expSvcList := c.normalizeServicesForExport()
+	c.setupServiceInfo(expSvcList, make(map[srvObj]bool), nil, nil)
+	svc2owners := make(map[string]map[string]bool)
+	for _, s := range expSvcList {
+		name, _, _ := strings.Cut(s.name, "(")
+		m := svc2owners[name]
+		if m == nil {
+			m = make(map[string]bool)
+			svc2owners[name] = m
+		}
+		for _, o := range s.owners {
+			m[o] = true
+		}
+	}
+	c.stopOnErr()
+
+	or := func(s string) string { return cmp.Or(s, "-") }
+	for _, sv := range c.ascendingServices {
+		if onlyDated && sv.enableAt == "" && sv.disableAt == "" {
+			continue
+		}
+		if expiring > 0 && (sv.disableAt == "" || sv.disabled ||
+			daysUntil(sv.disableDate) > expiring) {
+			continue
+		}
+		status := "enabled"
+		if sv.disabled {
+			status = "disabled"
+			if sv.enableAt != "" && !dateIsReached(sv.enableDate) {
+				status = "pending"
+			}
+		}
+		owners := slices.Sorted(maps.Keys(svc2owners[sv.name]))
+		fmt.Fprintf(stdout, "%s %s %s %s %s\n",
+			sv.name, or(sv.enableAt), or(sv.disableAt), status,
+			or(strings.Join(owners, ",")))
+	}
+}
+
+func PrintServiceDatesMain(d oslink.Data) int {
+	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
+
+	// Setup custom usage function.
+	fs.Usage = func() {
+		fmt.Fprintf(d.Stderr,
+			"Usage: %s [options] FILE|DIR\n%s",
+			d.Args[0], fs.FlagUsages())
+	}
+
+	// Command line flags
+	quiet := fs.BoolP("quiet", "q", false, "Don't print progress messages")
+	dated := fs.BoolP("dated", "d", false,
+		"Show only services with attribute enable_at or disable_at")
+	expiring := fs.IntP("expiring", "e", 0,
+		"Show only services disabled within given number of days")
+	if err := fs.Parse(d.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 1
+		}
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		fs.Usage()
+		return 1
+	}
+
+	// Argument processing
+	args := fs.Args()
+	if len(args) != 1 {
+		fs.Usage()
+		return 1
+	}
+	path := args[0]
+
+	cnf := conf.ConfigFromFile(path)
+	cnf.Quiet = *quiet
+	return toplevelSpoc(d, cnf, func(c *spoc) {
+		c.printServiceDates(d.Stdout, path, *dated, *expiring)
+	})
+}
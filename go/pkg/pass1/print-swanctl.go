@@ -0,0 +1,229 @@
+package pass1
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Names of algorithms in proposals of strongSwan.
+var swanEncryption = map[string]string{
+	"aes":          "aes128",
+	"aes192":       "aes192",
+	"aes256":       "aes256",
+	"des":          "des",
+	"3des":         "3des",
+	"aes-gcm":      "aes128gcm16",
+	"aes-gcm-192":  "aes192gcm16",
+	"aes-gcm-256":  "aes256gcm16",
+	"aes-gmac":     "aes128gmac",
+	"aes-gmac-192": "aes192gmac",
+	"aes-gmac-256": "aes256gmac",
+	"":             "null",
+}
+
+var swanHash = map[string]string{
+	"md5":    "md5",
+	"sha":    "sha1",
+	"sha256": "sha256",
+	"sha384": "sha384",
+	"sha512": "sha512",
+}
+
+var swanGroup = map[string]string{
+	"1":  "modp768",
+	"2":  "modp1024",
+	"5":  "modp1536",
+	"14": "modp2048",
+	"15": "modp3072",
+	"16": "modp4096",
+	"19": "ecp256",
+	"20": "ecp384",
+	"21": "ecp521",
+	"24": "modp2048s256",
+}
+
+// strongSwan can't use AH and ESP together in one CHILD_SA.
+func (c *spoc) verifyLinuxIpsec(r *router, crypto *crypto) {
+	ipsec := crypto.ipsec
+	if ipsec.ah != "" &&
+		(ipsec.espEncryption != "" || ipsec.espAuthentication != "") {
+		c.err("Must not use 'ah' together with 'esp_encryption' or"+
+			" 'esp_authentication' in %s for %s", ipsec.name, r)
+	}
+}
+
+func swanIkeProposal(isakmp *isakmp) string {
+	encr := swanEncryption[isakmp.encryption]
+	hash := swanHash[isakmp.hash]
+	// Combined mode cipher needs explicit pseudo random function.
+	if strings.Contains(encr, "gcm") {
+		hash = "prf" + hash
+	}
+	return encr + "-" + hash + "-" + swanGroup[isakmp.group]
+}
+
+func swanChildProposal(ipsec *ipsec) (string, string) {
+	key := "esp_proposals"
+	var l []string
+	if ah := ipsec.ah; ah != "" {
+		key = "ah_proposals"
+		l = append(l, swanHash[ah])
+	} else {
+		encr := ipsec.espEncryption
+		l = append(l, swanEncryption[encr])
+		if auth := ipsec.espAuthentication; auth != "" {
+			l = append(l, swanHash[auth])
+		}
+	}
+	if g := ipsec.pfsGroup; g != "" {
+		l = append(l, swanGroup[g])
+	}
+	return key, strings.Join(l, "-")
+}
+
+// Get tunnel interfaces of hardware interface.
+// Sort by IP of peer to get deterministic output.
+func getSwanTunnels(hw *hardware) intfList {
+	var result intfList
+	for _, intf := range hw.interfaces {
+		if intf.ipType == tunnelIP {
+			result.push(intf)
+		}
+	}
+	slices.SortFunc(result, func(a, b *routerIntf) int {
+		return a.peer.realIntf.ip.Compare(b.peer.realIntf.ip)
+	})
+	return result
+}
+
+// Name of connection and of iptables chain for tunnel interface.
+func swanName(hw *hardware, i int) string {
+	return "crypto-" + hw.name + "-" + strconv.Itoa(i+1)
+}
+
+// Print configuration of strongSwan in format of swanctl.conf.
+// Certificates and preshared keys are configured manually.
+func (c *spoc) printSwanctl(fh *os.File, r *router) {
+	fmt.Fprintln(fh, "swanctl --load-conns --file /dev/stdin <<EOF")
+	fmt.Fprintln(fh, "connections {")
+	for _, hw := range r.hardware {
+		natMap := hw.natMap
+		for i, intf := range getSwanTunnels(hw) {
+			name := swanName(hw, i)
+			peer := intf.peer
+			crypto := intf.getCrypto()
+			ipsec := crypto.ipsec
+			isakmp := ipsec.isakmp
+			fmt.Fprintln(fh, " "+name+" {")
+			fmt.Fprintln(fh, "  version =", isakmp.ikeVersion)
+			if real := intf.realIntf; real.ipType == hasIP {
+				fmt.Fprintln(fh, "  local_addrs =", real.ip)
+			}
+			fmt.Fprintln(fh, "  remote_addrs =",
+				prefixCode(peer.realIntf.address(natMap)))
+			fmt.Fprintln(fh, "  proposals =", swanIkeProposal(isakmp))
+			fmt.Fprintf(fh, "  rekey_time = %ds\n", isakmp.lifetime)
+			if isakmp.natTraversal == "on" {
+				fmt.Fprintln(fh, "  encap = yes")
+			}
+			auth := "psk"
+			if isakmp.authentication == "rsasig" {
+				auth = "pubkey"
+			}
+			printAuth := func(side, id string) {
+				fmt.Fprintln(fh, "  "+side+" {")
+				fmt.Fprintln(fh, "   auth =", auth)
+				if id != "" {
+					fmt.Fprintln(fh, "   id =", id)
+				}
+				fmt.Fprintln(fh, "  }")
+			}
+			// Attribute 'id' is only known at spoke.
+			if intf.isHub {
+				printAuth("local", "")
+				printAuth("remote", peer.id)
+			} else {
+				printAuth("local", intf.id)
+				printAuth("remote", "")
+			}
+
+			// Traffic selectors must obey NAT.
+			tsCode := func(l []*network) string {
+				var codes stringList
+				for _, n := range l {
+					codes.push(prefixCode(n.address(intf.natMap)))
+				}
+				return strings.Join(codes, ",")
+			}
+			local, remote := c.getCryptoNetworks(intf, crypto)
+			fmt.Fprintln(fh, "  children {")
+			fmt.Fprintln(fh, "   "+name+" {")
+			fmt.Fprintln(fh, "    local_ts =", tsCode(local))
+			fmt.Fprintln(fh, "    remote_ts =", tsCode(remote))
+			key, proposal := swanChildProposal(ipsec)
+			fmt.Fprintln(fh, "    "+key+" =", proposal)
+			if pair := ipsec.lifetime; pair != nil {
+				if sec := pair[0]; sec != -1 {
+					fmt.Fprintf(fh, "    rekey_time = %ds\n", sec)
+				}
+				if kb := pair[1]; kb != -1 {
+					fmt.Fprintf(fh, "    rekey_bytes = %d\n", kb*1024)
+				}
+			}
+			fmt.Fprintln(fh, "    start_action = trap")
+			fmt.Fprintln(fh, "   }")
+			fmt.Fprintln(fh, "  }")
+			fmt.Fprintln(fh, " }")
+		}
+	}
+	fmt.Fprintln(fh, "}")
+	fmt.Fprintln(fh, "EOF")
+}
+
+// Print chains with rules for traffic leaving crypto tunnel.
+// Decrypted packets are identified by policy match with IP of peer.
+// These chains must be called before chains of cleartext traffic
+// entering the same hardware interface. Packets not permitted by
+// these chains are dropped immediately.
+func printSwanFilterAcls(fh *os.File, r *router) {
+	if r.model.crypto != "Linux" {
+		return
+	}
+	for _, hw := range r.hardware {
+		natMap := hw.natMap
+		for i, intf := range getSwanTunnels(hw) {
+			name := swanName(hw, i)
+			peerIP := prefixCode(intf.peer.realIntf.address(natMap))
+			policy := "-m policy --dir in --pol ipsec --mode tunnel" +
+				" --tunnel-src " + peerIP
+			forward := &aclInfo{
+				name:    name,
+				rules:   intf.rules,
+				natMap:  intf.natMap,
+				addDeny: true,
+			}
+			self := &aclInfo{
+				name:    name + "_self",
+				rules:   intf.intfRules,
+				natMap:  intf.natMap,
+				addDeny: true,
+			}
+			intf.rules = nil
+			intf.intfRules = nil
+			for _, acl := range []*aclInfo{self, forward} {
+				chain := "FORWARD"
+				if acl == self {
+					chain = "INPUT"
+				}
+				r.aclList.push(acl)
+				printAclPlaceholder(fh, r, acl.name)
+				fmt.Fprintln(fh, "-A", chain, "-j", acl.name, "-i", hw.name, policy)
+				fmt.Fprintln(fh, "-A", chain, "-j droplog -i", hw.name, policy)
+				fmt.Fprintln(fh)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package pass1
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// Profiling is enabled by option --profile_dir.
+// CPU and heap profiles of pass1 and pass2 are written to files
+// pass1.cpu.pprof, pass1.heap.pprof, pass2.cpu.pprof, pass2.heap.pprof.
+// Wall time, allocations and object counts of each phase
+// and number of ACL lines of each device are written to file report.json.
+type profiler struct {
+	dir     string
+	pass    string
+	cpuFile *os.File
+	mutex   sync.Mutex
+	report  profileReport
+	// Current object counts, added to report of each phase.
+	expandedRules int
+	aclLines      int
+	// Phases running concurrently with startWithBackground.
+	// Counts of networks and rules are added when both jobs
+	// have finished.
+	concurrent bool
+	pending    []*phaseReport
+}
+
+type profileReport struct {
+	Phases []*phaseReport `json:"phases"`
+	// Number of ACL lines of each device.
+	ACLLines map[string]int `json:"acl_lines,omitempty"`
+}
+
+type phaseReport struct {
+	Name          string  `json:"name"`
+	Pass          string  `json:"pass"`
+	WallTime      float64 `json:"wall_time"`
+	Allocs        uint64  `json:"allocs"`
+	AllocBytes    uint64  `json:"alloc_bytes"`
+	Networks      int     `json:"networks"`
+	Rules         int     `json:"rules"`
+	ExpandedRules int     `json:"expanded_rules,omitempty"`
+	// Sum of ACL lines of all devices.
+	ACLLines int `json:"acl_lines,omitempty"`
+}
+
+// Start CPU profile of given pass.
+// Profile of previous pass is stopped.
+func (c *spoc) startProfile(pass string) {
+	dir := c.conf.ProfileDir
+	if dir == "" {
+		return
+	}
+	p := c.prof
+	if p == nil {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			c.abort("Can't %v", err)
+		}
+		p = &profiler{dir: dir}
+		p.report.ACLLines = make(map[string]int)
+		c.prof = p
+	} else {
+		c.stopProfile()
+	}
+	fd, err := os.Create(filepath.Join(dir, pass+".cpu.pprof"))
+	if err != nil {
+		c.abort("Can't %v", err)
+	}
+	if err := pprof.StartCPUProfile(fd); err != nil {
+		fd.Close()
+		c.abort("Can't start CPU profile: %v", err)
+	}
+	p.pass = pass
+	p.cpuFile = fd
+}
+
+// Stop CPU profile and write heap profile of current pass.
+func (c *spoc) stopProfile() {
+	p := c.prof
+	if p == nil || p.cpuFile == nil {
+		return
+	}
+	pprof.StopCPUProfile()
+	p.cpuFile.Close()
+	p.cpuFile = nil
+	fd, err := os.Create(filepath.Join(p.dir, p.pass+".heap.pprof"))
+	if err != nil {
+		c.abort("Can't %v", err)
+	}
+	defer fd.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(fd); err != nil {
+		c.abort("Can't write heap profile: %v", err)
+	}
+}
+
+// Stop profiling and write report of phases.
+func (c *spoc) finishProfile() {
+	p := c.prof
+	if p == nil {
+		return
+	}
+	c.stopProfile()
+	c.writeJson(filepath.Join(p.dir, "report.json"), &p.report)
+}
+
+// Run function f as named phase and add its statistics to report.
+// Phases running concurrently with startWithBackground are measured
+// separately, but allocations of the other job are included.
+func (c *spoc) phase(name string, f func()) {
+	p := c.prof
+	if p == nil {
+		f()
+		return
+	}
+	var m1, m2 runtime.MemStats
+	runtime.ReadMemStats(&m1)
+	start := time.Now()
+	f()
+	wall := time.Since(start).Seconds()
+	runtime.ReadMemStats(&m2)
+	ph := &phaseReport{
+		Name:       name,
+		WallTime:   wall,
+		Allocs:     m2.Mallocs - m1.Mallocs,
+		AllocBytes: m2.TotalAlloc - m1.TotalAlloc,
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	ph.Pass = p.pass
+	ph.ExpandedRules = p.expandedRules
+	ph.ACLLines = p.aclLines
+	p.report.Phases = append(p.report.Phases, ph)
+	if p.concurrent {
+		p.pending = append(p.pending, ph)
+	} else {
+		c.profileCounts(ph)
+	}
+}
+
+func (c *spoc) profileCounts(ph *phaseReport) {
+	ph.Networks = len(c.allNetworks)
+	ph.Rules = len(c.allPathRules.permit) + len(c.allPathRules.deny)
+}
+
+// Mark start and end of concurrently running jobs.
+// At end, counts are added to phases of both jobs.
+func (c *spoc) profileConcurrent(start bool) {
+	p := c.prof
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.concurrent = start
+	if !start {
+		for _, ph := range p.pending {
+			c.profileCounts(ph)
+		}
+		p.pending = nil
+	}
+}
+
+func (c *spoc) profileExpandedRules(n int) {
+	if p := c.prof; p != nil {
+		p.mutex.Lock()
+		p.expandedRules = n
+		p.mutex.Unlock()
+	}
+}
+
+func (c *spoc) profileACLLines(path string, n int) {
+	if p := c.prof; p != nil {
+		p.mutex.Lock()
+		p.report.ACLLines[path] = n
+		p.aclLines += n
+		p.mutex.Unlock()
+	}
+}
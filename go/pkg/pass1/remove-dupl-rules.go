@@ -12,7 +12,7 @@ func (c *spoc) removeSimpleDuplicateRules() {
 			dst someObj
 			prt *proto
 		}
-		seen := make(map[key]bool)
+		seen := make(map[key]*groupedRule)
 		j := 0
 		for _, r := range rules {
 			if len(r.src) == 1 && len(r.dst) == 1 && len(r.prt) == 1 &&
@@ -22,11 +22,17 @@ func (c *spoc) removeSimpleDuplicateRules() {
 				s := r.src[0]
 				d := r.dst[0]
 				p := r.prt[0]
-				if seen[key{s, d, p}] {
+				if r0 := seen[key{s, d, p}]; r0 != nil {
 					c.diag("Removed duplicate " + r.print())
+					if c.conf.ServiceMap {
+						if r.rule != nil {
+							r0.dupServices = append(r0.dupServices, r.rule.service)
+						}
+						r0.dupServices = append(r0.dupServices, r.dupServices...)
+					}
 					continue
 				}
-				seen[key{s, d, p}] = true
+				seen[key{s, d, p}] = r
 			}
 			rules[j] = r
 			j++
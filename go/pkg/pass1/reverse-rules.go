@@ -127,6 +127,7 @@ func (c *spoc) genReverseRules1(rules []*groupedRule) []*groupedRule {
 					modifiers: modifiers{stateless: true},
 					deny:      rule.deny,
 					prt:       prtList,
+					rule:      rule.rule,
 				},
 				src:     rule.dst,
 				dst:     rule.src,
@@ -2,6 +2,7 @@ package pass1
 
 import (
 	"fmt"
+	"net/netip"
 	"slices"
 )
 
@@ -12,7 +13,18 @@ managed interfaces.
 */
 func (c *spoc) findActiveRoutes() {
 	c.progress("Finding routes")
+	c.setRoutesInZones()
 
+	// Generate pseudo rule set with all src dst pairs to determine routes for.
+	tree := c.generateRoutingTree()
+
+	// Generate routing info for every pseudo rule and store it in interfaces.
+	c.generateRoutingInfo(tree)
+
+	c.checkAndConvertRoutes()
+}
+
+func (c *spoc) setRoutesInZones() {
 	// Mark interfaces of unmanaged routers such that no routes are collected.
 	for _, r := range c.allRouters {
 		if r.semiManaged && !r.routingOnly {
@@ -23,17 +35,10 @@ func (c *spoc) findActiveRoutes() {
 	}
 
 	// Generate navigation information for routing inside zones.
-	for _, z := range c.allZones {
-		c.setRoutesInZone(z)
-	}
-
-	// Generate pseudo rule set with all src dst pairs to determine routes for.
-	tree := c.generateRoutingTree()
-
-	// Generate routing info for every pseudo rule and store it in interfaces.
-	c.generateRoutingInfo(tree)
-
-	c.checkAndConvertRoutes()
+	// Zones are processed concurrently.
+	c.parallel(len(c.allZones), func(c *spoc, i int) {
+		c.setRoutesInZone(c.allZones[i])
+	})
 }
 
 type netMap map[*network]bool
@@ -624,13 +629,16 @@ func (c *spoc) checkAndConvertRoutes() {
 	for _, r := range c.managedRouters {
 		fixBridgedRoutes(r)
 	}
-	for _, r := range c.managedRouters {
+	// Routers are processed concurrently,
+	// because only routes of current router are changed.
+	c.parallel(len(c.managedRouters), func(c *spoc, i int) {
+		r := c.managedRouters[i]
 		if r.routingOnly {
 			c.addRoutingOnlyNetworks(r)
 		}
 		c.adjustVPNRoutes(r)
 		c.checkDuplicateRoutes(r)
-	}
+	})
 }
 
 // Add networks of locally attached zone to routing_only devices.
@@ -960,3 +968,176 @@ func getMainInterface(intf *routerIntf) *routerIntf {
 	}
 	return intf
 }
+
+// Aggregate static routes with identical hop into supernets.
+// Two routes with same hop are replaced by their smallest common
+// supernet, if no other route and no directly connected network
+// overlapping this supernet uses a different hop.
+// Routes inside the supernet become redundant and are removed.
+// Function 'keep' marks hops, whose routes must not be aggregated.
+//
+// Routes and connected networks are stored in a binary trie.
+// A single walk finds the largest subtrees, that only contain
+// routes with identical hop. All routes of such a subtree are
+// replaced by their smallest common supernet.
+func aggregateRoutes(prefix2ip2net map[int]map[netip.Addr]*network,
+	net2hop map[*network]*routerIntf, connected []netip.Prefix,
+	keep func(*routerIntf) bool) {
+
+	var roots [2]*routeTrie
+	getNode := func(p netip.Prefix) *routeTrie {
+		i := 0
+		if p.Addr().Is6() {
+			i = 1
+		}
+		if roots[i] == nil {
+			roots[i] = &routeTrie{prefix: netip.PrefixFrom(p.Addr(), 0).Masked()}
+		}
+		return roots[i].node(p)
+	}
+	for _, ip2net := range prefix2ip2net {
+		for _, n := range ip2net {
+			getNode(n.ipp).net = n
+		}
+	}
+	for _, p := range connected {
+		if p.IsValid() {
+			getNode(p.Masked()).connected = true
+		}
+	}
+	replace := func(t *routeTrie, hop *routerIntf) {
+		super := t.commonSupernet()
+		t.walk(func(n *network) {
+			delete(prefix2ip2net[n.ipp.Bits()], n.ipp.Addr())
+		})
+		combined := super.net
+		if combined == nil {
+			combined = &network{ipp: super.prefix}
+			net2hop[combined] = hop
+		}
+		ip2net := prefix2ip2net[super.prefix.Bits()]
+		if ip2net == nil {
+			ip2net = make(map[netip.Addr]*network)
+			prefix2ip2net[super.prefix.Bits()] = ip2net
+		}
+		ip2net[super.prefix.Addr()] = combined
+	}
+	// Walk trie from top to bottom.
+	// ancHop is hop of routes at enclosing nodes, mixed is set
+	// if enclosing nodes have different hops or a connected network.
+	var find func(t *routeTrie, ancHop *routerIntf, mixed bool)
+	find = func(t *routeTrie, ancHop *routerIntf, mixed bool) {
+		if t == nil || t.count < 2 {
+			return
+		}
+		hop := t.hop
+		// Must not aggregate into network 0/0.
+		if t.prefix.Bits() != 0 && !mixed && !t.mixed &&
+			(ancHop == nil || ancHop == hop) {
+
+			if !keep(hop) {
+				replace(t, hop)
+			}
+			return
+		}
+		if n := t.net; n != nil {
+			h := net2hop[n]
+			mixed = mixed || ancHop != nil && ancHop != h
+			ancHop = h
+		}
+		mixed = mixed || t.connected
+		for _, ch := range t.child {
+			find(ch, ancHop, mixed)
+		}
+	}
+	for _, root := range roots {
+		if root != nil {
+			root.summarize(net2hop)
+			find(root, nil, false)
+		}
+	}
+}
+
+// Binary trie of routes and connected networks.
+type routeTrie struct {
+	prefix    netip.Prefix
+	child     [2]*routeTrie
+	net       *network
+	connected bool
+	// Summary of subtree:
+	// number of routes, hop of routes if all have the same hop,
+	// mixed if there are different hops or some connected network.
+	count int
+	hop   *routerIntf
+	mixed bool
+}
+
+// Get node of prefix, add missing nodes.
+func (t *routeTrie) node(p netip.Prefix) *routeTrie {
+	addr := p.Addr().AsSlice()
+	for bits := t.prefix.Bits(); bits < p.Bits(); bits++ {
+		i := addr[bits/8] >> (7 - bits%8) & 1
+		ch := t.child[i]
+		if ch == nil {
+			ch = &routeTrie{
+				prefix: netip.PrefixFrom(p.Addr(), bits+1).Masked()}
+			t.child[i] = ch
+		}
+		t = ch
+	}
+	return t
+}
+
+func (t *routeTrie) summarize(net2hop map[*network]*routerIntf) {
+	t.mixed = t.connected
+	add := func(count int, hop *routerIntf, mixed bool) {
+		if count == 0 {
+			return
+		}
+		if t.count != 0 && t.hop != hop {
+			mixed = true
+		}
+		t.count += count
+		t.hop = hop
+		t.mixed = t.mixed || mixed
+	}
+	if n := t.net; n != nil {
+		add(1, net2hop[n], false)
+	}
+	for _, ch := range t.child {
+		if ch != nil {
+			ch.summarize(net2hop)
+			add(ch.count, ch.hop, ch.mixed)
+			t.mixed = t.mixed || ch.mixed
+		}
+	}
+}
+
+// Get node of smallest prefix containing all routes of subtree.
+func (t *routeTrie) commonSupernet() *routeTrie {
+	for t.net == nil {
+		var next *routeTrie
+		for _, ch := range t.child {
+			if ch != nil && ch.count != 0 {
+				if next != nil {
+					return t
+				}
+				next = ch
+			}
+		}
+		t = next
+	}
+	return t
+}
+
+// Call f for each route of subtree.
+func (t *routeTrie) walk(f func(*network)) {
+	if n := t.net; n != nil {
+		f(n)
+	}
+	for _, ch := range t.child {
+		if ch != nil {
+			ch.walk(f)
+		}
+	}
+}
@@ -37,9 +37,20 @@ func (c *spoc) showReadStatistics() {
 
 func (c *spoc) parseFiles(dir string) []ast.Toplevel {
 	var result []ast.Toplevel
+	mode := parser.Mode(0)
+	if c.conf.AllSyntaxErrors {
+		mode = parser.AllErrors
+	}
 	err := filetree.Walk(dir, func(input *filetree.Context) error {
 		source := []byte(input.Data)
-		aF, err := parser.ParseFile(source, input.Path, 0)
+		aF, err := parser.ParseFile(source, input.Path, mode)
+		if l, ok := err.(parser.ErrorList); ok {
+			// Show errors of all files.
+			for _, e := range l {
+				c.err("%v", e)
+			}
+			return nil
+		}
 		if err != nil {
 			return err
 		}
@@ -57,6 +68,7 @@ func (c *spoc) parseFiles(dir string) []ast.Toplevel {
 	if err != nil {
 		c.abort("%v", err)
 	}
+	c.stopOnErr()
 	return result
 }
 
@@ -69,9 +81,11 @@ func (c *spoc) setupTopology(toplevel []ast.Toplevel) {
 	c.checkGeneralPermit()
 	c.stopOnErr()
 	c.createTunnels()
+	c.checkCryptoPolicy()
 	c.linkVirtualInterfaces()
 	c.splitSemiManagedRouters()
 	c.collectRoutersAndNetworks()
+	c.checkRedundancyConfig()
 	c.stopOnErr()
 }
 
@@ -1269,7 +1283,7 @@ func (c *spoc) setupArea(v *ast.Area) {
 			return nil, nil
 		}
 		ctx := "'" + att + "' of " + name
-		l := c.expandGroup(u.Elements, ctx, false)
+		l := c.expandGroup(u.Elements, ctx, name+","+att, false)
 		var v4, v6 intfList
 		for _, el := range l {
 			intf, ok := el.(*routerIntf)
@@ -1362,7 +1376,7 @@ func (c *spoc) setupArea(v *ast.Area) {
 
 func (c *spoc) setupPathrestriction(v *ast.TopList) {
 	name := v.Name
-	l := c.expandGroup(v.Elements, name, false)
+	l := c.expandGroup(v.Elements, name, name+",elements", false)
 	var v4, v6 intfList
 	var hasCombined46 bool
 	for _, obj := range l {
@@ -2041,6 +2055,7 @@ func (c *spoc) setupInterface(
 		intf.redundant = virtual.redundant
 		intf.redundancyType = virtual.redundancyType
 		intf.redundancyId = virtual.redundancyId
+		intf.redundancyPrio = virtual.redundancyPrio
 	} else if !ipGiven && intf.ipType == hasIP {
 		intf.ipType = shortIP
 	}
@@ -2359,7 +2374,13 @@ func (c *spoc) setupService(v *ast.Service) {
 			sv.disabled = c.getFlag(a, name)
 		case "disable_at":
 			sv.disableAt = c.getSingleValue(a, name)
-			if c.dateIsReached(sv.disableAt, "'disable_at' of "+name) {
+			sv.disableDate, _ =
+				c.parseDate(sv.disableAt, "'disable_at' of "+name)
+		case "enable_at":
+			sv.enableAt = c.getSingleValue(a, name)
+			var ok bool
+			sv.enableDate, ok = c.parseDate(sv.enableAt, "'enable_at' of "+name)
+			if !ok || !dateIsReached(sv.enableDate) {
 				sv.disabled = true
 			}
 		default:
@@ -2369,6 +2390,19 @@ func (c *spoc) setupService(v *ast.Service) {
 	if sv.ipV4Only && sv.ipV6Only {
 		c.err("Must not use ipv4_only and ipv6_only together at %s", name)
 	}
+	if d := sv.disableDate; !d.IsZero() {
+		if !sv.enableDate.IsZero() && !sv.enableDate.Before(d) {
+			c.err("'enable_at' must be before 'disable_at' in %s", name)
+		}
+		if dateIsReached(d) {
+			sv.disabled = true
+		} else if n := c.conf.WarnExpiringDays; n > 0 {
+			if days := daysUntil(d); days <= n {
+				c.warn("%s will be disabled in %d day(s) at %s",
+					name, days, sv.disableAt)
+			}
+		}
+	}
 	sv.foreach = v.Foreach
 	sv.user = v.User.Elements
 	userUserCount := 0
@@ -2822,6 +2856,7 @@ var routerInfo = map[string]*model{
 		routing:     "iproute",
 		filter:      "iptables",
 		hasIoACL:    true,
+		crypto:      "Linux",
 		commentChar: "#",
 	},
 }
@@ -2878,6 +2913,18 @@ func (c *spoc) getModel(a *ast.Attribute, ctx string) *model {
 				default:
 					goto FAIL
 				}
+			case "Linux":
+				// Alternative formats of routing configuration,
+				// both supporting VRF.
+				switch att {
+				case "FRR":
+					info.routing = "FRR"
+				case "BATCH":
+					info.routing = "ip-batch"
+				default:
+					goto FAIL
+				}
+				info.canVRF = true
 			default:
 				goto FAIL
 			}
@@ -2913,6 +2960,11 @@ var routingInfo = map[string]*mcastProto{
 			v4: multicast{ips: []string{"224.0.0.9"}},
 			v6: multicast{ips: []string{"ff02::9"}}},
 	},
+	// BGP uses unicast only.
+	"BGP": {
+		name: "BGP",
+		prt:  &proto{proto: "tcp", ports: [2]int{179, 179}, name: "tcp 179"},
+	},
 	"dynamic": {name: "dynamic"},
 
 	// Identical to 'dynamic', but must only be applied to router, not
@@ -2936,13 +2988,15 @@ func (c *spoc) getRouting(a *ast.Attribute, ctx string) *mcastProto {
 // Definition of redundancy protocols.
 var xxrpInfo = map[string]*mcastProto{
 	"VRRP": {
-		prt: &proto{proto: "112", name: "proto 112"},
+		name: "VRRP",
+		prt:  &proto{proto: "112", name: "proto 112"},
 		mcast: mcast{
 			v4: multicast{ips: []string{"224.0.0.18"}},
 			v6: multicast{ips: []string{"ff02::12"}}},
 	},
 	"HSRP": {
-		prt: &proto{proto: "udp", ports: [2]int{1985, 1985}, name: "udp 1985"},
+		name: "HSRP",
+		prt:  &proto{proto: "udp", ports: [2]int{1985, 1985}, name: "udp 1985"},
 		mcast: mcast{
 			v4: multicast{ips: []string{"224.0.0.2"}},
 
@@ -2951,7 +3005,8 @@ var xxrpInfo = map[string]*mcastProto{
 			v6: multicast{ips: []string{"::e000:2"}}},
 	},
 	"HSRPv2": {
-		prt: &proto{proto: "udp", ports: [2]int{1985, 1985}, name: "udp 1985"},
+		name: "HSRPv2",
+		prt:  &proto{proto: "udp", ports: [2]int{1985, 1985}, name: "udp 1985"},
 		mcast: mcast{
 			v4: multicast{ips: []string{"224.0.0.102"}},
 			v6: multicast{ips: []string{"ff02::66"}}},
@@ -3010,6 +3065,15 @@ func (c *spoc) getVirtual(a *ast.Attribute, v6 bool, ctx string,
 				c.err("Redundancy ID must be > 0, < 256 in %s", vCtx)
 			}
 			virtual.redundancyId = id
+		case "priority":
+			prio := c.getSingleValue(a2, vCtx)
+			num, err := strconv.Atoi(prio)
+			if err != nil {
+				c.err("Redundancy priority must be numeric in %s", vCtx)
+			} else if !(num > 0 && num < 256) {
+				c.err("Redundancy priority must be > 0, < 256 in %s", vCtx)
+			}
+			virtual.redundancyPrio = prio
 		default:
 			c.err("Unexpected attribute in %s: %s", vCtx, a2.Name)
 		}
@@ -3021,6 +3085,10 @@ func (c *spoc) getVirtual(a *ast.Attribute, v6 bool, ctx string,
 		c.err("Redundancy ID is given without redundancy protocol in %s",
 			vCtx)
 	}
+	if virtual.redundancyPrio != "" && virtual.redundancyType == nil {
+		c.err("Redundancy priority is given without redundancy protocol in %s",
+			vCtx)
+	}
 	return virtual
 }
 
@@ -3108,18 +3176,29 @@ func (c *spoc) checkVxIP(ip netip.Addr, v6 bool, name, ctx string) {
 // Check if given date has been reached already.
 var dateRegex = regexp.MustCompile(`^(\d\d\d\d-\d\d-\d\d)$`)
 
-func (c *spoc) dateIsReached(s, ctx string) bool {
+func dateIsReached(date time.Time) bool {
+	return time.Now().After(date)
+}
+
+// Get number of days from today until given date.
+func daysUntil(date time.Time) int {
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	return int(date.Sub(today).Hours()+12) / 24
+}
+
+func (c *spoc) parseDate(s, ctx string) (time.Time, bool) {
 	l := dateRegex.FindStringSubmatch(s)
 	if l == nil {
 		c.err("Date expected as yyyy-mm-dd in %s", ctx)
-		return false
+		return time.Time{}, false
 	}
 	date, err := time.ParseInLocation("2006-01-02", s, time.Local)
 	if err != nil {
 		c.err("Invalid date in %s: %v", ctx, err)
-		return false
+		return time.Time{}, false
 	}
-	return time.Now().After(date)
+	return date, true
 }
 
 func (c *spoc) getNetworkRef(a *ast.Attribute, ctx string) *network {
@@ -4045,6 +4124,7 @@ func (c *spoc) moveLockedIntf(intf *routerIntf) {
 // Link all virtual interfaces to the group of member interfaces.
 // Check consistency:
 // - Member interfaces must use identical protocol and identical ID.
+// - Member interfaces must use different priority.
 // - The same ID must not be used by some other group
 //   - connected to the same network
 //   - emploing the same redundancy type
@@ -4082,6 +4162,16 @@ func (c *spoc) linkVirtualInterfaces() {
 						" - %s\n"+
 						" - %s", v2, v1)
 				}
+				if p1 := v1.redundancyPrio; p1 != "" {
+					for _, v2 := range l {
+						if v2.redundancyPrio == p1 {
+							c.err("Must use different priority at\n"+
+								" - %s\n"+
+								" - %s", v2, v1)
+							break
+						}
+					}
+				}
 			} else if id1 != "" {
 				// Check for identical ID used at unrelated virtual
 				// interfaces inside current network.
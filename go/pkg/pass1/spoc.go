@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/netip"
 	"path"
+	"runtime/debug"
 	"slices"
 	"time"
 
@@ -28,6 +29,9 @@ type spoc struct {
 	messages        stringList
 	aborted         bool
 	showDiag        bool
+	concurrent      bool
+	prof            *profiler
+	autofix         *autofix
 	// State of compiler
 	symTable              *symbolTable
 	userObj               userInfo
@@ -70,6 +74,9 @@ func initSpoc(d oslink.Data, cnf *conf.Config) *spoc {
 			hasOtherSubnet: true,
 		},
 	}
+	if cnf.Autofix != "" {
+		c.autofix = new(autofix)
+	}
 	return c
 }
 
@@ -137,6 +144,7 @@ func (c *spoc) warnOrErr(
 func (c *spoc) uselessSvcAttr(attr string, svc *service) {
 	if errType := c.conf.CheckServiceUselessAttribute; errType != "" {
 		c.warnOrErr(errType, "Useless '%s' at %s", attr, svc)
+		c.fixUselessSvcAttr(attr, svc)
 	}
 }
 
@@ -214,50 +222,74 @@ func SpocMain(d oslink.Data) int {
 		return 1
 	}
 	return toplevelSpoc(d, cnf, func(c *spoc) {
+		defer c.finishProfile()
+		if l := c.conf.MemoryLimit; l > 0 {
+			// Set soft limit for garbage collector; restore old limit.
+			defer debug.SetMemoryLimit(debug.SetMemoryLimit(int64(l) << 20))
+		}
 		if device := c.conf.DebugPass2; device != "" {
-			pass2.File(device, outDir, path.Join(outDir, ".prev"))
+			c.startProfile("pass2")
+			c.phase("pass2", func() {
+				pass2.File(device, outDir, path.Join(outDir, ".prev"))
+			})
 			return
 		}
+		c.startProfile("pass1")
 		c.info("%s, version %s", program, version)
-		c.readNetspoc(inDir)
+		c.phase("readNetspoc", func() { c.readNetspoc(inDir) })
 		c.showReadStatistics()
-		c.orderProtocols()
-		c.checkIPAddresses()
-		c.setZone()
-		c.setPath()
-		NATDomains, _ := c.distributeNatInfo()
-		sRules := c.normalizeServices()
+		c.phase("orderProtocols", c.orderProtocols)
+		c.phase("checkIPAddresses", c.checkIPAddresses)
+		c.phase("setZone", func() { c.setZone() })
+		c.phase("setPath", c.setPath)
+		var NATDomains []*natDomain
+		c.phase("distributeNatInfo", func() {
+			NATDomains, _ = c.distributeNatInfo()
+		})
+		var sRules *serviceRules
+		c.phase("normalizeServices", func() { sRules = c.normalizeServices() })
 		c.stopOnErr()
-		pRules, dRules := c.convertHostsInRules(sRules)
-		c.groupPathRules(pRules, dRules)
+		var pRules, dRules ruleList
+		c.phase("convertHostsInRules", func() {
+			pRules, dRules = c.convertHostsInRules(sRules)
+		})
+		c.phase("groupPathRules", func() { c.groupPathRules(pRules, dRules) })
 
 		c.startWithBackground(
 			func(c *spoc) {
-				c.findSubnetsInNatDomain(NATDomains)
-				c.checkUnstableNatRules()
-				c.markManagedLocal()
-				c.checkDynamicNatRules()
-				c.checkSupernetRules(pRules)
+				c.phase("findSubnetsInNatDomain", func() {
+					c.findSubnetsInNatDomain(NATDomains)
+				})
+				c.phase("checkUnstableNatRules", c.checkUnstableNatRules)
+				c.phase("markManagedLocal", c.markManagedLocal)
+				c.phase("checkDynamicNatRules", c.checkDynamicNatRules)
+				c.phase("checkSupernetRules", func() {
+					c.checkSupernetRules(pRules)
+				})
 			},
 			func(c *spoc) {
-				c.checkServiceOwner(sRules)
-				c.checkIdenticalServices(sRules)
-				c.checkUnused()
-				c.checkRedundantRules()
+				c.phase("checkServiceOwner", func() {
+					c.checkServiceOwner(sRules)
+				})
+				c.phase("checkIdenticalServices", func() {
+					c.checkIdenticalServices(sRules)
+				})
+				c.phase("checkUnused", c.checkUnused)
+				c.phase("checkRedundantRules", c.checkRedundantRules)
 			})
 
-		c.removeSimpleDuplicateRules()
-		c.combineSubnetsInRules()
-		c.setPolicyDistributionIP()
-		c.expandCrypto()
-		c.findActiveRoutes()
-		c.genReverseRules()
+		c.phase("removeSimpleDuplicateRules", c.removeSimpleDuplicateRules)
+		c.phase("combineSubnetsInRules", c.combineSubnetsInRules)
+		c.phase("setPolicyDistributionIP", c.setPolicyDistributionIP)
+		c.phase("expandCrypto", c.expandCrypto)
+		c.phase("findActiveRoutes", c.findActiveRoutes)
+		c.phase("genReverseRules", c.genReverseRules)
 		if outDir != "" {
-			c.markSecondaryRules()
-			c.rulesDistribution()
-			c.printCode(outDir)
-			c.copyRaw(inDir, outDir)
+			c.phase("markSecondaryRules", c.markSecondaryRules)
+			c.phase("rulesDistribution", c.rulesDistribution)
+			c.printCode(inDir, outDir)
 		}
+		c.writeAutofix(d.Stdout)
 		c.stopOnErr()
 		c.progress("Finished")
 	})
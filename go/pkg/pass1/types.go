@@ -5,6 +5,7 @@ import (
 	"net/netip"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/hknutzen/Netspoc/go/pkg/ast"
 
@@ -19,11 +20,18 @@ func (a *stringerList[E]) push(e *E) {
 
 func (l stringerList[E]) sortByName() stringerList[E] {
 	slices.SortFunc(l, func(a, b *E) int {
-		return strings.Compare((*a).String(), (*b).String())
+		return strings.Compare(nameOf(a), nameOf(b))
 	})
 	return l
 }
 
+// Get name by pointer. Method String has value receiver and would
+// copy element, which conflicts with concurrent change of some other
+// attribute. Hence use method getName with pointer receiver.
+func nameOf[E fmt.Stringer](x *E) string {
+	return any(x).(interface{ getName() string }).getName()
+}
+
 type intfList = stringerList[routerIntf]
 
 type stringList []string
@@ -136,7 +144,8 @@ type ipObj struct {
 	name string
 }
 
-func (x ipObj) String() string { return x.name }
+func (x ipObj) String() string   { return x.name }
+func (x *ipObj) getName() string { return x.name }
 
 type natTagMap map[string]*network
 type natObj struct {
@@ -355,6 +364,7 @@ type router struct {
 }
 
 func (x router) String() string     { return x.name }
+func (x *router) getName() string   { return x.name }
 func (x router) isRouter() bool     { return true }
 func (x router) isCombined46() bool { return x.combined46 != nil }
 func (x router) vxName() string {
@@ -401,6 +411,7 @@ type routerIntf struct {
 	realIntf        *routerIntf
 	redundancyId    string
 	redundancyIntfs intfList
+	redundancyPrio  string
 	secondaryIntfs  intfList
 	redundancyType  *mcastProto
 	redundant       bool
@@ -504,7 +515,8 @@ type ipsec struct {
 	pfsGroup          string
 }
 
-func (x ipsec) String() string { return x.name }
+func (x ipsec) String() string   { return x.name }
+func (x *ipsec) getName() string { return x.name }
 
 type isakmp struct {
 	name           string
@@ -518,6 +530,13 @@ type isakmp struct {
 	natTraversal   string
 }
 
+// Key of cache in zone.ipPrefix2net.
+type zoneNetKey struct {
+	ipp       netip.Prefix
+	isAgg     bool
+	natDomain *natDomain
+}
+
 type zone struct {
 	ipVxObj
 	pathStoreData
@@ -529,7 +548,7 @@ type zone struct {
 	hasNonPrimary        bool
 	inArea               *area
 	ipPrefix2aggregate   map[netip.Prefix]*network
-	ipPrefix2net         map[netip.Prefix]netList
+	ipPrefix2net         map[zoneNetKey]netList
 	natDomain            *natDomain
 	noCheckSupernetRules bool
 	partition            string
@@ -576,7 +595,8 @@ type area struct {
 	zones               []*zone
 }
 
-func (x area) String() string { return x.name }
+func (x area) String() string   { return x.name }
+func (x *area) getName() string { return x.name }
 func (x area) vxName() string {
 	return vxName(x.name, x.ipV6, x.combined46 != nil)
 }
@@ -614,7 +634,6 @@ type proto struct {
 	established   bool
 	statelessICMP bool
 	up            *proto
-	localUp       *proto
 }
 type protoList []*proto
 
@@ -645,7 +664,10 @@ type service struct {
 	name             string
 	description      string
 	disableAt        string
+	disableDate      time.Time
 	disabled         bool
+	enableAt         string
+	enableDate       time.Time
 	foreach          bool
 	rules            []*unexpRule
 	ruleCount        int
@@ -704,6 +726,9 @@ type groupedRule struct {
 	dstPath          pathStore
 	someNonSecondary bool
 	somePrimary      bool
+	// Services of duplicate rules, that have been removed.
+	// Only collected with option --service_map.
+	dupServices []*service
 }
 type ruleList []*groupedRule
 
@@ -0,0 +1,650 @@
+package pass2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/fileop"
+	"github.com/hknutzen/Netspoc/go/pkg/jcode"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/spf13/pflag"
+)
+
+// Restriction of matched packets, derived from a single line of
+// a hit count dump.
+// Zero value of prefix or nil protocol matches everything.
+type hitMatch struct {
+	src netip.Prefix
+	dst netip.Prefix
+	prt *proto
+}
+
+// Entry of ACL with hit count > 0.
+// For iptables, an entry is reached by a sequence of jumps.
+// Hence it has a list of restrictions, that all must be matched.
+type hitEntry struct {
+	deny    bool
+	matches []hitMatch
+}
+
+// Maps name of ACL to entries with hit count > 0.
+type aclHits map[string][]*hitEntry
+
+func CheckHitcountMain(d oslink.Data) int {
+	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
+
+	// Setup custom usage function.
+	fs.Usage = func() {
+		fmt.Fprintf(d.Stderr,
+			"Usage: %s [options] CODE-DIR DUMP-DIR\n%s",
+			d.Args[0], fs.FlagUsages())
+	}
+
+	// Command line flags
+	quiet := fs.BoolP("quiet", "q", false, "Don't print progress messages")
+	if err := fs.Parse(d.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 1
+		}
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		fs.Usage()
+		return 1
+	}
+
+	// Argument processing
+	args := fs.Args()
+	if len(args) != 2 {
+		fs.Usage()
+		return 1
+	}
+	codeDir, dumpDir := args[0], args[1]
+	for _, dir := range args {
+		if !fileop.IsDir(dir) {
+			fmt.Fprintf(d.Stderr, "Error: Can't find directory %s\n", dir)
+			return 1
+		}
+	}
+	dead, err := findDeadServices(d.Stderr, codeDir, dumpDir, *quiet)
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	for _, name := range dead {
+		fmt.Fprintln(d.Stdout, name)
+	}
+	return 0
+}
+
+// Find services, that have rules on some device and
+// where no rule was hit on any device.
+// Services having rules on some device without dump file
+// are never reported.
+func findDeadServices(
+	stderr io.Writer, codeDir, dumpDir string, quiet bool,
+) ([]string, error) {
+
+	warn := func(format string, args ...any) {
+		fmt.Fprintf(stderr, "Warning: "+format+"\n", args...)
+	}
+	info := func(format string, args ...any) {
+		if !quiet {
+			fmt.Fprintf(stderr, format+"\n", args...)
+		}
+	}
+	var devices []string
+	for _, pattern := range []string{"*.rules", "ipv6/*.rules"} {
+		files, _ := filepath.Glob(filepath.Join(codeDir, pattern))
+		for _, f := range files {
+			rel, _ := filepath.Rel(codeDir, f)
+			devices = append(devices, strings.TrimSuffix(rel, ".rules"))
+		}
+	}
+	used := make(map[string]bool)
+	unknown := make(map[string]bool)
+	seen := make(map[string]bool)
+	checked := 0
+	for _, device := range devices {
+		jData, err := readRulesFile(filepath.Join(codeDir, device+".rules"))
+		if err != nil {
+			return nil, err
+		}
+		dumpFile := filepath.Join(dumpDir, device)
+		var hits aclHits
+		if fileop.IsRegular(dumpFile) {
+			data, err := os.ReadFile(dumpFile)
+			if err != nil {
+				return nil, fmt.Errorf("Can't %v", err)
+			}
+			switch jData.Model {
+			case "ASA":
+				hits = parseASAHits(string(data))
+			case "Linux":
+				hits = parseIptablesHits(string(data))
+			default:
+				warn("Ignoring %s with unsupported model %s",
+					dumpFile, jData.Model)
+			}
+		}
+		if hits != nil {
+			checked++
+		}
+		for _, acl := range jData.ACLs {
+			entries := hits[acl.Name]
+			for _, rules := range [][]*jcode.Rule{acl.IntfRules, acl.Rules} {
+				for _, rule := range rules {
+					if rule.Services == nil {
+						continue
+					}
+					isHit := hits == nil || slices.ContainsFunc(entries,
+						func(e *hitEntry) bool { return e.matchRule(rule) })
+					for _, name := range rule.Services {
+						seen[name] = true
+						if hits == nil {
+							unknown[name] = true
+						} else if isHit {
+							used[name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(seen) == 0 && len(devices) != 0 {
+		return nil, fmt.Errorf(
+			"Missing service names in %s; use option --service_map of Netspoc",
+			codeDir)
+	}
+	info("Checked hit counts of %d devices", checked)
+	var result []string
+	for name := range seen {
+		if !used[name] && !unknown[name] {
+			result = append(result, name)
+		}
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+func readRulesFile(path string) (*jcode.RouterData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Can't %v", err)
+	}
+	jData := new(jcode.RouterData)
+	if err := json.Unmarshal(data, jData); err != nil {
+		return nil, fmt.Errorf("Can't parse %s: %v", path, err)
+	}
+	return jData, nil
+}
+
+// Check if rule from intermediate code overlaps with hit entry.
+func (e *hitEntry) matchRule(rule *jcode.Rule) bool {
+	if e.deny != rule.Deny {
+		return false
+	}
+	var srcPort *proto
+	if rule.SrcRange != "" {
+		srcPort = createPrtObj(rule.SrcRange)
+	}
+	for _, m := range e.matches {
+		if !overlapsSome(m.src, rule.Src) || !overlapsSome(m.dst, rule.Dst) {
+			return false
+		}
+		if m.prt != nil && !slices.ContainsFunc(rule.Prt, func(name string) bool {
+			return m.prt.overlapsPrt(createPrtObj(name), srcPort)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func overlapsSome(p netip.Prefix, l []string) bool {
+	if !p.IsValid() {
+		return true
+	}
+	for _, name := range l {
+		if n, err := netip.ParsePrefix(name); err == nil && n.Overlaps(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Protocol of hit entry may additionally restrict source port.
+// Source port is stored in attribute 'up' of destination protocol.
+func (p *proto) overlapsPrt(other, srcPort *proto) bool {
+	if p.protocol == "ip" || other.protocol == "ip" {
+		return true
+	}
+	if p.protocol != other.protocol {
+		return false
+	}
+	switch p.protocol {
+	case "tcp", "udp":
+		if !rangesOverlap(p.ports, other.ports) {
+			return false
+		}
+		if sp := p.up; sp != nil && srcPort != nil {
+			return rangesOverlap(sp.ports, srcPort.ports)
+		}
+	case "icmp":
+		if p.icmpType != -1 && other.icmpType != -1 {
+			if p.icmpType != other.icmpType {
+				return false
+			}
+			if p.icmpCode != -1 && other.icmpCode != -1 {
+				return p.icmpCode == other.icmpCode
+			}
+		}
+	}
+	return true
+}
+
+func rangesOverlap(a, b [2]int) bool {
+	return a[0] <= b[1] && b[0] <= a[1]
+}
+
+// Port and protocol names used in output of Cisco ASA and iptables.
+var portNames = map[string]int{
+	"bgp": 179, "bootpc": 68, "bootps": 67, "domain": 53, "ftp": 21,
+	"ftp-data": 20, "http": 80, "https": 443, "imap4": 143, "isakmp": 500,
+	"kerberos": 88, "ldap": 389, "ldaps": 636, "netbios-dgm": 138,
+	"netbios-ns": 137, "netbios-ssn": 139, "nfs": 2049, "ntp": 123,
+	"pop3": 110, "radius": 1645, "radius-acct": 1646, "rsh": 514,
+	"sip": 5060, "smtp": 25, "snmp": 161, "snmptrap": 162, "sqlnet": 1521,
+	"ssh": 22, "sunrpc": 111, "syslog": 514, "tacacs": 49, "telnet": 23,
+	"tftp": 69, "www": 80,
+}
+
+var protoNames = map[string]string{
+	"ah": "51", "eigrp": "88", "esp": "50", "gre": "47", "igmp": "2",
+	"ospf": "89", "pim": "103", "vrrp": "112",
+}
+
+var icmpNames = map[string]int{
+	"echo-reply": 0, "unreachable": 3, "source-quench": 4, "redirect": 5,
+	"echo": 8, "router-advertisement": 9, "router-solicitation": 10,
+	"time-exceeded": 11, "parameter-problem": 12, "timestamp-request": 13,
+	"timestamp-reply": 14, "mask-request": 17, "mask-reply": 18,
+}
+
+func parsePort(s string) (int, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, true
+	}
+	n, found := portNames[s]
+	return n, found
+}
+
+func protocolFromName(name string) *proto {
+	switch name {
+	case "ip", "all", "0":
+		return &proto{protocol: "ip"}
+	case "tcp", "udp":
+		return &proto{protocol: name, ports: [2]int{1, 65535}}
+	case "icmp", "icmp6", "ipv6-icmp", "icmpv6":
+		return &proto{protocol: "icmp", icmpType: -1, icmpCode: -1}
+	}
+	if num, found := protoNames[name]; found {
+		name = num
+	}
+	if _, err := strconv.Atoi(name); err != nil {
+		return nil
+	}
+	return &proto{protocol: name}
+}
+
+var asaLine = regexp.MustCompile(
+	`^\s*access-list (\S+) line \d+ extended (permit|deny) (.*) ` +
+		`\(hitcnt=(\d+)\)`)
+
+// Parse output of command "show access-list" of Cisco ASA.
+// Lines referencing object-groups are ignored, because
+// ASA additionally shows each expanded line with its own hit count.
+func parseASAHits(data string) aclHits {
+	result := make(aclHits)
+	for _, line := range strings.Split(data, "\n") {
+		m := asaLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if cnt, _ := strconv.Atoi(m[4]); cnt == 0 {
+			continue
+		}
+		words := strings.Fields(m[3])
+		if slices.Contains(words, "object-group") ||
+			slices.Contains(words, "object") {
+			continue
+		}
+		match, ok := parseASAMatch(words)
+		if !ok {
+			continue
+		}
+		e := &hitEntry{deny: m[2] == "deny", matches: []hitMatch{match}}
+		result[m[1]] = append(result[m[1]], e)
+	}
+	return result
+}
+
+// Get prefix length from network mask.
+func maskBits(mask netip.Addr) int {
+	bits := 0
+	for _, b := range mask.AsSlice() {
+		for ; b&0x80 != 0; b <<= 1 {
+			bits++
+		}
+	}
+	return bits
+}
+
+func parseASAMatch(words []string) (hitMatch, bool) {
+	var m hitMatch
+	if len(words) == 0 {
+		return m, false
+	}
+	prt := protocolFromName(words[0])
+	words = words[1:]
+	addr := func() (netip.Prefix, bool) {
+		if len(words) == 0 {
+			return netip.Prefix{}, false
+		}
+		w := words[0]
+		words = words[1:]
+		switch w {
+		case "any", "any4", "any6":
+			return netip.Prefix{}, true
+		case "interface":
+			if len(words) > 0 {
+				words = words[1:]
+			}
+			return netip.Prefix{}, true
+		case "host":
+			if len(words) == 0 {
+				return netip.Prefix{}, false
+			}
+			ip, err := netip.ParseAddr(words[0])
+			words = words[1:]
+			if err != nil {
+				return netip.Prefix{}, false
+			}
+			return netip.PrefixFrom(ip, ip.BitLen()), true
+		}
+		if strings.Contains(w, "/") {
+			p, err := netip.ParsePrefix(w)
+			return p, err == nil
+		}
+		if len(words) == 0 {
+			return netip.Prefix{}, false
+		}
+		ip, err1 := netip.ParseAddr(w)
+		mask, err2 := netip.ParseAddr(words[0])
+		words = words[1:]
+		if err1 != nil || err2 != nil {
+			return netip.Prefix{}, false
+		}
+		return netip.PrefixFrom(ip, maskBits(mask)), true
+	}
+	ports := func() ([2]int, bool) {
+		all := [2]int{1, 65535}
+		if len(words) == 0 {
+			return all, false
+		}
+		switch words[0] {
+		case "eq", "lt", "gt", "neq":
+			if len(words) < 2 {
+				return all, false
+			}
+			op := words[0]
+			p, ok := parsePort(words[1])
+			words = words[2:]
+			if !ok {
+				return all, true
+			}
+			switch op {
+			case "eq":
+				return [2]int{p, p}, true
+			case "lt":
+				return [2]int{1, p - 1}, true
+			case "gt":
+				return [2]int{p + 1, 65535}, true
+			}
+			return all, true
+		case "range":
+			if len(words) < 3 {
+				return all, false
+			}
+			p1, ok1 := parsePort(words[1])
+			p2, ok2 := parsePort(words[2])
+			words = words[3:]
+			if !ok1 || !ok2 {
+				return all, true
+			}
+			return [2]int{p1, p2}, true
+		}
+		return all, false
+	}
+	var ok bool
+	if m.src, ok = addr(); !ok {
+		return m, false
+	}
+	var srcPorts [2]int
+	hasSrcPorts := false
+	if prt != nil && (prt.protocol == "tcp" || prt.protocol == "udp") {
+		srcPorts, hasSrcPorts = ports()
+	}
+	if m.dst, ok = addr(); !ok {
+		return m, false
+	}
+	if prt != nil {
+		switch prt.protocol {
+		case "tcp", "udp":
+			prt.ports, _ = ports()
+			if hasSrcPorts {
+				prt.up = &proto{protocol: prt.protocol, ports: srcPorts}
+			}
+		case "icmp":
+			if len(words) > 0 {
+				if t, err := strconv.Atoi(words[0]); err == nil {
+					prt.icmpType = t
+				} else if t, found := icmpNames[words[0]]; found {
+					prt.icmpType = t
+				}
+				if len(words) > 1 && prt.icmpType != -1 {
+					if c, err := strconv.Atoi(words[1]); err == nil {
+						prt.icmpCode = c
+					}
+				}
+			}
+		}
+	}
+	m.prt = prt
+	return m, true
+}
+
+// Find start position of column name in header line.
+func headerColumn(line, name string) int {
+	for i, w := range strings.Fields(line) {
+		if w == name {
+			return fieldStarts(line)[i]
+		}
+	}
+	return -1
+}
+
+// Get start positions of fields separated by white space.
+func fieldStarts(line string) []int {
+	var result []int
+	inField := false
+	for i, ch := range line {
+		isSpace := ch == ' ' || ch == '\t'
+		if !isSpace && !inField {
+			result = append(result, i)
+		}
+		inField = !isSpace
+	}
+	return result
+}
+
+type iptablesRule struct {
+	pkts   int
+	target string
+	match  hitMatch
+}
+
+// Parse output of command "iptables -L -v -x -n".
+// Rules reached by jumps into sub-chains are combined with
+// the restrictions of the jumping rules.
+func parseIptablesHits(data string) aclHits {
+	chains := make(map[string][]*iptablesRule)
+	var chain string
+	// Position of columns "prot" and "in" in header line.
+	protCol, inCol := -1, -1
+	for _, line := range strings.Split(data, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		if words[0] == "Chain" && len(words) >= 2 {
+			chain = words[1]
+			chains[chain] = nil
+			continue
+		}
+		if words[0] == "pkts" {
+			protCol = headerColumn(line, "prot")
+			inCol = headerColumn(line, "in")
+			continue
+		}
+		pkts, err := strconv.Atoi(words[0])
+		if err != nil || chain == "" || len(words) < 8 {
+			continue
+		}
+		// Column "opt" is empty in output of ip6tables.
+		// Hence check if fifth field starts before column "in".
+		// Positions are taken relative to column "prot", because
+		// preceding columns are shifted by large counters or long
+		// names of targets.
+		starts := fieldStarts(line)
+		hasOpt := true
+		if protCol != -1 && inCol != -1 {
+			shift := starts[3] - protCol
+			hasOpt = starts[4] < inCol+shift
+		}
+		words = words[2:]
+		r := &iptablesRule{pkts: pkts, target: words[0]}
+		prt := protocolFromName(words[1])
+		words = words[2:]
+		if hasOpt {
+			words = words[1:]
+		}
+		if len(words) < 4 {
+			continue
+		}
+		// Ignore columns "in" and "out".
+		r.match.src = parseIptablesAddr(words[2])
+		r.match.dst = parseIptablesAddr(words[3])
+		if prt != nil {
+			for _, w := range words[4:] {
+				key, val, _ := strings.Cut(w, ":")
+				switch key {
+				case "dpt", "dpts":
+					prt.ports = parseIptablesPorts(val)
+				case "spt", "spts":
+					prt.up = &proto{protocol: prt.protocol,
+						ports: parseIptablesPorts(val)}
+				}
+			}
+			if prt.protocol == "icmp" {
+				setIptablesIcmp(prt, words[4:])
+			}
+		}
+		r.match.prt = prt
+		chains[chain] = append(chains[chain], r)
+	}
+	result := make(aclHits)
+	var walk func(acl string, l []*iptablesRule, matches []hitMatch, depth int)
+	walk = func(acl string, l []*iptablesRule, matches []hitMatch, depth int) {
+		for _, r := range l {
+			if r.pkts == 0 {
+				continue
+			}
+			ml := append(slices.Clip(matches), r.match)
+			switch r.target {
+			case "ACCEPT":
+				result[acl] = append(result[acl], &hitEntry{matches: ml})
+			case "droplog", "DROP":
+				result[acl] =
+					append(result[acl], &hitEntry{deny: true, matches: ml})
+			default:
+				// Sub-chains of Netspoc are never nested deeply.
+				if sub, found := chains[r.target]; found && depth < 20 {
+					walk(acl, sub, ml, depth+1)
+				}
+			}
+		}
+	}
+	for name, l := range chains {
+		walk(name, l, nil, 0)
+	}
+	return result
+}
+
+func parseIptablesAddr(s string) netip.Prefix {
+	if p, err := netip.ParsePrefix(s); err == nil {
+		if p.Bits() == 0 {
+			return netip.Prefix{}
+		}
+		return p
+	}
+	if ip, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(ip, ip.BitLen())
+	}
+	return netip.Prefix{}
+}
+
+func parseIptablesPorts(s string) [2]int {
+	lo, hi, found := strings.Cut(s, ":")
+	p1, err1 := strconv.Atoi(lo)
+	if !found {
+		if err1 != nil {
+			return [2]int{1, 65535}
+		}
+		return [2]int{p1, p1}
+	}
+	p2, err2 := strconv.Atoi(hi)
+	if err1 != nil {
+		p1 = 1
+	}
+	if err2 != nil {
+		p2 = 65535
+	}
+	return [2]int{p1, p2}
+}
+
+// Handle "icmptype 3 code 13", "icmp type 3 code 13"
+// and "ipv6-icmptype 1 code 4".
+func setIptablesIcmp(prt *proto, words []string) {
+	for i, w := range words {
+		if i+1 == len(words) {
+			break
+		}
+		switch w {
+		case "icmptype", "type", "ipv6-icmptype":
+			if t, err := strconv.Atoi(words[i+1]); err == nil {
+				prt.icmpType = t
+			}
+		case "code":
+			if c, err := strconv.Atoi(words[i+1]); err == nil {
+				prt.icmpCode = c
+			}
+		}
+	}
+}
@@ -0,0 +1,461 @@
+package pass2
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hknutzen/Netspoc/go/pkg/fileop"
+	"github.com/hknutzen/Netspoc/go/pkg/oslink"
+	"github.com/spf13/pflag"
+)
+
+// Route of routing table, identified by VRF and prefix.
+type routeKey struct {
+	vrf    string
+	prefix netip.Prefix
+}
+
+// Next hops of a route and if route is static.
+type routeEntry struct {
+	hops   []string
+	static bool
+}
+
+type routeTable map[routeKey]*routeEntry
+
+func (t routeTable) add(k routeKey, hop string, static bool) {
+	e := t[k]
+	if e == nil {
+		e = &routeEntry{static: static}
+		t[k] = e
+	}
+	if !slices.Contains(e.hops, hop) {
+		e.hops = append(e.hops, hop)
+	}
+}
+
+func CheckRoutesMain(d oslink.Data) int {
+	fs := pflag.NewFlagSet(d.Args[0], pflag.ContinueOnError)
+
+	// Setup custom usage function.
+	fs.Usage = func() {
+		fmt.Fprintf(d.Stderr,
+			"Usage: %s [options] CODE-DIR DUMP-DIR\n%s",
+			d.Args[0], fs.FlagUsages())
+	}
+
+	// Command line flags
+	quiet := fs.BoolP("quiet", "q", false, "Don't print progress messages")
+	if err := fs.Parse(d.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return 1
+		}
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		fs.Usage()
+		return 1
+	}
+
+	// Argument processing
+	args := fs.Args()
+	if len(args) != 2 {
+		fs.Usage()
+		return 1
+	}
+	codeDir, dumpDir := args[0], args[1]
+	for _, dir := range args {
+		if !fileop.IsDir(dir) {
+			fmt.Fprintf(d.Stderr, "Error: Can't find directory %s\n", dir)
+			return 1
+		}
+	}
+	err := checkRoutes(d.Stdout, d.Stderr, codeDir, dumpDir, *quiet)
+	if err != nil {
+		fmt.Fprintf(d.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// Compare routes generated by Netspoc with routing table of each
+// device, where a dump file is available.
+// Differences are printed to stdout.
+func checkRoutes(
+	stdout, stderr io.Writer, codeDir, dumpDir string, quiet bool,
+) error {
+
+	warn := func(format string, args ...any) {
+		fmt.Fprintf(stderr, "Warning: "+format+"\n", args...)
+	}
+	info := func(format string, args ...any) {
+		if !quiet {
+			fmt.Fprintf(stderr, format+"\n", args...)
+		}
+	}
+	var devices []string
+	for _, pattern := range []string{"*.rules", "ipv6/*.rules"} {
+		files, _ := filepath.Glob(filepath.Join(codeDir, pattern))
+		for _, f := range files {
+			rel, _ := filepath.Rel(codeDir, f)
+			devices = append(devices, strings.TrimSuffix(rel, ".rules"))
+		}
+	}
+	checked := 0
+	for _, device := range devices {
+		dumpFile := filepath.Join(dumpDir, device)
+		if !fileop.IsRegular(dumpFile) {
+			continue
+		}
+		jData, err := readRulesFile(filepath.Join(codeDir, device+".rules"))
+		if err != nil {
+			return err
+		}
+		var parse func(string) routeTable
+		switch jData.Model {
+		case "IOS", "ASA":
+			parse = parseCiscoRoutes
+		case "Linux":
+			parse = parseIPRoutes
+		default:
+			warn("Ignoring routing table of %s with unsupported model %s",
+				device, jData.Model)
+			continue
+		}
+		code, err := os.ReadFile(filepath.Join(codeDir, device))
+		if err != nil {
+			return fmt.Errorf("Can't %v", err)
+		}
+		dump, err := os.ReadFile(dumpFile)
+		if err != nil {
+			return fmt.Errorf("Can't %v", err)
+		}
+		checked++
+		compareRoutes(stdout, device,
+			parseGeneratedRoutes(string(code)), parse(string(dump)))
+	}
+	info("Checked routes of %d devices", checked)
+	return nil
+}
+
+func compareRoutes(w io.Writer, device string, expected, found routeTable) {
+	keys := slices.Collect(maps.Keys(expected))
+	for k, e := range found {
+		if e.static && expected[k] == nil {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b routeKey) int {
+		if a.vrf != b.vrf {
+			return strings.Compare(a.vrf, b.vrf)
+		}
+		if c := a.prefix.Addr().Compare(b.prefix.Addr()); c != 0 {
+			return c
+		}
+		return a.prefix.Bits() - b.prefix.Bits()
+	})
+	for _, k := range keys {
+		name := k.prefix.String()
+		if k.vrf != "" {
+			name = "vrf " + k.vrf + " " + name
+		}
+		exp, got := expected[k], found[k]
+		switch {
+		case exp == nil:
+			fmt.Fprintf(w, "%s: extra route %s via %s\n",
+				device, name, strings.Join(got.hops, ", "))
+		case got == nil:
+			fmt.Fprintf(w, "%s: missing route %s via %s\n",
+				device, name, strings.Join(exp.hops, ", "))
+		case !slices.Equal(slices.Sorted(slices.Values(exp.hops)),
+			slices.Sorted(slices.Values(got.hops))):
+			fmt.Fprintf(w, "%s: conflicting next hop for %s: %s, expected %s\n",
+				device, name, strings.Join(got.hops, ", "),
+				strings.Join(exp.hops, ", "))
+		}
+	}
+}
+
+// Parse routes from section "[ Routing ]" of generated code.
+func parseGeneratedRoutes(code string) routeTable {
+	t := make(routeTable)
+	inRouting := false
+	// VRF of section "vrf VRF" ... "exit-vrf" in configuration of FRR.
+	frrVRF := ""
+	scanner := bufio.NewScanner(strings.NewReader(code))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, "[ ") && strings.HasSuffix(line, " ]") {
+			inRouting = strings.Contains(line, "[ Routing")
+			continue
+		}
+		if !inRouting {
+			continue
+		}
+		words := strings.Fields(line)
+		switch {
+		case len(words) == 2 && words[0] == "vrf":
+			frrVRF = words[1]
+			continue
+		case len(words) == 1 && words[0] == "exit-vrf":
+			frrVRF = ""
+			continue
+		}
+		vrf := frrVRF
+		var pWords []string
+		var hop string
+		switch {
+		// ip route add PREFIX via HOP
+		case len(words) == 6 && words[2] == "add" && words[4] == "via":
+			pWords, hop = words[3:4], words[5]
+		// route add PREFIX via HOP [vrf VRF]
+		// of "ip -batch"
+		case len(words) >= 5 && words[0] == "route" && words[1] == "add" &&
+			words[3] == "via":
+			pWords, hop = words[2:3], words[4]
+			if len(words) == 7 && words[5] == "vrf" {
+				vrf = words[6]
+			}
+		// ip route [vrf VRF] ADDR MASK HOP
+		// ipv6 route [vrf VRF] PREFIX HOP
+		// route IFACE ADDR MASK HOP
+		// ipv6 route IFACE PREFIX HOP
+		default:
+			for len(words) > 0 && words[0] != "route" {
+				words = words[1:]
+			}
+			if len(words) < 3 {
+				continue
+			}
+			words = words[1:]
+			if words[0] == "vrf" && len(words) > 2 {
+				vrf = words[1]
+				words = words[2:]
+			}
+			pWords, hop = words[:len(words)-1], words[len(words)-1]
+			if !isAddrOrPrefix(pWords[0]) {
+				// Skip name of interface of ASA.
+				pWords = pWords[1:]
+			}
+		}
+		if p, ok := parseRoutePrefix(pWords); ok {
+			t.add(routeKey{vrf, p}, hop, true)
+		}
+	}
+	return t
+}
+
+func isAddrOrPrefix(s string) bool {
+	_, err1 := netip.ParseAddr(s)
+	_, err2 := netip.ParsePrefix(s)
+	return err1 == nil || err2 == nil
+}
+
+// Parse prefix, given as PREFIX or as ADDR MASK.
+// Single address without prefix length is taken as host route.
+func parseRoutePrefix(words []string) (netip.Prefix, bool) {
+	switch len(words) {
+	case 1:
+		if ip, err := netip.ParseAddr(words[0]); err == nil {
+			return netip.PrefixFrom(ip, ip.BitLen()), true
+		}
+		p, err := netip.ParsePrefix(words[0])
+		return p.Masked(), err == nil
+	case 2:
+		ip, err1 := netip.ParseAddr(words[0])
+		mask, err2 := netip.ParseAddr(words[1])
+		if err1 != nil || err2 != nil {
+			return netip.Prefix{}, false
+		}
+		return netip.PrefixFrom(ip, maskBits(mask)).Masked(), true
+	}
+	return netip.Prefix{}, false
+}
+
+// Parse output of "show ip route" and "show ipv6 route" of IOS
+// and of "show route" and "show ipv6 route" of ASA.
+// Routes of VRF are recognized from header "Routing Table: VRF".
+func parseCiscoRoutes(data string) routeTable {
+	t := make(routeTable)
+	vrf := ""
+	defaultBits := -1
+	var lastKey routeKey
+	var lastStatic, haveLast bool
+	addHop := func(words []string) {
+		for i, w := range words {
+			if w == "via" && i+1 < len(words) {
+				hop := strings.TrimSuffix(words[i+1], ",")
+				t.add(lastKey, hop, lastStatic)
+				return
+			}
+			if w == "connected," && i+1 < len(words) && lastStatic {
+				// Static route to interface.
+				t.add(lastKey, words[i+1], true)
+				return
+			}
+		}
+	}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		if name, found := strings.CutPrefix(line, "Routing Table: "); found {
+			vrf = tableVRF(name)
+			haveLast = false
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			// Header of classful network
+			// "10.0.0.0/24 is subnetted, 3 subnets" or
+			// "10.0.0.0/8 is variably subnetted, 4 subnets, 2 masks".
+			if len(words) > 2 && words[1] == "is" {
+				defaultBits = -1
+				if words[2] == "subnetted," {
+					if p, err := netip.ParsePrefix(words[0]); err == nil {
+						defaultBits = p.Bits()
+					}
+				}
+				haveLast = false
+				continue
+			}
+			// Additional next hop of previous route.
+			if haveLast {
+				addHop(words)
+			}
+			continue
+		}
+		// Skip route codes like "S", "S*", "O IA", "O E2".
+		codes := words[0]
+		words = words[1:]
+		for len(words) > 0 && len(words[0]) <= 2 && !isAddrOrPrefix(words[0]) {
+			words = words[1:]
+		}
+		if len(codes) > 3 || len(words) == 0 || !isAddrOrPrefix(words[0]) {
+			haveLast = false
+			continue
+		}
+		var p netip.Prefix
+		var ok bool
+		if strings.Contains(words[0], "/") {
+			p, ok = parseRoutePrefix(words[:1])
+			words = words[1:]
+		} else if len(words) > 1 && isAddrOrPrefix(words[1]) &&
+			!strings.Contains(words[1], "/") {
+			p, ok = parseRoutePrefix(words[:2])
+			words = words[2:]
+		} else if ip, err := netip.ParseAddr(words[0]); err == nil {
+			bits := defaultBits
+			if bits < 0 {
+				bits = classfulBits(ip)
+			}
+			p, ok = netip.PrefixFrom(ip, bits), true
+			words = words[1:]
+		}
+		if !ok {
+			haveLast = false
+			continue
+		}
+		// Ignore directly connected and local networks.
+		if codes == "C" || codes == "L" {
+			haveLast = false
+			continue
+		}
+		lastKey = routeKey{vrf, p.Masked()}
+		lastStatic = codes[0] == 'S'
+		haveLast = true
+		addHop(words)
+	}
+	return t
+}
+
+func classfulBits(ip netip.Addr) int {
+	if ip.Is6() {
+		return 64
+	}
+	switch b := ip.As4()[0]; {
+	case b < 128:
+		return 8
+	case b < 192:
+		return 16
+	default:
+		return 24
+	}
+}
+
+// Get name of VRF from name of routing table.
+// Empty string is returned for the default routing table.
+func tableVRF(name string) string {
+	switch name = strings.TrimSpace(name); name {
+	case "Default", "default", "main":
+		return ""
+	}
+	return name
+}
+
+// Parse output of "ip route" and "ip -6 route" of Linux.
+// Routes of VRF are recognized from header "Routing Table: VRF"
+// or from attribute "table VRF" as shown by "ip route show table all".
+func parseIPRoutes(data string) routeTable {
+	t := make(routeTable)
+	sectionVRF := ""
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, found := strings.CutPrefix(line, "Routing Table: "); found {
+			sectionVRF = tableVRF(name)
+			continue
+		}
+		words := strings.Fields(line)
+		if len(words) < 3 {
+			continue
+		}
+		var p netip.Prefix
+		switch words[0] {
+		case "default":
+			p = netip.MustParsePrefix("0.0.0.0/0")
+		default:
+			var ok bool
+			if p, ok = parseRoutePrefix(words[:1]); !ok {
+				continue
+			}
+		}
+		// Get value of keywords like "via", "dev", "proto".
+		attr := make(map[string]string)
+		for i := 1; i+1 < len(words); i++ {
+			switch w := words[i]; w {
+			case "via", "dev", "proto", "scope", "table":
+				attr[w] = words[i+1]
+				i++
+			}
+		}
+		hop := attr["via"]
+		if hop == "" {
+			// Ignore directly connected network.
+			if attr["proto"] == "kernel" || attr["scope"] == "link" {
+				continue
+			}
+			hop = attr["dev"]
+		} else if words[0] == "default" && strings.Contains(hop, ":") {
+			p = netip.MustParsePrefix("::/0")
+		}
+		static := false
+		switch attr["proto"] {
+		case "", "boot", "static":
+			static = true
+		}
+		vrf := sectionVRF
+		if name, found := attr["table"]; found {
+			vrf = tableVRF(name)
+		}
+		t.add(routeKey{vrf, p}, hop, static)
+	}
+	return t
+}
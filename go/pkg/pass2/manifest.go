@@ -0,0 +1,135 @@
+package pass2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Manifest in code directory records content hashes of intermediate
+// files and of generated code of each device.
+// It is used to find code, that can be reused in next run.
+const manifestFile = ".manifest"
+
+type manifestEntry struct {
+	Config string `json:"config"`
+	Rules  string `json:"rules"`
+	Code   string `json:"code"`
+}
+
+// Reuse decides which code files from previous run can be reused
+// and collects manifest of current run.
+type Reuse struct {
+	prev   string
+	old    map[string]manifestEntry
+	mutex  sync.Mutex
+	cur    map[string]manifestEntry
+	noLink atomic.Bool
+}
+
+// NewReuse reads manifest from directory with code of previous run.
+// Code isn't reused if manifest is missing or invalid.
+func NewReuse(prev string) *Reuse {
+	r := &Reuse{prev: prev, cur: make(map[string]manifestEntry)}
+	if data, err := os.ReadFile(prev + "/" + manifestFile); err == nil {
+		if json.Unmarshal(data, &r.old) != nil {
+			r.old = nil
+		}
+	}
+	return r
+}
+
+// WriteManifest writes manifest of current run into code directory.
+func (r *Reuse) WriteManifest(dir string) error {
+	data, err := json.MarshalIndent(r.cur, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dir+"/"+manifestFile, data, 0666)
+}
+
+func hashFile(path string) (string, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, fd); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func mustHashFile(path string) string {
+	h, err := hashFile(path)
+	if err != nil {
+		panicf("Can't hash %s: %v", path, err)
+	}
+	return h
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Try to use code file from previous run.
+// Code is reused, if intermediate files of device have
+// the same hash as in previous run and if code file is unchanged.
+// Code file is linked or copied, if filesystem doesn't support links.
+func (r *Reuse) tryPrev(devicePath, file string, e *manifestEntry) bool {
+	old, found := r.old[devicePath]
+	if !found || old.Config != e.Config || old.Rules != e.Rules {
+		return false
+	}
+	prevFile := r.prev + "/" + devicePath
+	if h, err := hashFile(prevFile); err != nil || h != old.Code {
+		return false
+	}
+	// Try to remove old code file if it was left over somehow.
+	os.Remove(file)
+	if r.noLink.Load() || os.Link(prevFile, file) != nil {
+		r.noLink.Store(true)
+		if err := copyFile(prevFile, file); err != nil {
+			panicf("Can't %v", err)
+		}
+	}
+	e.Code = old.Code
+	return true
+}
+
+// File generates code for device from intermediate files of pass1
+// or reuses code from previous run.
+// Returns true, if code has been reused.
+func (r *Reuse) File(devicePath, dir string) bool {
+	file := dir + "/" + devicePath
+	var e manifestEntry
+	e.Rules = mustHashFile(file + ".rules")
+	e.Config = mustHashFile(file + ".config")
+	reused := r.tryPrev(devicePath, file, &e)
+	if !reused {
+		printRouter(file)
+		e.Code = mustHashFile(file)
+	}
+	r.mutex.Lock()
+	r.cur[devicePath] = e
+	r.mutex.Unlock()
+	return reused
+}
@@ -30,11 +30,9 @@ import (
 	"maps"
 	"net/netip"
 	"os"
-	"os/exec"
 	"slices"
 	"strings"
 
-	"github.com/hknutzen/Netspoc/go/pkg/fileop"
 	"github.com/hknutzen/Netspoc/go/pkg/jcode"
 )
 
@@ -390,41 +388,6 @@ func printRouter(file string) {
 	}
 }
 
-// Try to use pass2 file from previous run.
-// If identical files with extension .config and .rules
-// exist in directory .prev/, then use copy.
-func tryPrev(devicePath, dir, prev string) bool {
-	if !fileop.IsDir(prev) {
-		return false
-	}
-	prevFile := prev + "/" + devicePath
-	if !fileop.IsRegular(prevFile) {
-		return false
-	}
-	codeFile := dir + "/" + devicePath
-	for _, ext := range [...]string{"config", "rules"} {
-		pass1name := codeFile + "." + ext
-		pass1prev := prevFile + "." + ext
-		cmd := exec.Command("cmp", "-s", pass1name, pass1prev)
-		if cmd.Run() != nil {
-			return false
-		}
-	}
-	// Try to remove old code file if it was left over somehow.
-	os.Remove(codeFile)
-	if err := os.Link(prevFile, codeFile); err != nil {
-		panic(err)
-	}
-
-	// File was found and hardlink was created successfully.
-	return true
-}
-
 func File(devicePath, dir, prev string) bool {
-	if tryPrev(devicePath, dir, prev) {
-		return true
-	}
-	file := dir + "/" + devicePath
-	printRouter(file)
-	return false
+	return NewReuse(prev).File(devicePath, dir)
 }
@@ -9,7 +9,20 @@ import (
 	"github.com/hknutzen/Netspoc/go/pkg/ast"
 )
 
+// Style of printed output.
+type Style struct {
+	// Number of spaces for each level of indentation.
+	Indent int
+	// Put list of simple elements on a single line, if it fits into
+	// this many characters. Value 0 prints each element on a
+	// separate line.
+	MaxLineLength int
+}
+
+var DefaultStyle = Style{Indent: 1}
+
 type printer struct {
+	style Style
 	// Current state
 	output []byte // raw printer result
 	indent int    // current indentation
@@ -97,7 +110,7 @@ func (p *printer) element(pre string, el ast.Element, post string) {
 	case *ast.Intersection:
 		p.intersection(pre, x.Elements, post)
 	case *ast.Complement:
-		p.element("! ", x.Element, post)
+		p.element(pre+"! ", x.Element, post)
 	}
 }
 
@@ -120,13 +133,58 @@ func (p *printer) intersection(pre string, l []ast.Element, post string) {
 	p.indent -= ind
 }
 
+// Get line with all elements of list, if it fits into maximum line
+// length. List must have more than one element and all elements must
+// be simple and without comments.
+func (p *printer) singleLine(pre string, l []ast.Element, stop string) string {
+	max := p.style.MaxLineLength
+	if max == 0 || len(l) < 2 {
+		return ""
+	}
+	line := pre
+	for i, el := range l {
+		switch el.(type) {
+		case *ast.User, *ast.NamedRef, *ast.IntfRef:
+		default:
+			return ""
+		}
+		if el.PreComment() != "" || el.PostComment() != "" {
+			return ""
+		}
+		if i > 0 {
+			line += ", "
+		}
+		line += el.String()
+	}
+	line += stop
+	if p.indent+utfLen(line) > max {
+		return ""
+	}
+	return line
+}
+
+// Check if all values fit on a single line.
+func (p *printer) valuesFit(pre string, l []*ast.Value) bool {
+	max := p.style.MaxLineLength
+	if max == 0 {
+		return false
+	}
+	for _, v := range l {
+		if v.PreComment() != "" || v.PostComment() != "" {
+			return false
+		}
+	}
+	line, _ := getValueList(l)
+	return p.indent+utfLen(pre+line) <= max
+}
+
 func (p *printer) elementList(l []ast.Element, stop string) {
-	p.indent++
+	p.indent += p.style.Indent
 	for _, el := range l {
 		p.preComment(el)
 		p.element("", el, ","+el.PostComment())
 	}
-	p.indent--
+	p.indent -= p.style.Indent
 	p.print(stop)
 }
 
@@ -138,10 +196,10 @@ func (p *printer) description(n ast.Toplevel) {
 		t := strings.TrimRight(strings.TrimSpace(d.Text), " \t\r;")
 		// Ignore empty description.
 		if t != "" {
-			p.indent++
+			p.indent += p.style.Indent
 			p.preComment(d)
 			p.print("description = " + t + d.PostComment())
-			p.indent--
+			p.indent -= p.style.Indent
 			p.emptyLine()
 		}
 	}
@@ -153,6 +211,13 @@ func (p *printer) topListHead(n ast.Toplevel) {
 }
 
 func (p *printer) topElementList(n *ast.TopList) {
+	if n.GetDescription() == nil {
+		line := p.singleLine(n.Name+" = ", n.Elements, ";")
+		if line != "" {
+			p.print(line)
+			return
+		}
+	}
 	p.topListHead(n)
 	p.elementList(n.Elements, ";")
 }
@@ -165,9 +230,9 @@ func (p *printer) topProtocol(n *ast.Protocol) {
 	if n.GetDescription() != nil {
 		p.print(n.Name + " =")
 		p.description(n)
-		p.indent++
+		p.indent += p.style.Indent
 		p.print(proto)
-		p.indent--
+		p.indent -= p.style.Indent
 	} else {
 		p.print(n.Name + " = " + proto)
 	}
@@ -175,12 +240,12 @@ func (p *printer) topProtocol(n *ast.Protocol) {
 
 func (p *printer) topProtocolList(n *ast.Protocolgroup) {
 	p.topListHead(n)
-	p.indent++
+	p.indent += p.style.Indent
 	for _, el := range n.ValueList {
 		p.preComment(el)
 		p.print(el.Value + "," + el.PostComment())
 	}
-	p.indent--
+	p.indent -= p.style.Indent
 	p.print(";")
 }
 
@@ -199,12 +264,17 @@ func (p *printer) namedUnion(prefix string, n *ast.NamedUnion) {
 	// Put first value on same line with name, if it has no comment.
 	first := l[0]
 	var rest []ast.Element
+	if line := p.singleLine(pre, l, ";"); line != "" {
+		p.print(line)
+		return
+	}
 	ind := utfLen(pre)
-	if len(name) > shortName {
-		ind = 1
+	long := len(name) > shortName
+	if long {
+		ind = p.style.Indent
 	}
 	cmt := first.PreComment()
-	if cmt != "" || len(l) > 1 && ind == 1 {
+	if cmt != "" || len(l) > 1 && long {
 		p.print(pre[:len(pre)-1])
 		rest = l
 	} else {
@@ -238,11 +308,12 @@ func (p *printer) namedValueList(name string, l []*ast.Value) {
 	pre := name + " = "
 	var ind int
 	cmt := first.PreComment()
-	if cmt != "" || (len(name) > shortName && len(l) > 1) {
+	nextLine := cmt != "" || (len(name) > shortName && len(l) > 1)
+	if nextLine {
 		p.print(pre[:len(pre)-1])
-		ind = 1
+		ind = p.style.Indent
 		rest = l
-	} else if name == "model" || len(l) == 1 {
+	} else if name == "model" || len(l) == 1 || p.valuesFit(pre, l) {
 		line, comment := getValueList(l)
 		p.print(pre + line + comment)
 	} else {
@@ -258,7 +329,7 @@ func (p *printer) namedValueList(name string, l []*ast.Value) {
 			p.preComment(v)
 			p.print(v.Value + "," + v.PostComment())
 		}
-		if ind == 1 {
+		if nextLine {
 			p.indent -= ind
 			p.print(";")
 		} else {
@@ -271,11 +342,11 @@ func (p *printer) namedValueList(name string, l []*ast.Value) {
 func (p *printer) complexValue(n *ast.Attribute) {
 	pre := n.Name + " = {"
 	p.print(pre)
-	p.indent++
+	p.indent += p.style.Indent
 	for _, a := range n.ComplexValue {
 		p.attribute(a)
 	}
-	p.indent--
+	p.indent -= p.style.Indent
 	p.print("}")
 }
 
@@ -301,11 +372,11 @@ func (p *printer) attribute(n *ast.Attribute) {
 }
 
 func (p *printer) attributeList(l []*ast.Attribute) {
-	p.indent++
+	p.indent += p.style.Indent
 	for _, a := range l {
 		p.attribute(a)
 	}
-	p.indent--
+	p.indent -= p.style.Indent
 }
 
 func (p *printer) shortAttributeList(name string, l []*ast.Attribute) {
@@ -343,7 +414,7 @@ func (p *printer) service(n *ast.Service) {
 		p.attributeList(l)
 		p.emptyLine()
 	}
-	p.indent++
+	p.indent += p.style.Indent
 	if n.Foreach {
 		p.print("user = foreach")
 		p.elementList(n.User.Elements, ";")
@@ -353,7 +424,7 @@ func (p *printer) service(n *ast.Service) {
 	for _, r := range n.Rules {
 		p.rule(r)
 	}
-	p.indent--
+	p.indent -= p.style.Indent
 	p.print("}")
 }
 
@@ -441,7 +512,7 @@ ATTR:
 func (p *printer) indentedAttributeList(
 	l []*ast.Attribute, simple map[string]bool) {
 
-	p.indent++
+	p.indent += p.style.Indent
 	max, noIndent := getMaxAndNoIndent(l, simple)
 	for _, a := range l {
 		if a.ComplexValue == nil {
@@ -453,7 +524,7 @@ func (p *printer) indentedAttributeList(
 			p.indentedAttribute(a, max)
 		}
 	}
-	p.indent--
+	p.indent -= p.style.Indent
 }
 
 var simpleHostAttr = map[string]bool{
@@ -490,9 +561,9 @@ func (p *printer) router(n *ast.Router) {
 
 func (p *printer) namedUnionIfSet(n *ast.NamedUnion) {
 	if n != nil {
-		p.indent++
+		p.indent += p.style.Indent
 		p.namedUnion("", n)
-		p.indent--
+		p.indent -= p.style.Indent
 	}
 }
 
@@ -575,7 +646,11 @@ func (p *printer) simpleNetList(l []*ast.Network) {
 }
 
 func File(aF *ast.File) []byte {
-	p := new(printer)
+	return StyledFile(aF, DefaultStyle)
+}
+
+func StyledFile(aF *ast.File, s Style) []byte {
+	p := &printer{style: s}
 
 	list := aF.Nodes
 	var simple []*ast.Network
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"unicode"
 	"unicode/utf8"
 )
@@ -28,6 +29,8 @@ type Scanner struct {
 	ch       rune // current character
 	offset   int  // character offset
 	rdOffset int  // reading offset (position after current character)
+
+	lines []int // offsets of line starts, filled on demand
 }
 
 // Read the next Unicode char into s.ch.
@@ -36,18 +39,24 @@ func (s *Scanner) next() {
 	if s.rdOffset < len(s.src) {
 		s.offset = s.rdOffset
 		r, w := rune(s.src[s.rdOffset]), 1
+		var msg string
 		switch {
 		case r == 0:
-			s.syntaxErr("illegal character NUL")
+			msg = "illegal character NUL"
 		case r >= utf8.RuneSelf:
 			// not ASCII
 			r, w = utf8.DecodeRune(s.src[s.rdOffset:])
 			if r == utf8.RuneError {
-				s.syntaxErr("illegal UTF-8 encoding")
+				msg = "illegal UTF-8 encoding"
 			}
 		}
 		s.rdOffset += w
 		s.ch = r
+		// Show error after invalid character has been read.
+		// Hence scanning may be continued after error.
+		if msg != "" {
+			s.syntaxErr(msg)
+		}
 	} else {
 		s.offset = len(s.src)
 		s.ch = -1 // eof
@@ -70,6 +79,7 @@ func (s *Scanner) Init(src []byte, fname string, ah AbortHandler) {
 	s.ch = ' '
 	s.offset = 0
 	s.rdOffset = 0
+	s.lines = nil
 
 	s.next()
 }
@@ -113,6 +123,42 @@ func (s *Scanner) context(offset int) string {
 	return c
 }
 
+// LineColumn returns line and column of position pos.
+// First line and first column have number 1.
+// Columns are counted in bytes.
+func (s *Scanner) LineColumn(pos int) (int, int) {
+	if s.lines == nil {
+		s.lines = []int{0}
+		for i, b := range s.src {
+			if b == '\n' {
+				s.lines = append(s.lines, i+1)
+			}
+		}
+	}
+	// Find last line that starts at or before pos.
+	i, found := slices.BinarySearch(s.lines, pos)
+	if !found {
+		i--
+	}
+	return i + 1, pos - s.lines[i] + 1
+}
+
+// AtLineStart reports whether only white space is found between
+// start of line and position pos.
+func (s *Scanner) AtLineStart(pos int) bool {
+	for pos > 0 {
+		pos--
+		switch s.src[pos] {
+		case '\n':
+			return true
+		case ' ', '\t', '\r':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Scanner) SyntaxErr(offset int, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
 	msg = msg + s.context(offset)
@@ -3,23 +3,30 @@ package netspoc_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync/atomic"
 	"testing"
 
 	"github.com/hknutzen/Netspoc/go/pkg/addto"
+	"github.com/hknutzen/Netspoc/go/pkg/anonymize"
 	"github.com/hknutzen/Netspoc/go/pkg/api"
 	"github.com/hknutzen/Netspoc/go/pkg/expand"
 	"github.com/hknutzen/Netspoc/go/pkg/exportsyntax"
+	"github.com/hknutzen/Netspoc/go/pkg/extract"
 	"github.com/hknutzen/Netspoc/go/pkg/fileop"
 	"github.com/hknutzen/Netspoc/go/pkg/format"
+	"github.com/hknutzen/Netspoc/go/pkg/importer"
+	"github.com/hknutzen/Netspoc/go/pkg/mergeservices"
 	"github.com/hknutzen/Netspoc/go/pkg/oslink"
 	"github.com/hknutzen/Netspoc/go/pkg/pass1"
 	"github.com/hknutzen/Netspoc/go/pkg/pass2"
@@ -50,22 +57,36 @@ type test struct {
 var tests = []test{
 	{".", outDirT, pass1.SpocMain, netspocCheck},
 	{"ipv6", outDirT, pass1.SpocMain, netspocCheck},
+	{"autofix", stdoutT, pass1.SpocMain, jsonCheck},
 	{"export-netspoc", outDirT, pass1.ExportMain, exportCheck},
 	{"format-netspoc", chgInputT, format.Main, formatCheck},
+	{"format-netspoc-stdout", stdoutT, formatStdoutRun, stdoutCheck},
 	{"add-to-netspoc", chgInputT, addto.Main, chgInputCheck},
 	{"expand-group", chgInputT, expand.Main, chgInputCheck},
+	{"extract-group", chgInputT, extract.Main, chgInputCheck},
+	{"extract-group-suggest", stdoutT, extract.Main, stdoutCheck},
+	{"merge-services", chgInputT, mergeservices.Main, chgInputCheck},
 	{"remove-from-netspoc", chgInputT, removefrom.Main, chgInputCheck},
 	{"remove-service", chgInputT, removeservice.Main, chgInputCheck},
 	{"rename-netspoc", chgInputT, rename.Main, chgInputCheck},
 	{"transpose-service", chgInputT, transposeservice.Main, chgInputCheck},
+	{"anonymize-netspoc", outDirT, anonymize.Main, formatCheck},
+	{"anonymize-compile", outDirT, anonymizeCompileRun, netspocCheck},
+	{"import-asa", chgInputT, importASARun, chgInputCheck},
+	{"import-iptables", chgInputT, importIptablesRun, chgInputCheck},
 	{"api", stdoutT, modifyRun, stdoutCheck},
 	{"cut-netspoc", stdoutT, pass1.CutNetspocMain, stdoutCheck},
 	{"export-netspoc-syntax", stdoutT, exportsyntax.Main, jsonCheck},
 	{"export-netvis", stdoutT, pass1.ExportNetvisMain, jsonCheck},
+	{"export-netvis-graph", stdoutT, pass1.ExportNetvisMain, stdoutCheck},
 	{"print-path", stdoutT, pass1.PrintPathMain, jsonCheck},
 	{"print-group", stdoutT, pass1.PrintGroupMain, stdoutCheck},
 	{"print-service", stdoutT, pass1.PrintServiceMain, stdoutCheck},
+	{"print-service-dates", stdoutT, pass1.PrintServiceDatesMain, stdoutCheck},
 	{"check-acl", outDirStdoutT, checkACLRun, stdoutCheck},
+	{"check-hitcount", outDirStdoutT, checkHitcountRun, stdoutCheck},
+	{"check-routes", outDirStdoutT, checkRoutesRun, stdoutCheck},
+	{"profile", outDirStdoutT, profileRun, stdoutCheck},
 }
 
 var count int32
@@ -561,3 +582,190 @@ func checkACLRun(d oslink.Data) int {
 	d.Args = chArgs
 	return pass2.CheckACLMain(d)
 }
+
+// Run format-netspoc, that writes to STDOUT.
+// If last argument is "-", content of input file is read from STDIN.
+// Arguments: PROGRAM -q [option ...] input [-]
+func formatStdoutRun(d oslink.Data) int {
+	if n := len(d.Args); n >= 3 && d.Args[n-1] == "-" {
+		data, err := os.ReadFile(d.Args[n-2])
+		if err != nil {
+			panic(err)
+		}
+		d.Stdin = bytes.NewReader(data)
+		d.Args = append(d.Args[:n-2], "-")
+	}
+	return format.Main(d)
+}
+
+// Anonymize input with fixed key and compile original and anonymized
+// input with Netspoc. Code of anonymized input is checked by test.
+// Both runs must generate the same files with the same number of lines.
+// Arguments: PROGRAM -q [option ...] input code
+func anonymizeCompileRun(d oslink.Data) int {
+	n := len(d.Args)
+	input, code := d.Args[n-2], d.Args[n-1]
+	anonInput := path.Join(path.Dir(input), "anonymized")
+	origCode := path.Join(path.Dir(input), "orig-code")
+	d2 := d
+	d2.Args = []string{d.Args[0], "-q", "--key=test", input, anonInput}
+	if status := anonymize.Main(d2); status != 0 {
+		return status
+	}
+	d2.Args = append(slices.Clone(d.Args[:n-2]), input, origCode)
+	if status := pass1.SpocMain(d2); status != 0 {
+		return status
+	}
+	d.Args = append(slices.Clone(d.Args[:n-2]), anonInput, code)
+	if status := pass1.SpocMain(d); status != 0 {
+		return status
+	}
+	orig, anon := countLines(origCode), countLines(code)
+	if len(orig) != len(anon) {
+		fmt.Fprintf(d.Stderr, "Error: Got %d files from original input,"+
+			" but %d files from anonymized input\n", len(orig), len(anon))
+		return 1
+	}
+	for i, o := range orig {
+		if a := anon[i]; o.lines != a.lines {
+			fmt.Fprintf(d.Stderr, "Error: %s has %d lines, but %s has %d\n",
+				o.name, o.lines, a.name, a.lines)
+			return 1
+		}
+	}
+	return 0
+}
+
+type fileLines struct {
+	name  string
+	lines int
+}
+
+// Count lines of visible files in directory, sorted by file name.
+func countLines(dir string) []fileLines {
+	var result []fileLines
+	filepath.WalkDir(dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil || e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		result = append(result, fileLines{rel, bytes.Count(data, []byte("\n"))})
+		return nil
+	})
+	return result
+}
+
+// Run Netspoc with option --profile_dir and show written profiles
+// and phases from report.json without measured values.
+// Arguments: PROGRAM -q [option ...] input code
+func profileRun(d oslink.Data) int {
+	prof := path.Join(path.Dir(d.Args[len(d.Args)-1]), "prof")
+	d.Args = slices.Concat(
+		d.Args[:2], []string{"--profile_dir=" + prof}, d.Args[2:])
+	if status := pass1.SpocMain(d); status != 0 {
+		return status
+	}
+	files, _ := os.ReadDir(prof)
+	for _, f := range files {
+		info, _ := f.Info()
+		if info.Size() == 0 {
+			fmt.Fprintln(d.Stdout, f.Name(), "is empty")
+		} else {
+			fmt.Fprintln(d.Stdout, f.Name())
+		}
+	}
+	data, err := os.ReadFile(path.Join(prof, "report.json"))
+	if err != nil {
+		fmt.Fprintln(d.Stderr, "Error:", err)
+		return 1
+	}
+	var report struct {
+		Phases []struct {
+			Name          string `json:"name"`
+			Pass          string `json:"pass"`
+			Networks      int    `json:"networks"`
+			Rules         int    `json:"rules"`
+			ExpandedRules int    `json:"expanded_rules"`
+			ACLLines      int    `json:"acl_lines"`
+		} `json:"phases"`
+		ACLLines map[string]int `json:"acl_lines"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		fmt.Fprintln(d.Stderr, "Error:", err)
+		return 1
+	}
+	for _, ph := range report.Phases {
+		fmt.Fprintf(d.Stdout, "%s %s %d %d %d %v\n", ph.Pass, ph.Name,
+			ph.Networks, ph.Rules, ph.ExpandedRules, ph.ACLLines)
+	}
+	fmt.Fprintln(d.Stdout, "acl_lines", report.ACLLines)
+	return 0
+}
+
+// Run import-asa with ASA configuration relative to working directory.
+// Arguments: PROGRAM -q [option ...] input asa-config
+// ASA configuration must be created by =SETUP=.
+func importASARun(d oslink.Data) int {
+	if n := len(d.Args); n >= 4 && !strings.HasPrefix(d.Args[n-1], "-") {
+		input := d.Args[n-2]
+		d.Args[n-1] = path.Join(path.Dir(input), d.Args[n-1])
+	}
+	return importer.ImportASAMain(d)
+}
+
+// Run import-iptables with rulesets relative to working directory.
+// Arguments: PROGRAM -q [option ...] input router ruleset ...
+// Rulesets must be created by =SETUP=.
+func importIptablesRun(d oslink.Data) int {
+	for i, a := range d.Args {
+		if path.Base(a) == "INPUT" {
+			for j := i + 2; j < len(d.Args); j++ {
+				d.Args[j] = path.Join(path.Dir(a), d.Args[j])
+			}
+			break
+		}
+	}
+	return importer.ImportIptablesMain(d)
+}
+
+// Run Netspoc pass1 with option --service_map + check-hitcount sequentially.
+// Arguments: PROGRAM -q input code [option ...] dump-dir
+// Dump directory is relative to working directory and
+// must be created by =SETUP=.
+func checkHitcountRun(d oslink.Data) int {
+	return checkDumpRun(d, []string{"--service_map"}, pass2.CheckHitcountMain)
+}
+
+// Run Netspoc pass1 + check-routes sequentially.
+// Arguments: PROGRAM -q input code [option ...] dump-dir
+func checkRoutesRun(d oslink.Data) int {
+	return checkDumpRun(d, nil, pass2.CheckRoutesMain)
+}
+
+// Run Netspoc pass1 + program, that compares generated code with
+// dump files of devices.
+func checkDumpRun(
+	d oslink.Data, p1Opts []string, check func(oslink.Data) int) int {
+
+	input, code := d.Args[2], d.Args[3]
+	p1Args := append([]string{d.Args[0], d.Args[1]}, p1Opts...)
+	p1Args = append(p1Args, input, code)
+	chArgs := []string{d.Args[0], d.Args[1], code}
+	for _, arg := range d.Args[4:] {
+		if !strings.HasPrefix(arg, "-") {
+			arg = path.Join(path.Dir(input), arg)
+		}
+		chArgs = append(chArgs, arg)
+	}
+	d.Args = p1Args
+	status := pass1.SpocMain(d)
+	if status != 0 {
+		return status
+	}
+	d.Args = chArgs
+	return check(d)
+}
@@ -0,0 +1,68 @@
+############################################################
+=TITLE=Anonymized input compiles to same structure
+=INPUT=
+network:Sales = {
+ ip = 10.1.1.0/24;
+ nat:Public = { ip = 192.168.1.0/24; }
+ host:Alice = { ip = 10.1.1.10; }
+}
+network:Transfer = { ip = 10.9.9.0/30; }
+network:Finance = { ip = 10.1.2.0/24; }
+router:Gateway = {
+ managed;
+ model = ASA;
+ interface:Sales = { ip = 10.1.1.1; hardware = inside; }
+ interface:Transfer = {
+  ip = 10.9.9.1;
+  hardware = outside;
+  nat_out = Public;
+ }
+}
+router:Core = {
+ managed;
+ model = IOS;
+ routing = OSPF;
+ interface:Transfer = { ip = 10.9.9.2; hardware = GigabitEthernet0/0; }
+ interface:Finance = { ip = 10.1.2.1; hardware = GigabitEthernet0/1; }
+}
+service:Web = {
+ user = host:Alice;
+ permit src = user; dst = network:Finance; prt = tcp 80, tcp 443;
+}
+service:Admin = {
+ user = network:Finance;
+ permit src = user;
+        dst = interface:Gateway.Sales, interface:Core.Transfer;
+        prt = tcp 22;
+}
+=OUTPUT=
+-- r1
+! [ ACL ]
+ip access-list extended hw1_in
+ permit 89 178.107.150.196 0.0.0.3 host 224.0.0.5
+ permit 89 178.107.150.196 0.0.0.3 host 224.0.0.6
+ permit 89 178.107.150.196 0.0.0.3 178.107.150.196 0.0.0.3
+ deny ip any host 178.98.110.1
+ permit tcp host 61.36.81.12 178.98.110.0 0.0.0.255 eq 80
+ permit tcp host 61.36.81.12 178.98.110.0 0.0.0.255 eq 443
+ permit tcp host 61.36.81.1 178.98.110.0 0.0.0.255 established
+ deny ip any any
+--
+ip access-list extended hw2_in
+ permit tcp 178.98.110.0 0.0.0.255 host 178.107.150.198 eq 22
+ permit 89 178.98.110.0 0.0.0.255 host 224.0.0.5
+ permit 89 178.98.110.0 0.0.0.255 host 224.0.0.6
+ permit 89 178.98.110.0 0.0.0.255 178.98.110.0 0.0.0.255
+ permit tcp 178.98.110.0 0.0.0.255 host 61.36.81.1 eq 22
+ permit tcp 178.98.110.0 0.0.0.255 host 61.36.81.12 established
+ deny ip any any
+-- r2
+! [ Routing ]
+route hw4 178.98.110.0 255.255.255.0 178.107.150.198
+--
+! hw3_in
+access-list hw3_in extended permit tcp host 178.98.108.12 178.98.110.0 255.255.255.0 eq 80
+access-list hw3_in extended permit tcp host 178.98.108.12 178.98.110.0 255.255.255.0 eq 443
+access-list hw3_in extended deny ip any4 any4
+access-group hw3_in in interface hw3
+=END=
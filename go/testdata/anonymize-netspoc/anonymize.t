@@ -0,0 +1,211 @@
+
+############################################################
+=TITLE=Option '-h'
+=INPUT=#
+=PARAMS=-h
+=ERROR=
+Usage: PROGRAM [options] IN-DIR|IN-FILE OUT-DIR
+  -k, --key string   Key for mapping of IP addresses, random if not given
+  -q, --quiet        Don't print status messages
+=END=
+
+############################################################
+=TITLE=Missing output directory
+=INPUT=#
+=ERROR=
+Usage: PROGRAM [options] IN-DIR|IN-FILE OUT-DIR
+  -k, --key string   Key for mapping of IP addresses, random if not given
+  -q, --quiet        Don't print status messages
+=END=
+
+############################################################
+=TITLE=Invalid input
+=WITH_OUTDIR=
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24;
+=ERROR=
+Error: Expected something at line 1 of INPUT, at EOF
+=END=
+
+############################################################
+=TITLE=Rename objects, remove comments and descriptions
+=OPTIONS=--key=test
+=INPUT=
+--topo
+# Network of department X
+network:Sales = {
+ description = Sales department
+ ip = 10.1.1.0/24;
+ host:Alice = { ip = 10.1.1.10; owner = Marketing; }
+ host:Printer = { range = 10.1.1.16 - 10.1.1.31; }
+}
+network:Finance = { ip = 10.1.2.0/24; } # second network
+router:Gateway = {
+ managed;
+ model = ASA;
+ interface:Sales = { ip = 10.1.1.1; hardware = inside; }
+ interface:Finance = { ip = 10.1.2.1; hardware = outside; }
+}
+--rules
+group:Accounting = host:Alice, network:Finance;
+service:Web = {
+ user = group:Accounting;
+ permit src = user; dst = interface:Gateway.Sales; prt = tcp 80;
+}
+owner:Marketing = {
+ admins = alice@example.com;
+}
+=OUTPUT=
+-- file1
+group:g1 =
+ host:h1,
+ network:n1,
+;
+
+service:s1 = {
+ user = group:g1;
+ permit src = user;
+        dst = interface:r1.n2;
+        prt = tcp 80;
+}
+
+owner:o1 = {
+ admins = user1@domain1;
+}
+-- file2
+network:n2 = {
+ description = Description 1
+
+ ip = 178.98.108.0/24;
+ host:h1 = { ip = 178.98.108.15; owner = o1; }
+ host:h2 = { range = 178.98.108.16-178.98.108.31; }
+}
+
+network:n1 = { ip = 178.98.110.0/24; }
+
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n2 = { ip = 178.98.108.1; hardware = hw1; }
+ interface:n1 = { ip = 178.98.110.1; hardware = hw2; }
+}
+=END=
+
+############################################################
+=TITLE=NAT, IPv6, VRF, bridged network and ID host
+=OPTIONS=--key=test
+=INPUT=
+network:Intern = {
+ ip = 10.1.1.0/24;
+ nat:Extern = { ip = 192.168.1.0/24; }
+ nat:Hide = { hidden; }
+ host:id:alice@example.com = { ip = 10.1.1.10; }
+}
+network:Transfer = { ip = 10.9.9.0/30; }
+router:Firewall@Vrf1 = {
+ managed;
+ model = IOS;
+ interface:Intern = { ip = 10.1.1.1; hardware = e0; nat_out = Extern; }
+ interface:Transfer = { ip = 10.9.9.1; hardware = e1; }
+}
+network:Bridged/Left = { ip = 10.2.2.0/24; }
+network:Bridged/Right = { ip = 10.2.2.0/24; }
+router:Bridge = {
+ model = ASA;
+ managed;
+ interface:Bridged = { ip = 10.2.2.9; hardware = device; }
+ interface:Bridged/Left = { hardware = inside; }
+ interface:Bridged/Right = { hardware = outside; }
+}
+network:V6 = { ip6 = 2001:db8:1::/64; }
+=OUTPUT=
+-- file1
+network:n2 = {
+ ip = 178.98.108.0/24;
+ nat:nat1 = { ip = 61.36.81.0/24; }
+ nat:nat2 = { hidden; }
+ host:id:user1@domain1 = { ip = 178.98.108.12; }
+}
+
+network:n3 = { ip = 178.107.150.196/30; }
+
+router:r2@v1 = {
+ managed;
+ model = IOS;
+ interface:n2 = {
+  ip = 178.98.108.1;
+  hardware = hw1;
+  nat_out = nat1;
+ }
+ interface:n3 = { ip = 178.107.150.197; hardware = hw2; }
+}
+
+network:n1/b1 = { ip = 178.97.33.0/24; }
+network:n1/b2 = { ip = 178.97.33.0/24; }
+
+router:r1 = {
+ model = ASA;
+ managed;
+ interface:n1    = { ip = 178.97.33.14; hardware = device; }
+ interface:n1/b1 = { hardware = hw3; }
+ interface:n1/b2 = { hardware = hw4; }
+}
+
+network:n4 = { ip6 = f874:de7f:269e:2d66::/64; }
+=END=
+
+############################################################
+=TITLE=Values of vpn_attributes and names of hardware
+=OPTIONS=--key=test
+=INPUT=
+router:asavpn = {
+ model = ASA, VPN;
+ managed;
+ vpn_attributes = {
+  trust-point = ACME_TrustPoint;
+  banner = Welcome to ACME;
+  dns-server = 10.1.1.53;
+  default-domain = acme.example.com;
+  vpn-idle-timeout = 120;
+ }
+ interface:dmz = { ip = 192.168.0.101; hardware = GigabitEthernet0/1; }
+}
+network:dmz = { ip = 192.168.0.0/24; }
+network:customers = {
+ ip = 10.99.1.0/24;
+ host:id:foo@acme.example.com = {
+  ip = 10.99.1.10;
+  vpn_attributes = {
+   split-tunnel-policy = tunnelspecified;
+   banner = Hello Foo;
+  }
+ }
+}
+=OUTPUT=
+-- file1
+router:r1 = {
+ model = ASA, VPN;
+ managed;
+ vpn_attributes = {
+  trust-point = value2;
+  banner = value4;
+  dns-server = value1;
+  default-domain = value5;
+  vpn-idle-timeout = 120;
+ }
+ interface:n2 = { ip = 61.36.80.99; hardware = hw1; }
+}
+
+network:n2 = { ip = 61.36.80.0/24; }
+
+network:n1 = {
+ ip = 178.12.239.0/24;
+ host:id:user1@domain1 = {
+  ip = 178.12.239.8;
+  vpn_attributes = {
+   split-tunnel-policy = tunnelspecified;
+   banner = value3;
+  }
+ }
+}
+=END=
@@ -83,6 +83,23 @@ ip access-list extended e0_in
  deny ip any any
 =END=
 
+############################################################
+=TITLE=Interface with BGP
+=INPUT=
+network:U = { ip = 10.1.1.0/24; }
+router:R = {
+ managed;
+ model = IOS;
+ interface:U = { ip = 10.1.1.1; hardware = e0; routing = BGP; }
+}
+=OUTPUT=
+--R
+ip access-list extended e0_in
+ permit tcp 10.1.1.0 0.0.0.255 10.1.1.0 0.0.0.255 eq 179
+ permit tcp 10.1.1.0 0.0.0.255 eq 179 10.1.1.0 0.0.0.255
+ deny ip any any
+=END=
+
 ############################################################
 =TITLE=Interface with HSRP
 =INPUT=
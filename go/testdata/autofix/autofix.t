@@ -0,0 +1,292 @@
+
+############################################################
+=TITLE=Useless attributes of service
+=OPTIONS=--autofix=-
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; host:h1 = { ip = 10.1.1.10; } }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+service:s1 = {
+ user = host:h1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s2 = {
+ has_unenforceable;
+ overlaps = service:s1, service:s3;
+ user = host:h1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s3 = {
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+=WARNING=
+Warning: Useless 'has_unenforceable' at service:s2
+Warning: Redundant rules in service:s1 compared to service:s3:
+  permit src=host:h1; dst=network:n2; prt=tcp 80; of service:s1
+< permit src=network:n1; dst=network:n2; prt=tcp 80; of service:s3
+Warning: Useless 'overlaps = service:s3' at service:s2
+=OUTPUT=
+{
+ "method": "multi_job",
+ "params": {
+  "jobs": [
+   {
+    "method": "delete",
+    "params": {
+     "path": "service:s2,has_unenforceable"
+    }
+   },
+   {
+    "method": "delete",
+    "params": {
+     "path": "service:s2,overlaps",
+     "value": "service:s3"
+    }
+   }
+  ]
+ }
+}
+=END=
+
+############################################################
+=TITLE=Duplicate elements
+=OPTIONS=--autofix=-
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; host:h1 = { ip = 10.1.1.10; } host:h2 = { ip = 10.1.1.11; } }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+group:g1 = host:h1, host:h2, host:h1, host:h1;
+group:g2 = group:g1, host:h2;
+group:g3 = host:h1, host:h2;
+service:s1 = {
+ user = group:g2, group:g3;
+ permit src = user; dst = network:n2, network:n2; prt = tcp 80;
+}
+=WARNING=
+Warning: Duplicate elements in group:g1:
+ - host:h1
+ - host:h1
+Warning: Duplicate elements in group:g2:
+ - host:h2
+Warning: Duplicate elements in user of service:s1:
+ - host:h1
+ - host:h2
+Warning: Duplicate elements in dst of rule in service:s1:
+ - network:n2
+=OUTPUT=
+{
+ "method": "multi_job",
+ "params": {
+  "jobs": [
+   {
+    "method": "delete",
+    "params": {
+     "path": "group:g1,elements",
+     "value": [
+      "host:h1",
+      "host:h1"
+     ]
+    }
+   },
+   {
+    "method": "delete",
+    "params": {
+     "path": "group:g2,elements",
+     "value": "host:h2"
+    }
+   },
+   {
+    "method": "delete",
+    "params": {
+     "path": "service:s1,rules,1,dst",
+     "value": "network:n2"
+    }
+   }
+  ]
+ }
+}
+=END=
+
+############################################################
+=TITLE=Redundant rules in same service
+=OPTIONS=--autofix=-
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; host:h1 = { ip = 10.1.1.10; } host:h2 = { ip = 10.1.1.11; } }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+service:s1 = {
+ user = host:h1, host:h2;
+ permit src = user; dst = network:n2; prt = tcp 80;
+ permit src = user; dst = network:n2; prt = tcp 81;
+ permit src = user; dst = network:n2; prt = tcp;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s2 = {
+ user = host:h1;
+ permit src = user; dst = network:n2; prt = udp 53;
+ permit src = user; dst = network:n2; prt = udp 53;
+}
+=WARNING=
+Warning: Duplicate rules in service:s1 and service:s1:
+  permit src=host:h1; dst=network:n2; prt=tcp 80; of service:s1
+  permit src=host:h2; dst=network:n2; prt=tcp 80; of service:s1
+Warning: Duplicate rules in service:s2 and service:s2:
+  permit src=host:h1; dst=network:n2; prt=udp 53; of service:s2
+Warning: Redundant rules in service:s1 compared to service:s1:
+  permit src=host:h1; dst=network:n2; prt=tcp 80; of service:s1
+< permit src=host:h1; dst=network:n2; prt=tcp; of service:s1
+  permit src=host:h1; dst=network:n2; prt=tcp 81; of service:s1
+< permit src=host:h1; dst=network:n2; prt=tcp; of service:s1
+  permit src=host:h2; dst=network:n2; prt=tcp 80; of service:s1
+< permit src=host:h2; dst=network:n2; prt=tcp; of service:s1
+  permit src=host:h2; dst=network:n2; prt=tcp 81; of service:s1
+< permit src=host:h2; dst=network:n2; prt=tcp; of service:s1
+=OUTPUT=
+{
+ "method": "multi_job",
+ "params": {
+  "jobs": [
+   {
+    "method": "delete",
+    "params": {
+     "path": "service:s1,rules,4"
+    }
+   },
+   {
+    "method": "delete",
+    "params": {
+     "path": "service:s1,rules,2"
+    }
+   },
+   {
+    "method": "delete",
+    "params": {
+     "path": "service:s1,rules,1"
+    }
+   },
+   {
+    "method": "delete",
+    "params": {
+     "path": "service:s2,rules,2"
+    }
+   }
+  ]
+ }
+}
+=END=
+
+############################################################
+=TITLE=Don't fix partially redundant rule or rule of other service
+=OPTIONS=--autofix=-
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; host:h1 = { ip = 10.1.1.10; } host:h2 = { ip = 10.1.1.11; } }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+service:s1 = {
+ user = host:h1;
+ permit src = user; dst = network:n2; prt = tcp 80, tcp 81;
+ permit src = user; dst = network:n2; prt = tcp 80-90;
+}
+service:s2 = {
+ user = host:h1, host:h2;
+ permit src = user; dst = network:n2; prt = tcp 82;
+}
+=WARNING=
+Warning: Redundant rules in service:s1 compared to service:s1:
+  permit src=host:h1; dst=network:n2; prt=tcp 80; of service:s1
+< permit src=host:h1; dst=network:n2; prt=tcp 80-90; of service:s1
+  permit src=host:h1; dst=network:n2; prt=tcp 81; of service:s1
+< permit src=host:h1; dst=network:n2; prt=tcp 80-90; of service:s1
+Warning: Redundant rules in service:s2 compared to service:s1:
+  permit src=host:h1; dst=network:n2; prt=tcp 82; of service:s2
+< permit src=host:h1; dst=network:n2; prt=tcp 80-90; of service:s1
+=OUTPUT=
+{
+ "method": "multi_job",
+ "params": {
+  "jobs": [
+   {
+    "method": "delete",
+    "params": {
+     "path": "service:s1,rules,1"
+    }
+   }
+  ]
+ }
+}
+=END=
+
+############################################################
+=TITLE=Unused groups
+=OPTIONS=--autofix=-
+=INPUT=
+group:g1 = network:n1;
+group:g2 = group:g1;
+protocolgroup:pg1 = tcp 80;
+network:n1 = { ip = 10.1.1.0/24; }
+=WARNING=
+Warning: unused group:g1
+Warning: unused group:g2
+Warning: unused protocolgroup:pg1
+=OUTPUT=
+{
+ "method": "multi_job",
+ "params": {
+  "jobs": [
+   {
+    "method": "delete",
+    "params": {
+     "path": "group:g1"
+    }
+   },
+   {
+    "method": "delete",
+    "params": {
+     "path": "group:g2"
+    }
+   },
+   {
+    "method": "delete",
+    "params": {
+     "path": "protocolgroup:pg1"
+    }
+   }
+  ]
+ }
+}
+=END=
+
+############################################################
+=TITLE=No fixable warnings
+=OPTIONS=--autofix=-
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+=OUTPUT=
+{
+ "method": "multi_job",
+ "params": {
+  "jobs": []
+ }
+}
+=END=
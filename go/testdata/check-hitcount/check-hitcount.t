@@ -0,0 +1,197 @@
+
+=TEMPL=input
+network:n1 = { ip = 10.1.1.0/24; host:h10 = { ip = 10.1.1.10; } }
+network:n2 = { ip = 10.1.2.0/24; host:h20 = { ip = 10.1.2.20; } }
+network:n3 = { ip = 10.1.3.0/24; }
+router:asa = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+router:linux = {
+ managed;
+ model = Linux;
+ interface:n2 = { ip = 10.1.2.2; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = host:h20; prt = tcp 80;
+}
+service:s2 = {
+ user = host:h10;
+ permit src = user; dst = network:n2; prt = udp 123, icmp 8;
+}
+service:s3 = {
+ user = network:n1;
+ permit src = user; dst = network:n3; prt = tcp 22;
+}
+service:s4 = {
+ user = network:n2;
+ permit src = user; dst = network:n3; prt = tcp 80-90, tcp 443;
+}
+=END=
+
+=TEMPL=asa
+mkdir -p hits
+cat > hits/asa <<END
+access-list cached ACL log flows: total 0, denied 0 (deny-flow-max 4096)
+access-list n1_in; 5 elements; name hash: 0x6c9f2b4f
+access-list n1_in line 1 extended permit tcp 10.1.1.0 255.255.255.0 host 10.1.2.20 eq www (hitcnt={{.s1}}) 0x2d3c0f6a
+access-list n1_in line 2 extended permit udp host 10.1.1.10 10.1.2.0 255.255.255.0 eq ntp (hitcnt=0) 0x9b1a7e3e
+access-list n1_in line 3 extended permit icmp host 10.1.1.10 10.1.2.0 255.255.255.0 echo (hitcnt={{.s2}}) 0x1f0c2d7a
+access-list n1_in line 4 extended permit tcp 10.1.1.0 255.255.255.0 10.1.3.0 255.255.255.0 eq ssh (hitcnt=0) 0x5e8e2c11
+access-list n1_in line 5 extended deny ip any4 any4 (hitcnt=17) 0x8a7b6c5d
+access-list n2_in; 1 elements; name hash: 0x4a2b3c4d
+access-list n2_in line 1 extended deny ip any4 any4 (hitcnt=0) 0x1a2b3c4d
+END
+=END=
+
+=TEMPL=linux
+mkdir -p hits
+cat > hits/linux <<END
+Chain INPUT (policy DROP 0 packets, 0 bytes)
+    pkts      bytes target     prot opt in     out     source               destination
+     120     9600 ACCEPT     all  --  *      *       0.0.0.0/0            0.0.0.0/0            state RELATED,ESTABLISHED
+       0        0 n2_self    all  --  n2     *       0.0.0.0/0            0.0.0.0/0
+
+Chain FORWARD (policy DROP 0 packets, 0 bytes)
+    pkts      bytes target     prot opt in     out     source               destination
+     300    24000 ACCEPT     all  --  *      *       0.0.0.0/0            0.0.0.0/0            state RELATED,ESTABLISHED
+      {{.n2_n3}}      240 n2_n3      all  --  n2     n3      0.0.0.0/0            0.0.0.0/0
+
+Chain c1 (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+       {{.s4}}      180 ACCEPT     tcp  --  *      *       0.0.0.0/0            0.0.0.0/0            tcp dpt:443
+       0        0 ACCEPT     tcp  --  *      *       0.0.0.0/0            0.0.0.0/0            tcp dpts:80:90
+
+Chain c2 (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+       {{.s4}}      180 c1         tcp  --  *      *       10.1.2.0/24          0.0.0.0/0           [goto]  tcp dpts:80:443
+       {{.s3}}       60 ACCEPT     tcp  --  *      *       10.1.1.0/24          0.0.0.0/0            tcp dpt:22
+
+Chain n2_n3 (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+       {{.n2_n3}}      240 c2         tcp  --  *      *       10.1.0.0/22          10.1.3.0/24         [goto]
+END
+=END=
+
+############################################################
+=TITLE=Option '-h'
+=INPUT=[[input]]
+=PARAMS=-h
+=ERROR=
+Usage: PROGRAM [options] CODE-DIR DUMP-DIR
+  -q, --quiet   Don't print progress messages
+=END=
+
+############################################################
+=TITLE=Unknown option
+=INPUT=[[input]]
+=PARAMS=--abc hits
+=ERROR=
+Error: unknown flag: --abc
+=END=
+
+############################################################
+=TITLE=Services without hits at ASA
+=SETUP=[[asa {s1: 0, s2: 4}]]
+=INPUT=[[input]]
+=PARAMS=hits
+# Services s3 and s4 are ignored, because no dump of router:linux is given.
+=OUTPUT=
+service:s1
+=END=
+
+############################################################
+=TITLE=Services without hits at ASA and Linux
+=SETUP=
+[[asa {s1: 0, s2: 4}]]
+[[linux {n2_n3: 3, s3: 0, s4: 3}]]
+=INPUT=[[input]]
+=PARAMS=hits
+=OUTPUT=
+service:s1
+service:s3
+=END=
+
+############################################################
+=TITLE=Hits of sub-chain are combined with jumping rule
+# Rule for tcp 22 in c2 is hit, but s3 at n2_n3 has no hits at ASA.
+=SETUP=
+[[asa {s1: 7, s2: 0}]]
+[[linux {n2_n3: 0, s3: 1, s4: 0}]]
+=INPUT=[[input]]
+=PARAMS=hits
+=OUTPUT=
+service:s2
+service:s3
+service:s4
+=END=
+
+############################################################
+=TEMPL=input6
+network:n1 = { ip6 = ::a01:100/120; }
+network:n2 = { ip6 = ::a01:200/120; }
+router:linux = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip6 = ::a01:101; hardware = n1; }
+ interface:n2 = { ip6 = ::a01:201; hardware = n2; }
+}
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 22;
+}
+service:s2 = {
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+=END=
+
+=TEMPL=linux6
+mkdir -p hits/ipv6
+cat > hits/ipv6/linux <<END
+Chain FORWARD (policy DROP 0 packets, 0 bytes)
+    pkts      bytes target     prot opt in     out     source               destination
+     300      24000 ACCEPT     all      *      *      ::/0                 ::/0                 state RELATED,ESTABLISHED
+{{.n1_n2}}        480 n1_n2      all      !n2    n2     ::/0                 ::/0
+
+Chain c1 (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+ {{.s2}}        180 ACCEPT     tcp      *      *      ::/0                 ::/0                 tcp dpt:80
+ {{.s1}}         60 ACCEPT     tcp      *      *      ::/0                 ::/0                 tcp dpt:22
+
+Chain n1_n2 (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+{{.n1_n2}}        480 c1         tcp      !n2    *      ::a01:100/120        ::a01:200/120        [goto]  tcp dpts:22:80
+END
+=END=
+
+############################################################
+=TITLE=Column "opt" is empty and column "in" is negated in ip6tables
+=SETUP=[[linux6 {n1_n2: 5, s1: 5, s2: 0}]]
+=INPUT=[[input6]]
+=PARAMS=hits
+=OUTPUT=
+service:s2
+=END=
+
+############################################################
+=TITLE=Services of removed duplicate rules are checked
+=SETUP=[[linux6 {n1_n2: 5, s1: 5, s2: 0}]]
+=INPUT=
+[[input6]]
+service:s3 = {
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+=PARAMS=hits
+=WARNING=
+Warning: Duplicate rules in service:s3 and service:s2:
+  permit src=network:n1; dst=network:n2; prt=tcp 80; of service:s3
+=OUTPUT=
+service:s2
+service:s3
+=END=
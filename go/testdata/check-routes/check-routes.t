@@ -0,0 +1,324 @@
+=TEMPL=input
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+router:asa = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = inside; }
+ interface:n2 = { ip = 10.1.2.1; hardware = outside; }
+}
+router:ios = {
+ managed;
+ model = IOS;
+ interface:n2 = { ip = 10.1.2.2; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+router:linux = {
+ managed;
+ model = Linux;
+ interface:n3 = { ip = 10.1.3.2; hardware = eth0; }
+ interface:n4 = { ip = 10.1.4.1; hardware = eth1; }
+}
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = network:n4; prt = tcp 80;
+}
+=END=
+
+############################################################
+=TITLE=Option '-h'
+=INPUT=[[input]]
+=PARAMS=-h
+=ERROR=
+Usage: PROGRAM [options] CODE-DIR DUMP-DIR
+  -q, --quiet   Don't print progress messages
+=END=
+
+############################################################
+=TITLE=Unknown option
+=INPUT=[[input]]
+=PARAMS=--abc routes
+=ERROR=
+Error: unknown flag: --abc
+=END=
+
+############################################################
+=TITLE=Routes are equal
+=SETUP=
+mkdir -p routes
+cat > routes/asa <<END
+Codes: L - local, C - connected, S - static, R - RIP, M - mobile, B - BGP
+       D - EIGRP, EX - EIGRP external, O - OSPF, IA - OSPF inter area
+       * - candidate default, U - per-user static route, o - ODR
+
+Gateway of last resort is not set
+
+C        10.1.1.0 255.255.255.0 is directly connected, inside
+L        10.1.1.1 255.255.255.255 is directly connected, inside
+C        10.1.2.0 255.255.255.0 is directly connected, outside
+L        10.1.2.1 255.255.255.255 is directly connected, outside
+S        10.1.4.0 255.255.255.0 [1/0] via 10.1.2.2, outside
+END
+cat > routes/ios <<END
+Gateway of last resort is not set
+
+      10.0.0.0/8 is variably subnetted, 6 subnets, 2 masks
+S        10.1.1.0/24 [1/0] via 10.1.2.1
+C        10.1.2.0/24 is directly connected, n2
+L        10.1.2.2/32 is directly connected, n2
+C        10.1.3.0/24 is directly connected, n3
+L        10.1.3.1/32 is directly connected, n3
+S        10.1.4.0/24 [1/0] via 10.1.3.2
+END
+cat > routes/linux <<END
+10.1.1.0/24 via 10.1.3.1 dev eth0
+10.1.3.0/24 dev eth0 proto kernel scope link src 10.1.3.2
+10.1.4.0/24 dev eth1 proto kernel scope link src 10.1.4.1
+END
+=INPUT=[[input]]
+=PARAMS=routes
+=OUTPUT=NONE
+
+############################################################
+=TITLE=Missing, extra and conflicting routes
+=SETUP=
+mkdir -p routes
+cat > routes/asa <<END
+C        10.1.1.0 255.255.255.0 is directly connected, inside
+C        10.1.2.0 255.255.255.0 is directly connected, outside
+S*       0.0.0.0 0.0.0.0 [1/0] via 10.1.2.9, outside
+END
+cat > routes/ios <<END
+     10.0.0.0/24 is subnetted, 4 subnets
+S       10.1.1.0 [1/0] via 10.1.2.3
+C       10.1.2.0 is directly connected, n2
+C       10.1.3.0 is directly connected, n3
+S       10.1.4.0 [1/0] via 10.1.3.2
+                 [1/0] via 10.1.3.3
+END
+cat > routes/linux <<END
+default via 10.1.3.9 dev eth0 proto dhcp
+10.1.1.0/24 via 10.1.3.1 dev eth0
+10.1.3.0/24 dev eth0 proto kernel scope link src 10.1.3.2
+10.1.4.0/24 dev eth1 proto kernel scope link src 10.1.4.1
+10.9.0.0/16 via 10.1.3.1 dev eth0 proto static
+END
+=INPUT=[[input]]
+=PARAMS=routes
+=OUTPUT=
+asa: extra route 0.0.0.0/0 via 10.1.2.9
+asa: missing route 10.1.4.0/24 via 10.1.2.2
+ios: conflicting next hop for 10.1.1.0/24: 10.1.2.3, expected 10.1.2.1
+ios: conflicting next hop for 10.1.4.0/24: 10.1.3.2, 10.1.3.3, expected 10.1.3.2
+linux: extra route 10.9.0.0/16 via 10.1.3.1
+=END=
+
+############################################################
+=TITLE=Route learned by OSPF
+=SETUP=
+mkdir -p routes
+cat > routes/ios <<END
+O        10.1.1.0/24 [110/2] via 10.1.2.1, 00:01:02, n2
+O E2     10.9.9.0/24 [110/20] via 10.1.2.1, 00:01:02, n2
+S        10.1.4.0/24 [1/0] via 10.1.3.2
+END
+=INPUT=[[input]]
+=PARAMS=routes
+# Route to 10.9.9.0/24 isn't static and hence isn't reported.
+=OUTPUT=NONE
+
+############################################################
+=TITLE=Routes of VRF
+=SETUP=
+mkdir -p routes
+cat > routes/r1 <<END
+Routing Table: v1
+S        10.1.2.0/24 [1/0] via 10.1.1.2
+Routing Table: v2
+S        10.1.4.0/24 [1/0] via 10.1.3.3
+END
+=INPUT=
+network:n0 = { ip = 10.1.0.0/24; partition = part1; }
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+network:n5 = { ip = 10.1.5.0/24; partition = part2; }
+router:r1@v1 = {
+ managed;
+ model = IOS;
+ interface:n0 = { ip = 10.1.0.1; hardware = n0; }
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+}
+router:r1@v2 = {
+ managed;
+ model = IOS;
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+ interface:n5 = { ip = 10.1.5.1; hardware = n5; }
+}
+router:u1 = {
+ interface:n1 = { ip = 10.1.1.2; }
+ interface:n2;
+}
+router:u2 = {
+ interface:n3 = { ip = 10.1.3.2; }
+ interface:n4;
+}
+service:s1 = {
+ user = network:n0;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s2 = {
+ user = network:n5;
+ permit src = user; dst = network:n4; prt = tcp 80;
+}
+=PARAMS=routes
+=OUTPUT=
+r1: conflicting next hop for vrf v2 10.1.4.0/24: 10.1.3.3, expected 10.1.3.2
+=END=
+
+############################################################
+=TITLE=Routes of VRF of model Linux, BATCH
+=TEMPL=linux_vrf
+network:n0 = { ip = 10.1.0.0/24; partition = part1; }
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+network:n5 = { ip = 10.1.5.0/24; partition = part2; }
+router:r1@v1 = {
+ managed;
+ model = Linux, BATCH;
+ interface:n0 = { ip = 10.1.0.1; hardware = eth0; }
+ interface:n1 = { ip = 10.1.1.1; hardware = eth1; }
+}
+router:r1@v2 = {
+ managed;
+ model = Linux, BATCH;
+ interface:n3 = { ip = 10.1.3.1; hardware = eth3; }
+ interface:n5 = { ip = 10.1.5.1; hardware = eth5; }
+}
+router:u1 = {
+ interface:n1 = { ip = 10.1.1.2; }
+ interface:n2;
+}
+router:u2 = {
+ interface:n3 = { ip = 10.1.3.2; }
+ interface:n4;
+}
+service:s1 = {
+ user = network:n0;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s2 = {
+ user = network:n5;
+ permit src = user; dst = network:n4; prt = tcp 80;
+}
+=END=
+=SETUP=
+mkdir -p routes
+cat > routes/r1 <<END
+10.1.0.0/24 dev eth0 table v1 proto kernel scope link src 10.1.0.1
+10.1.1.0/24 dev eth1 table v1 proto kernel scope link src 10.1.1.1
+10.1.2.0/24 via 10.1.1.2 dev eth1 table v1
+10.1.3.0/24 dev eth3 table v2 proto kernel scope link src 10.1.3.1
+10.1.4.0/24 via 10.1.3.3 dev eth3 table v2
+10.1.5.0/24 dev eth5 table v2 proto kernel scope link src 10.1.5.1
+10.1.9.0/24 via 10.1.1.2 dev eth1 table v1 proto static
+local 10.1.0.1 dev eth0 table local proto kernel scope host src 10.1.0.1
+END
+=INPUT=[[linux_vrf]]
+=PARAMS=routes
+=OUTPUT=
+r1: extra route vrf v1 10.1.9.0/24 via 10.1.1.2
+r1: conflicting next hop for vrf v2 10.1.4.0/24: 10.1.3.3, expected 10.1.3.2
+=END=
+
+############################################################
+=TITLE=Routes of VRF of model Linux, FRR
+=SETUP=
+mkdir -p routes
+cat > routes/r1 <<END
+Routing Table: v1
+10.1.2.0/24 via 10.1.1.2 dev eth1 proto static metric 20
+Routing Table: v2
+10.1.3.0/24 dev eth3 proto kernel scope link src 10.1.3.1
+10.1.5.0/24 dev eth5 proto kernel scope link src 10.1.5.1
+END
+=INPUT=[[linux_vrf]]
+=SUBST=/Linux, BATCH/Linux, FRR/
+=PARAMS=routes
+=OUTPUT=
+r1: missing route vrf v2 10.1.4.0/24 via 10.1.3.2
+=END=
+
+############################################################
+=TITLE=IPv6 routes of ASA and Linux
+=SETUP=
+mkdir -p routes/ipv6
+cat > routes/ipv6/asa <<END
+S   2001:db8:4::/64 [1/0]
+     via 2001:db8:2::2, outside
+END
+cat > routes/ipv6/linux <<END
+2001:db8:1::/64 via 2001:db8:3::1 dev eth0 metric 1024 pref medium
+2001:db8:3::/64 dev eth0 proto kernel metric 256 pref medium
+default via 2001:db8:3::9 dev eth0 metric 1024 pref medium
+END
+=INPUT=
+network:n1 = { ip6 = 2001:db8:1::/64; }
+network:n2 = { ip6 = 2001:db8:2::/64; }
+network:n3 = { ip6 = 2001:db8:3::/64; }
+network:n4 = { ip6 = 2001:db8:4::/64; }
+router:asa = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip6 = 2001:db8:1::1; hardware = inside; }
+ interface:n2 = { ip6 = 2001:db8:2::1; hardware = outside; }
+}
+router:ios = {
+ managed;
+ model = IOS;
+ interface:n2 = { ip6 = 2001:db8:2::2; hardware = n2; }
+ interface:n3 = { ip6 = 2001:db8:3::1; hardware = n3; }
+}
+router:linux = {
+ managed;
+ model = Linux;
+ interface:n3 = { ip6 = 2001:db8:3::2; hardware = eth0; }
+ interface:n4 = { ip6 = 2001:db8:4::1; hardware = eth1; }
+}
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = network:n4; prt = tcp 80;
+}
+=PARAMS=routes
+=OUTPUT=
+ipv6/linux: extra route ::/0 via 2001:db8:3::9
+=END=
+
+############################################################
+=TITLE=Unsupported model
+=SETUP=
+mkdir -p routes
+touch routes/r1
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ model = PAN-OS;
+ management_instance;
+ interface:n1 = { ip = 10.1.1.9; }
+}
+router:r1@v1 = {
+ managed;
+ model = PAN-OS;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+=PARAMS=routes
+=WARNING=
+Warning: Ignoring routing table of r1 with unsupported model PAN-OS
+=END=
@@ -87,4 +87,148 @@ Error: unused group:g1
 Aborted after 2 errors
 =OPTIONS=--concurrency_pass1=2 --check_unused_groups=1 --check_unenforceable=1 --max_errors=2
 
-############################################################
\ No newline at end of file
+############################################################
+=TITLE=Messages of concurrent jobs in pass 1 have deterministic order
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+router:r2 = {
+ managed;
+ model = ASA;
+ interface:n2 = { ip = 10.1.2.2; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+router:r3 = {
+ managed;
+ model = ASA;
+ interface:n3 = { ip = 10.1.3.2; hardware = n3; }
+ interface:n4 = { ip = 10.1.4.1; hardware = n4; }
+}
+service:s1 = {
+ user = any:[network:n1];
+ permit src = user; dst = network:n4; prt = tcp 80;
+}
+service:s2 = {
+ user = network:n4;
+ permit src = user; dst = any:[network:n1]; prt = tcp 81;
+}
+service:s3 = {
+ user = network:n1, network:n2;
+ permit src = user; dst = network:n4; prt = tcp 80;
+ permit src = user; dst = network:n3; prt = tcp;
+}
+service:s4 = {
+ user = network:n2;
+ permit src = user; dst = network:n3; prt = tcp 22;
+}
+=WARNING=
+Warning: This supernet rule would permit unexpected access:
+  permit src=any:[network:n1]; dst=network:n4; prt=tcp 80; of service:s1
+ Generated ACL at interface:r2.n2 would permit access from additional networks:
+ - network:n2
+ Either replace any:[network:n1] by smaller networks that are not supernet
+ or add above-mentioned networks to src of rule.
+Warning: This supernet rule would permit unexpected access:
+  permit src=any:[network:n1]; dst=network:n4; prt=tcp 80; of service:s1
+ Generated ACL at interface:r3.n3 would permit access from additional networks:
+ - network:n3
+ Either replace any:[network:n1] by smaller networks that are not supernet
+ or add above-mentioned networks to src of rule.
+Warning: This supernet rule would permit unexpected access:
+  permit src=network:n4; dst=any:[network:n1]; prt=tcp 81; of service:s2
+ Generated ACL at interface:r3.n4 would permit access to additional networks:
+ - network:n3
+ Either replace any:[network:n1] by smaller networks that are not supernet
+ or add above-mentioned networks to dst of rule.
+Warning: This supernet rule would permit unexpected access:
+  permit src=network:n4; dst=any:[network:n1]; prt=tcp 81; of service:s2
+ Generated ACL at interface:r2.n3 would permit access to additional networks:
+ - network:n2
+ Either replace any:[network:n1] by smaller networks that are not supernet
+ or add above-mentioned networks to dst of rule.
+Warning: Redundant rules in service:s3 compared to service:s1:
+  permit src=network:n1; dst=network:n4; prt=tcp 80; of service:s3
+< permit src=any:[network:n1]; dst=network:n4; prt=tcp 80; of service:s1
+Warning: Redundant rules in service:s4 compared to service:s3:
+  permit src=network:n2; dst=network:n3; prt=tcp 22; of service:s4
+< permit src=network:n2; dst=network:n3; prt=tcp; of service:s3
+=OPTIONS=--concurrency_pass1=8
+
+############################################################
+=TITLE=Count errors of concurrent jobs in pass 1 for --max_errors
+# Each job has less than 3 errors, but all jobs have more.
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+router:r2 = {
+ managed;
+ model = ASA;
+ interface:n2 = { ip = 10.1.2.2; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+router:r3 = {
+ managed;
+ model = ASA;
+ interface:n3 = { ip = 10.1.3.2; hardware = n3; }
+ interface:n4 = { ip = 10.1.4.1; hardware = n4; }
+}
+service:s1 = {
+ user = any:[network:n1];
+ permit src = user; dst = network:n4; prt = tcp 80;
+}
+service:s2 = {
+ user = network:n4;
+ permit src = user; dst = any:[network:n1]; prt = tcp 81;
+}
+service:s3 = {
+ user = network:n1, network:n2;
+ permit src = user; dst = network:n4; prt = tcp 80;
+ permit src = user; dst = network:n3; prt = tcp;
+}
+service:s4 = {
+ user = network:n2;
+ permit src = user; dst = network:n3; prt = tcp 22;
+}
+service:s5 = {
+ user = any:[network:n1];
+ permit src = user; dst = network:n3; prt = tcp 82;
+}
+=ERROR=
+Error: This supernet rule would permit unexpected access:
+  permit src=any:[network:n1]; dst=network:n4; prt=tcp 80; of service:s1
+ Generated ACL at interface:r2.n2 would permit access from additional networks:
+ - network:n2
+ Either replace any:[network:n1] by smaller networks that are not supernet
+ or add above-mentioned networks to src of rule.
+Error: This supernet rule would permit unexpected access:
+  permit src=any:[network:n1]; dst=network:n4; prt=tcp 80; of service:s1
+ Generated ACL at interface:r3.n3 would permit access from additional networks:
+ - network:n3
+ Either replace any:[network:n1] by smaller networks that are not supernet
+ or add above-mentioned networks to src of rule.
+Error: This supernet rule would permit unexpected access:
+  permit src=any:[network:n1]; dst=network:n3; prt=tcp 82; of service:s5
+ Generated ACL at interface:r2.n2 would permit access from additional networks:
+ - network:n2
+ Either replace any:[network:n1] by smaller networks that are not supernet
+ or add above-mentioned networks to src of rule.
+Aborted after 3 errors
+=OPTIONS=--concurrency_pass1=8 --check_supernet_rules=1 --max_errors=3
+
+############################################################
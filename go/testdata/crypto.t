@@ -966,7 +966,7 @@ Warning: Ignoring 'merge_tunnelspecified' at router:r
 =END=
 
 ############################################################
-=TITLE=Crypto not supported
+=TITLE=Crypto supported for model Linux
 =INPUT=
 [[crypto_sts]]
 network:n = { ip = 10.1.1.0/24; }
@@ -975,8 +975,22 @@ router:r = {
  model = Linux;
  interface:n = { ip = 10.1.1.1; hardware = n; hub = crypto:sts; }
 }
+=WARNING=
+Warning: No spokes have been defined for crypto:sts
+=END=
+
+############################################################
+=TITLE=Crypto not supported
+=INPUT=
+[[crypto_sts]]
+network:n = { ip = 10.1.1.0/24; }
+router:r@v1 = {
+ managed;
+ model = PAN-OS;
+ interface:n = { ip = 10.1.1.1; hardware = n; hub = crypto:sts; }
+}
 =ERROR=
-Error: Crypto not supported for router:r of model Linux
+Error: Crypto not supported for router:r@v1 of model PAN-OS
 =END=
 
 ############################################################
@@ -4959,3 +4973,327 @@ interface outside
 =END=
 
 ############################################################
+=TITLE=Linux as VPN hub
+=INPUT=
+[[topo]]
+service:test = {
+ user = network:lan1;
+ permit src = user; dst = host:netspoc; prt = tcp 80;
+ permit src = user; dst = interface:vpn.intern; prt = tcp 22;
+}
+=SUBST=/IOS/Linux/
+=OUTPUT=
+--vpn
+# [ Routing ]
+ip route add 0.0.0.0/0 via 192.168.0.1
+--
+# [ Crypto ]
+swanctl --load-conns --file /dev/stdin <<EOF
+connections {
+ crypto-dmz-1 {
+  version = 1
+  local_addrs = 192.168.0.101
+  remote_addrs = 172.16.1.2
+  proposals = aes256-sha1-modp1024
+  rekey_time = 43200s
+  local {
+   auth = pubkey
+  }
+  remote {
+   auth = pubkey
+   id = cert@example.com
+  }
+  children {
+   crypto-dmz-1 {
+    local_ts = 0.0.0.0/0
+    remote_ts = 10.99.1.0/24
+    esp_proposals = aes256-sha1-modp1024
+    rekey_time = 3600s
+    rekey_bytes = 102400000
+    start_action = trap
+   }
+  }
+ }
+}
+EOF
+--
+:crypto-dmz-1_self -
+-A crypto-dmz-1_self -j ACCEPT -s 10.99.1.0/24 -d 10.1.1.101 -p tcp --dport 22
+-A INPUT -j crypto-dmz-1_self -i dmz -m policy --dir in --pol ipsec --mode tunnel --tunnel-src 172.16.1.2
+-A INPUT -j droplog -i dmz -m policy --dir in --pol ipsec --mode tunnel --tunnel-src 172.16.1.2
+--
+:crypto-dmz-1 -
+-A crypto-dmz-1 -j ACCEPT -s 10.99.1.0/24 -d 10.1.1.111 -p tcp --dport 80
+-A FORWARD -j crypto-dmz-1 -i dmz -m policy --dir in --pol ipsec --mode tunnel --tunnel-src 172.16.1.2
+-A FORWARD -j droplog -i dmz -m policy --dir in --pol ipsec --mode tunnel --tunnel-src 172.16.1.2
+--
+:intern_self -
+-A INPUT -j intern_self -i intern
+--
+:intern_dmz -
+-A FORWARD -j intern_dmz -i intern -o dmz
+--
+:dmz_self -
+-A dmz_self -g c1 -s 172.16.1.2 -d 192.168.0.101
+-A INPUT -j dmz_self -i dmz
+--
+:dmz_intern -
+-A FORWARD -j dmz_intern -i dmz -o intern
+=END=
+
+############################################################
+=TITLE=Linux as managed VPN spoke with IKEv2
+=INPUT=
+ipsec:aes-gcm-256 = {
+ key_exchange = isakmp:aes-gcm-256-sha-256;
+ esp_encryption = aes-gcm-256;
+ pfs_group = 21;
+ lifetime = 1 hour;
+}
+isakmp:aes-gcm-256-sha-256 = {
+ ike_version = 2;
+ nat_traversal = on;
+ authentication = preshare;
+ encryption = aes-gcm-256;
+ hash = sha256;
+ group = 14;
+ lifetime = 43200 sec;
+}
+crypto:sts = {
+ type = ipsec:aes-gcm-256;
+}
+network:intern = { ip = 10.1.1.0/24; }
+router:asavpn = {
+ model = ASA;
+ managed;
+ interface:intern = {
+  ip = 10.1.1.101;
+  hardware = inside;
+ }
+ interface:dmz = {
+  ip = 192.168.1.1;
+  hub = crypto:sts;
+  hardware = outside;
+ }
+}
+network:dmz = { ip = 192.168.1.0/24; }
+router:vpn1 = {
+ managed;
+ model = Linux;
+ interface:dmz = {
+  ip = 192.168.1.2;
+  spoke = crypto:sts;
+  hardware = eth0;
+ }
+ interface:lan1 = {
+  ip = 10.99.1.1;
+  hardware = eth1;
+ }
+}
+network:lan1 = { ip = 10.99.1.0/24; }
+service:test = {
+ user = network:lan1;
+ permit src = user; dst = network:intern; prt = tcp 80;
+ permit src = network:intern; dst = user; prt = udp 123;
+}
+=OUTPUT=
+--vpn1
+# [ Routing ]
+ip route add 10.1.1.0/24 via 192.168.1.1
+--
+# [ Crypto ]
+swanctl --load-conns --file /dev/stdin <<EOF
+connections {
+ crypto-eth0-1 {
+  version = 2
+  local_addrs = 192.168.1.2
+  remote_addrs = 192.168.1.1
+  proposals = aes256gcm16-prfsha256-modp2048
+  rekey_time = 43200s
+  encap = yes
+  local {
+   auth = psk
+  }
+  remote {
+   auth = psk
+  }
+  children {
+   crypto-eth0-1 {
+    local_ts = 10.99.1.0/24
+    remote_ts = 0.0.0.0/0
+    esp_proposals = aes256gcm16-ecp521
+    rekey_time = 3600s
+    start_action = trap
+   }
+  }
+ }
+}
+EOF
+--
+:crypto-eth0-1_self -
+-A INPUT -j crypto-eth0-1_self -i eth0 -m policy --dir in --pol ipsec --mode tunnel --tunnel-src 192.168.1.1
+-A INPUT -j droplog -i eth0 -m policy --dir in --pol ipsec --mode tunnel --tunnel-src 192.168.1.1
+--
+:crypto-eth0-1 -
+-A crypto-eth0-1 -j ACCEPT -s 10.1.1.0/24 -d 10.99.1.0/24 -p udp --dport 123
+-A FORWARD -j crypto-eth0-1 -i eth0 -m policy --dir in --pol ipsec --mode tunnel --tunnel-src 192.168.1.1
+-A FORWARD -j droplog -i eth0 -m policy --dir in --pol ipsec --mode tunnel --tunnel-src 192.168.1.1
+--
+:eth0_self -
+-A eth0_self -j ACCEPT -s 192.168.1.1 -d 192.168.1.2 -p udp --sport 4500 --dport 4500
+-A INPUT -j eth0_self -i eth0
+--
+:eth0_eth1 -
+-A FORWARD -j eth0_eth1 -i eth0 -o eth1
+--
+:eth1_self -
+-A INPUT -j eth1_self -i eth1
+--
+:eth1_eth0 -
+-A eth1_eth0 -j ACCEPT -s 10.99.1.0/24 -d 10.1.1.0/24 -p tcp --dport 80
+-A FORWARD -j eth1_eth0 -i eth1 -o eth0
+=END=
+
+############################################################
+=TITLE=Linux can't use AH together with ESP
+=INPUT=
+[[topo]]
+=SUBST=/IOS/Linux/
+=SUBST=/pfs_group = 2;/ah = sha256;/
+=ERROR=
+Error: Must not use 'ah' together with 'esp_encryption' or 'esp_authentication' in ipsec:aes256SHA for router:vpn
+=END=
+
+############################################################
+=TITLE=Check crypto policy
+=TEMPL=input
+ipsec:aes256SHA = {
+ key_exchange = isakmp:aes256SHA;
+ esp_encryption = aes256;
+ esp_authentication = sha384;
+ pfs_group = 15;
+ lifetime = 3600 sec;
+}
+isakmp:aes256SHA = {
+ ike_version = 1;
+ authentication = rsasig;
+ encryption = aes256;
+ hash = sha;
+ group = 15;
+ lifetime = 43200 sec;
+ trust_point = ASDM_TrustPoint3;
+}
+ipsec:3desSHA = {
+ key_exchange = isakmp:3desSHA;
+ esp_encryption = 3des;
+ esp_authentication = sha;
+ pfs_group = 2;
+ lifetime = 600 sec;
+}
+isakmp:3desSHA = {
+ ike_version = 1;
+ authentication = preshare;
+ encryption = 3des;
+ hash = sha;
+ group = 2;
+ lifetime = 86400 sec;
+}
+crypto:sts1 = {
+ type = ipsec:aes256SHA;
+}
+crypto:sts2 = {
+ type = ipsec:3desSHA;
+}
+network:intern = { ip = 10.1.1.0/24; }
+router:asavpn = {
+ model = ASA;
+ managed;
+ interface:intern = { ip = 10.1.1.101; hardware = inside; }
+ interface:dmz = {
+  ip = 192.168.0.101;
+  hub = crypto:sts1, crypto:sts2;
+  hardware = outside;
+ }
+}
+network:dmz = { ip = 192.168.0.0/24; }
+router:extern = {
+ interface:dmz = { ip = 192.168.0.1; }
+ interface:internet;
+}
+network:internet = { ip = 0.0.0.0/0; has_subnets; }
+router:vpn1 = {
+ interface:internet = {
+  ip = 172.16.1.2;
+  id = cert@example.com;
+  spoke = crypto:sts1;
+ }
+ interface:lan1 = { ip = 10.99.1.1; }
+}
+network:lan1 = { ip = 10.99.1.0/24; }
+router:vpn2 = {
+ interface:internet = { ip = 172.16.2.2; spoke = crypto:sts2; }
+ interface:lan2 = { ip = 10.99.2.1; }
+}
+network:lan2 = { ip = 10.99.2.0/24; }
+=INPUT=[[input]]
+=OPTIONS=
+--check_crypto_policy=warn
+--crypto_policy_encryption=aes192,aes256,aes-gcm-256
+--crypto_policy_hash=sha256,sha384,sha512
+--crypto_policy_min_group=14
+--crypto_policy_min_ike_version=2
+--crypto_policy_min_isakmp_lifetime=3600
+--crypto_policy_min_ipsec_lifetime=3600
+=WARNING=
+Warning: crypto:sts1 doesn't comply with crypto policy:
+ isakmp:aes256SHA: ike_version = 1, hash = sha
+ Used at:
+ - router:asavpn
+ - router:vpn1
+Warning: crypto:sts2 doesn't comply with crypto policy:
+ ipsec:3desSHA: esp_encryption = 3des, esp_authentication = sha, pfs_group = 2, lifetime = 600 sec
+ isakmp:3desSHA: ike_version = 1, encryption = 3des, hash = sha, group = 2
+ Used at:
+ - router:asavpn
+ - router:vpn2
+=END=
+
+############################################################
+=TITLE=Crypto policy violation is error
+=INPUT=[[input]]
+=OPTIONS=
+--check_crypto_policy=err
+--crypto_policy_min_group=14
+=ERROR=
+Error: crypto:sts2 doesn't comply with crypto policy:
+ ipsec:3desSHA: pfs_group = 2
+ isakmp:3desSHA: group = 2
+ Used at:
+ - router:asavpn
+ - router:vpn2
+=END=
+
+############################################################
+=TITLE=Crypto policy read from config file
+=INPUT=
+-- config
+check_crypto_policy = warn;
+crypto_policy_encryption = aes192, aes256;
+crypto_policy_min_ike_version = 2;
+-- topology
+[[input]]
+=WARNING=
+Warning: crypto:sts1 doesn't comply with crypto policy:
+ isakmp:aes256SHA: ike_version = 1
+ Used at:
+ - router:asavpn
+ - router:vpn1
+Warning: crypto:sts2 doesn't comply with crypto policy:
+ ipsec:3desSHA: esp_encryption = 3des
+ isakmp:3desSHA: ike_version = 1, encryption = 3des
+ Used at:
+ - router:asavpn
+ - router:vpn2
+=END=
+
+############################################################
@@ -84,6 +84,68 @@ access-group n1_in in interface n1
 [[output]]
 =END=
 
+############################################################
+=TITLE=Service enabled today
+=TEMPL=enabled_service
+service:s = {
+ enable_at = {{DATE .}};
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+=INPUT=
+[[topo]]
+[[enabled_service -0]]
+=OUTPUT=
+[[output]]
+=END=
+
+=TITLE=Service was enabled 10 days ago
+=INPUT=
+[[topo]]
+[[enabled_service -10]]
+=OUTPUT=
+[[output]]
+=END=
+
+############################################################
+=TITLE=Service will be enabled tomorrow
+=INPUT=
+[[topo]]
+[[enabled_service 1]]
+=OUTPUT=
+--r1
+! n1_in
+access-list n1_in extended deny ip any4 any4
+access-group n1_in in interface n1
+=END=
+
+############################################################
+=TITLE=Warn on expiring service
+=TEMPL=date
+{{DATE . -}}
+=INPUT=
+[[topo]]
+service:s1 = {
+ disable_at = [[date 3]];
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s2 = {
+ disable_at = [[date 7]];
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 81;
+}
+service:s3 = {
+ disable_at = [[date 8]];
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 82;
+}
+=OPTIONS=--warn_expiring_days=7
+=WARNING=
+Warning: service:s1 will be disabled in 3 day(s) at [[date 3]]
+Warning: service:s2 will be disabled in 7 day(s) at [[date 7]]
+=END=
+
 ############################################################
 =TITLE=Invalid date format at service
 =INPUT=
@@ -111,3 +173,51 @@ Error: Invalid date in 'disable_at' of service:s: parsing time "2031-31-31": mon
 =END=
 
 ############################################################
+=TITLE=Invalid date in enable_at
+=INPUT=
+[[topo]]
+service:s = {
+ enable_at = 2031-31-31;
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+=ERROR=
+Error: Invalid date in 'enable_at' of service:s: parsing time "2031-31-31": month out of range
+=END=
+
+############################################################
+=TITLE=Invalid date is reported once with --warn_expiring_days
+=INPUT=
+[[topo]]
+service:s = {
+ disable_at = 2031-31-31;
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+=OPTIONS=--warn_expiring_days=7
+=ERROR=
+Error: Invalid date in 'disable_at' of service:s: parsing time "2031-31-31": month out of range
+=END=
+
+############################################################
+=TITLE=enable_at must be before disable_at
+=INPUT=
+[[topo]]
+service:s1 = {
+ enable_at = 2031-02-01;
+ disable_at = 2031-01-31;
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s2 = {
+ enable_at = 2031-02-01;
+ disable_at = 2031-02-01;
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 81;
+}
+=ERROR=
+Error: 'enable_at' must be before 'disable_at' in service:s1
+Error: 'enable_at' must be before 'disable_at' in service:s2
+=END=
+
+############################################################
@@ -5,7 +5,8 @@
 =PARAMS=-h
 =ERROR=
 Usage: PROGRAM [options] netspoc-data [TYPE:NAME|TYPE: ...]
-  -q, --quiet   Flag is ignored
+  -p, --position   Add position in source file to each definition
+  -q, --quiet      Flag is ignored
 =END=
 
 ############################################################
@@ -21,7 +22,8 @@ Error: unknown flag: --abc
 =INPUT=NONE
 =ERROR=
 Usage: PROGRAM [options] netspoc-data [TYPE:NAME|TYPE: ...]
-  -q, --quiet   Flag is ignored
+  -p, --position   Add position in source file to each definition
+  -q, --quiet      Flag is ignored
 =END=
 
 ############################################################
@@ -364,3 +366,87 @@ service:s1 = {
  ]
 }
 =END=
+
+############################################################
+=TITLE=With position
+=INPUT=
+--a
+network:n1 = {
+ ip = 10.1.1.0/24;
+ host:h1 = { ip = 10.1.1.10; }
+}
+--b
+router:r1 = {
+ interface:n1 = { ip = 10.1.1.1; }
+}
+service:s1 = {
+ user = host:h1;
+ permit src = user; dst = interface:r1.n1; prt = tcp 22;
+}
+=PARAMS=--position
+=OUTPUT=
+{
+ "network": [
+  {
+   "hosts": {
+    "host:h1": {
+     "ip": [ "10.1.1.10" ],
+     "position": {
+      "end": { "column": 31, "line": 3, "offset": 64 },
+      "start": { "column": 2, "line": 3, "offset": 35 }
+     }
+    }
+   },
+   "ip": [ "10.1.1.0/24" ],
+   "name": "network:n1",
+   "position": {
+    "end": { "column": 2, "line": 4, "offset": 66 },
+    "file": "a",
+    "start": { "column": 1, "line": 1, "offset": 0 }
+   }
+  }
+ ],
+ "router": [
+  {
+   "interfaces": {
+    "interface:n1": {
+     "ip": [ "10.1.1.1" ],
+     "position": {
+      "end": { "column": 35, "line": 2, "offset": 48 },
+      "start": { "column": 2, "line": 2, "offset": 15 }
+     }
+    }
+   },
+   "name": "router:r1",
+   "position": {
+    "end": { "column": 2, "line": 3, "offset": 50 },
+    "file": "b",
+    "start": { "column": 1, "line": 1, "offset": 0 }
+   }
+  }
+ ],
+ "service": [
+  {
+   "name": "service:s1",
+   "position": {
+    "end": { "column": 2, "line": 7, "offset": 141 },
+    "file": "b",
+    "start": { "column": 1, "line": 4, "offset": 51 }
+   },
+   "rules": [
+    {
+     "action": "permit",
+     "dst": [ "interface:r1.n1" ],
+     "position": {
+      "end": { "column": 57, "line": 6, "offset": 139 },
+      "start": { "column": 2, "line": 6, "offset": 84 }
+     },
+     "prt": [ "tcp 22" ],
+     "src": [ "user" ]
+    }
+   ],
+   "user": [ "host:h1" ]
+  }
+ ]
+}
+=END=
@@ -3462,6 +3462,7 @@ service:s1 = {
 }
 service:s2 = {
  disable_at = 3000-12-31;
+ enable_at = 2000-01-01;
  user = network:n1;
  permit src = user; dst = network:n2; prt = tcp 81;
 }
@@ -3509,6 +3510,7 @@ service:s3 = {
  "s2": {
   "details": {
    "disable_at": "3000-12-31",
+   "enable_at": "2000-01-01",
    "owner": [
     "all"
    ]
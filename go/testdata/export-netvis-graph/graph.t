@@ -0,0 +1,320 @@
+############################################################
+=TEMPL=topo
+area:a1 = { border = interface:r1.n2; }
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:cl = { ip = 10.9.9.0/29; crosslink; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+ interface:cl = { ip = 10.9.9.1; hardware = cl; }
+}
+router:r2 = {
+ managed;
+ model = IOS;
+ interface:cl = { ip = 10.9.9.2; hardware = cl; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+router:u = {
+ interface:n2 = { ip = 10.1.2.2; }
+ interface:n4;
+}
+network:n4 = { ip = 10.1.4.0/24; }
+=END=
+
+############################################################
+=TITLE=DOT with zones, areas and crosslink network
+=INPUT=[[topo]]
+=PARAMS=--format=dot
+=OUTPUT=
+graph netspoc {
+ subgraph "cluster_any:[network:cl]" {
+  label="any:[network:cl]";
+  style=dotted;
+  "network:cl" [shape=ellipse, label="network:cl\n10.9.9.0/29", style=dashed, xlabel="crosslink"];
+ }
+ subgraph "cluster_any:[network:n1]" {
+  label="any:[network:n1]";
+  style=dotted;
+  "network:n1" [shape=ellipse, label="network:n1\n10.1.1.0/24"];
+ }
+ subgraph "cluster_any:[network:n3]" {
+  label="any:[network:n3]";
+  style=dotted;
+  "network:n3" [shape=ellipse, label="network:n3\n10.1.3.0/24"];
+ }
+ subgraph "cluster_area:a1" {
+  label="area:a1";
+  subgraph "cluster_any:[network:n2]" {
+   label="any:[network:n2]";
+   style=dotted;
+   "network:n2" [shape=ellipse, label="network:n2\n10.1.2.0/24"];
+   "network:n4" [shape=ellipse, label="network:n4\n10.1.4.0/24"];
+   "router:u" [shape=box];
+  }
+ }
+ "router:r1" [shape=box, label="router:r1\nASA", style=filled, fillcolor=lightcoral];
+ "router:r2" [shape=box, label="router:r2\nIOS", style=filled, fillcolor=lightblue];
+ "network:cl" -- "router:r1";
+ "network:cl" -- "router:r2";
+ "network:n1" -- "router:r1";
+ "network:n2" -- "router:r1";
+ "network:n2" -- "router:u";
+ "network:n3" -- "router:r2";
+ "network:n4" -- "router:u";
+}
+=END=
+
+############################################################
+=TITLE=GraphML with zones, areas and crosslink network
+=INPUT=[[topo]]
+=PARAMS=--format=graphml
+=OUTPUT=
+<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+ <key id="type" for="node" attr.name="type" attr.type="string"/>
+ <key id="address" for="node" attr.name="address" attr.type="string"/>
+ <key id="model" for="node" attr.name="model" attr.type="string"/>
+ <key id="crosslink" for="node" attr.name="crosslink" attr.type="boolean"/>
+ <key id="is_tunnel" for="edge" attr.name="is_tunnel" attr.type="boolean"/>
+ <graph id="netspoc" edgedefault="undirected">
+  <node id="any:[network:cl]">
+   <data key="type">zone</data>
+   <graph id="any:[network:cl]:" edgedefault="undirected">
+    <node id="network:cl">
+     <data key="type">network</data>
+     <data key="address">10.9.9.0/29</data>
+     <data key="crosslink">true</data>
+    </node>
+   </graph>
+  </node>
+  <node id="any:[network:n1]">
+   <data key="type">zone</data>
+   <graph id="any:[network:n1]:" edgedefault="undirected">
+    <node id="network:n1">
+     <data key="type">network</data>
+     <data key="address">10.1.1.0/24</data>
+    </node>
+   </graph>
+  </node>
+  <node id="any:[network:n3]">
+   <data key="type">zone</data>
+   <graph id="any:[network:n3]:" edgedefault="undirected">
+    <node id="network:n3">
+     <data key="type">network</data>
+     <data key="address">10.1.3.0/24</data>
+    </node>
+   </graph>
+  </node>
+  <node id="area:a1">
+   <data key="type">area</data>
+   <graph id="area:a1:" edgedefault="undirected">
+    <node id="any:[network:n2]">
+     <data key="type">zone</data>
+     <graph id="any:[network:n2]:" edgedefault="undirected">
+      <node id="network:n2">
+       <data key="type">network</data>
+       <data key="address">10.1.2.0/24</data>
+      </node>
+      <node id="network:n4">
+       <data key="type">network</data>
+       <data key="address">10.1.4.0/24</data>
+      </node>
+      <node id="router:u">
+       <data key="type">router</data>
+      </node>
+     </graph>
+    </node>
+   </graph>
+  </node>
+  <node id="router:r1">
+   <data key="type">router: standard</data>
+   <data key="model">ASA</data>
+  </node>
+  <node id="router:r2">
+   <data key="type">router: standard</data>
+   <data key="model">IOS</data>
+  </node>
+  <edge source="network:cl" target="router:r1"/>
+  <edge source="network:cl" target="router:r2"/>
+  <edge source="network:n1" target="router:r1"/>
+  <edge source="network:n2" target="router:r1"/>
+  <edge source="network:n2" target="router:u"/>
+  <edge source="network:n3" target="router:r2"/>
+  <edge source="network:n4" target="router:u"/>
+ </graph>
+</graphml>
+=END=
+
+############################################################
+=TITLE=DOT limited to area
+=INPUT=[[topo]]
+=PARAMS=--format=dot --area=area:a1
+=OUTPUT=
+graph netspoc {
+ subgraph "cluster_area:a1" {
+  label="area:a1";
+  subgraph "cluster_any:[network:n2]" {
+   label="any:[network:n2]";
+   style=dotted;
+   "network:n2" [shape=ellipse, label="network:n2\n10.1.2.0/24"];
+   "network:n4" [shape=ellipse, label="network:n4\n10.1.4.0/24"];
+   "router:u" [shape=box];
+  }
+ }
+ "router:r1" [shape=box, label="router:r1\nASA", style=filled, fillcolor=lightcoral];
+ "network:n2" -- "router:r1";
+ "network:n2" -- "router:u";
+ "network:n4" -- "router:u";
+}
+=END=
+
+############################################################
+=TITLE=DOT limited to path
+=INPUT=[[topo]]
+=PARAMS=--format=dot --from=network:n4 --to=network:n3
+=OUTPUT=
+graph netspoc {
+ subgraph "cluster_any:[network:cl]" {
+  label="any:[network:cl]";
+  style=dotted;
+  "network:cl" [shape=ellipse, label="network:cl\n10.9.9.0/29", style=dashed, xlabel="crosslink"];
+ }
+ subgraph "cluster_any:[network:n3]" {
+  label="any:[network:n3]";
+  style=dotted;
+  "network:n3" [shape=ellipse, label="network:n3\n10.1.3.0/24"];
+ }
+ subgraph "cluster_area:a1" {
+  label="area:a1";
+  subgraph "cluster_any:[network:n2]" {
+   label="any:[network:n2]";
+   style=dotted;
+   "network:n2" [shape=ellipse, label="network:n2\n10.1.2.0/24"];
+   "network:n4" [shape=ellipse, label="network:n4\n10.1.4.0/24"];
+   "router:u" [shape=box];
+  }
+ }
+ "router:r1" [shape=box, label="router:r1\nASA", style=filled, fillcolor=lightcoral];
+ "router:r2" [shape=box, label="router:r2\nIOS", style=filled, fillcolor=lightblue];
+ "network:cl" -- "router:r1";
+ "network:cl" -- "router:r2";
+ "network:n2" -- "router:r1";
+ "network:n2" -- "router:u";
+ "network:n3" -- "router:r2";
+ "network:n4" -- "router:u";
+}
+=END=
+
+############################################################
+=TEMPL=tunnel
+ipsec:aes256SHA = {
+ key_exchange = isakmp:aes256SHA; esp_encryption = aes256;
+ esp_authentication = sha; pfs_group = 2; lifetime = 600 sec;
+}
+isakmp:aes256SHA = {
+ authentication = rsasig; encryption = aes256;
+ hash = sha; group = 2; lifetime = 86400 sec;
+}
+crypto:vpn = {type = ipsec:aes256SHA;}
+router:asavpn = {
+ model = ASA, VPN;
+ managed;
+ general_permit = icmp 3;
+ vpn_attributes = {
+  trust-point = ASDM_TrustPoint1;
+ }
+ interface:dmz = {
+  ip = 192.168.0.101;
+  hub = crypto:vpn;
+  hardware = outside;
+ }
+}
+network:dmz = { ip = 192.168.0.0/24; }
+router:extern = {
+ interface:dmz = { ip = 192.168.0.1; }
+ interface:internet;
+}
+network:internet = {
+ ip = 0.0.0.0/0;
+ has_subnets;
+}
+router:softclients = {
+ interface:internet = {
+  spoke = crypto:vpn;
+ }
+}
+=END=
+
+############################################################
+=TITLE=DOT with tunnel
+=INPUT=[[tunnel]]
+=PARAMS=--format=dot
+=OUTPUT=
+graph netspoc {
+ subgraph "cluster_any:[network:dmz]" {
+  label="any:[network:dmz]";
+  style=dotted;
+  "network:dmz" [shape=ellipse, label="network:dmz\n192.168.0.0/24"];
+  "network:internet" [shape=ellipse, label="network:internet\n0.0.0.0/0"];
+  "router:extern" [shape=box];
+  "router:softclients" [shape=box];
+ }
+ "router:asavpn" [shape=box, label="router:asavpn\nASA", style=filled, fillcolor=lightcoral];
+ "network:dmz" -- "router:asavpn";
+ "network:dmz" -- "router:extern";
+ "network:internet" -- "router:extern";
+ "network:internet" -- "router:softclients";
+ "router:asavpn" -- "router:softclients" [style=dashed, label="tunnel"];
+}
+=END=
+
+############################################################
+=TITLE=GraphML with tunnel
+=INPUT=[[tunnel]]
+=PARAMS=--format=graphml
+=OUTPUT=
+<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+ <key id="type" for="node" attr.name="type" attr.type="string"/>
+ <key id="address" for="node" attr.name="address" attr.type="string"/>
+ <key id="model" for="node" attr.name="model" attr.type="string"/>
+ <key id="crosslink" for="node" attr.name="crosslink" attr.type="boolean"/>
+ <key id="is_tunnel" for="edge" attr.name="is_tunnel" attr.type="boolean"/>
+ <graph id="netspoc" edgedefault="undirected">
+  <node id="any:[network:dmz]">
+   <data key="type">zone</data>
+   <graph id="any:[network:dmz]:" edgedefault="undirected">
+    <node id="network:dmz">
+     <data key="type">network</data>
+     <data key="address">192.168.0.0/24</data>
+    </node>
+    <node id="network:internet">
+     <data key="type">network</data>
+     <data key="address">0.0.0.0/0</data>
+    </node>
+    <node id="router:extern">
+     <data key="type">router</data>
+    </node>
+    <node id="router:softclients">
+     <data key="type">router</data>
+    </node>
+   </graph>
+  </node>
+  <node id="router:asavpn">
+   <data key="type">router: standard</data>
+   <data key="model">ASA</data>
+  </node>
+  <edge source="network:dmz" target="router:asavpn"/>
+  <edge source="network:dmz" target="router:extern"/>
+  <edge source="network:internet" target="router:extern"/>
+  <edge source="network:internet" target="router:softclients"/>
+  <edge source="router:asavpn" target="router:softclients">
+   <data key="is_tunnel">true</data>
+  </edge>
+ </graph>
+</graphml>
+=END=
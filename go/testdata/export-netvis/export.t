@@ -4,7 +4,11 @@
 =PARAMS=-h
 =ERROR=
 Usage: PROGRAM [options] FILE|DIR
-  -q, --quiet   Don't print progress messages
+  -a, --area string     Export only objects of this area
+  -f, --format string   Output format: json, dot or graphml (default "json")
+      --from string     Export only path starting at this object
+  -q, --quiet           Don't print progress messages
+      --to string       Export only path ending at this object
 =END=
 
 ############################################################
@@ -20,7 +24,11 @@ Error: unknown flag: --abc
 =INPUT=NONE
 =ERROR=
 Usage: PROGRAM [options] FILE|DIR
-  -q, --quiet   Don't print progress messages
+  -a, --area string     Export only objects of this area
+  -f, --format string   Output format: json, dot or graphml (default "json")
+      --from string     Export only path starting at this object
+  -q, --quiet           Don't print progress messages
+      --to string       Export only path ending at this object
 =END=
 
 ############################################################
@@ -380,4 +388,133 @@ router:r1 = {
     }
   }
 }
-=END=
\ No newline at end of file
+=END=
+############################################################
+=TITLE=Unknown format
+=INPUT=#
+=PARAMS=--format=svg
+=ERROR=
+Error: Unknown format 'svg'
+=END=
+
+############################################################
+=TITLE=Option --from without --to
+=INPUT=#
+=PARAMS=--from=network:n1
+=ERROR=
+Error: Options --from and --to must be used together
+=END=
+
+############################################################
+=TITLE=Unknown area
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+=PARAMS=--area=area:a1
+=ERROR=
+Error: Unknown area:a1
+Aborted
+=END=
+
+############################################################
+=TEMPL=topo
+area:a1 = { border = interface:r1.n2; }
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+router:r2 = {
+ interface:n2 = { ip = 10.1.2.2; }
+ interface:n3;
+}
+=END=
+
+############################################################
+=TITLE=Limit to area
+=INPUT=[[topo]]
+=PARAMS=--area=area:a1
+=OUTPUT=
+{
+ "network": {
+  "network:n2": {
+   "id": "network:n2",
+   "type": "network",
+   "in_area": "area:a1",
+   "address": "10.1.2.0/24",
+   "neighbors": [
+    {"id": "router:r1", "neighbor_count": 2},
+    {"id": "router:r2", "neighbor_count": 2}
+   ],
+   "hosts": null
+  },
+  "network:n3": {
+   "id": "network:n3",
+   "type": "network",
+   "in_area": "area:a1",
+   "address": "10.1.3.0/24",
+   "neighbors": [{"id": "router:r2", "neighbor_count": 2}],
+   "hosts": null
+  }
+ },
+ "router": {
+  "router:r1": {
+   "id": "router:r1",
+   "type": "router: standard",
+   "neighbors": [
+    {"id": "network:n2", "neighbor_count": 2},
+    {"id": "area:a1", "neighbor_count": 1}
+   ]
+  },
+  "router:r2": {
+   "id": "router:r2",
+   "type": "router",
+   "in_area": "area:a1",
+   "neighbors": [
+    {"id": "network:n2", "neighbor_count": 2},
+    {"id": "network:n3", "neighbor_count": 1}
+   ]
+  }
+ }
+}
+=END=
+
+############################################################
+=TITLE=Limit to path
+=INPUT=[[topo]]
+=PARAMS=--from=network:n1 --to=network:n2
+=OUTPUT=
+{
+ "network": {
+  "network:n1": {
+   "id": "network:n1",
+   "type": "network",
+   "address": "10.1.1.0/24",
+   "neighbors": [{"id": "router:r1", "neighbor_count": 2}],
+   "hosts": null
+  },
+  "network:n2": {
+   "id": "network:n2",
+   "type": "network",
+   "in_area": "area:a1",
+   "address": "10.1.2.0/24",
+   "neighbors": [{"id": "router:r1", "neighbor_count": 2}],
+   "hosts": null
+  }
+ },
+ "router": {
+  "router:r1": {
+   "id": "router:r1",
+   "type": "router: standard",
+   "neighbors": [
+    {"id": "network:n1", "neighbor_count": 1},
+    {"id": "network:n2", "neighbor_count": 2},
+    {"id": "area:a1", "neighbor_count": 1}
+   ]
+  }
+ }
+}
+=END=
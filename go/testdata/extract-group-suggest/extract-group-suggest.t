@@ -0,0 +1,24 @@
+############################################################
+=TITLE=Suggest groups
+=INPUT=
+group:g1 = host:a, host:b, any:[network:n1, network:n2];
+service:s1 = {
+ user = host:a, host:b, any:[network:n1, network:n2];
+ permit src = user; dst = network:n1, network:n2; prt = tcp 80;
+}
+service:s2 = {
+ user = network:n1, network:n2;
+ permit src = user; dst = host:c, host:b, host:a; prt = tcp 22;
+}
+=OPTIONS=--suggest --min_size=2
+=OUTPUT=
+# Existing group:g1 can be used at
+# - service:s1 user
+# New group can be used at
+# - service:s1 dst
+# - service:s2 user
+group:s1-dst =
+ network:n1,
+ network:n2,
+;
+=END=
@@ -0,0 +1,191 @@
+############################################################
+=TITLE=Option '-h'
+=INPUT=NONE
+=PARAMS=-h
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR
+      --min_count int   Extract only lists, that occur at least this many times (default 2)
+      --min_size int    Extract only lists with at least this many elements (default 5)
+  -q, --quiet           Don't show changed files
+      --suggest         Don't change files, but print suggested groups
+=END=
+
+############################################################
+=TITLE=No parameters
+=INPUT=NONE
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR
+      --min_count int   Extract only lists, that occur at least this many times (default 2)
+      --min_size int    Extract only lists with at least this many elements (default 5)
+  -q, --quiet           Don't show changed files
+      --suggest         Don't change files, but print suggested groups
+=END=
+
+############################################################
+=TITLE=Invalid input
+=INPUT=
+invalid
+=ERROR=
+Error: Typed name expected at line 1 of INPUT, near "--HERE-->invalid"
+=END=
+
+############################################################
+=TITLE=Extract repeated lists of services
+=INPUT=
+service:s1 = {
+ user = host:a, host:b, host:c;
+ permit src = user; dst = network:n1, network:n2; prt = tcp 80;
+}
+service:s2 = {
+ user = network:n1, network:n2;
+ permit src = user; dst = host:c, host:b, host:a; prt = tcp 22;
+ permit src = host:a, host:b, host:c; dst = user; prt = tcp 23;
+}
+service:s3 = {
+ user = host:a, host:b;
+ permit src = user; dst = network:n1, network:n2, network:n3; prt = tcp 80;
+}
+=OPTIONS=--min_size=2
+=OUTPUT=
+service:s1 = {
+ user = group:s1-user;
+ permit src = user;
+        dst = group:s1-dst;
+        prt = tcp 80;
+}
+service:s2 = {
+ user = group:s1-dst;
+ permit src = user;
+        dst = group:s1-user;
+        prt = tcp 22;
+ permit src = group:s1-user;
+        dst = user;
+        prt = tcp 23;
+}
+service:s3 = {
+ user = host:a,
+        host:b,
+        ;
+ permit src = user;
+        dst = network:n1,
+              network:n2,
+              network:n3,
+              ;
+        prt = tcp 80;
+}
+group:s1-dst =
+ network:n1,
+ network:n2,
+;
+group:s1-user =
+ host:a,
+ host:b,
+ host:c,
+;
+=END=
+
+############################################################
+=TITLE=Reuse existing group
+=INPUT=
+-- groups
+group:g1 =
+ host:a,
+ host:b,
+ host:c,
+;
+group:g2 = host:c, host:a, host:b;
+-- rules
+service:s1 = {
+ user = host:a, host:b, host:c;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s2 = {
+ user = foreach host:a, host:b, host:c;
+ permit src = user; dst = network:n1; prt = tcp 22;
+}
+=OPTIONS=--min_size=3
+=OUTPUT=
+-- groups
+group:g1 =
+ host:a,
+ host:b,
+ host:c,
+;
+group:g2 =
+ group:g1,
+;
+-- rules
+service:s1 = {
+ user = group:g1;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 80;
+}
+service:s2 = {
+ user = foreach
+  host:a,
+  host:b,
+  host:c,
+ ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 22;
+}
+=END=
+
+############################################################
+=TITLE=Minimum count
+=INPUT=
+-- rules
+service:s1 = {
+ user = host:a, host:b;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s2 = {
+ user = host:a, host:b;
+ permit src = user; dst = network:n1; prt = tcp 22;
+}
+-- rules2
+service:s3 = {
+ user = host:b, host:c;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s4 = {
+ user = host:b, host:a;
+ permit src = user; dst = network:n1; prt = tcp 22;
+}
+=OPTIONS=--min_size=2 --min_count=3
+=OUTPUT=
+-- rules
+service:s1 = {
+ user = group:s1-user;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 80;
+}
+service:s2 = {
+ user = group:s1-user;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 22;
+}
+group:s1-user =
+ host:a,
+ host:b,
+;
+-- rules2
+service:s3 = {
+ user = host:b,
+        host:c,
+        ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 80;
+}
+service:s4 = {
+ user = group:s1-user;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 22;
+}
+=END=
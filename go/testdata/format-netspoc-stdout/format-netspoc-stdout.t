@@ -0,0 +1,139 @@
+############################################################
+=TITLE=Show differences of single file
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24;
+ host:h2 = { ip = 10.1.1.2; }
+ host:h1 = { ip = 10.1.1.1; }
+}
+group:g1 = network:n1;
+=OPTIONS=--diff
+=OUTPUT=
+--- a/INPUT
++++ b/INPUT
+@@ -1,5 +1,9 @@
+-network:n1 = { ip = 10.1.1.0/24;
+- host:h2 = { ip = 10.1.1.2; }
++network:n1 = {
++ ip = 10.1.1.0/24;
+  host:h1 = { ip = 10.1.1.1; }
++ host:h2 = { ip = 10.1.1.2; }
+ }
+-group:g1 = network:n1;
++
++group:g1 =
++ network:n1,
++;
+=END=
+
+############################################################
+=TITLE=Show differences of files in directory
+=INPUT=
+-- a
+network:n1 = { ip = 10.1.1.0/24; }
+-- b
+network:n2 = { ip = 10.1.2.0/24;
+}
+-- c
+group:g1 = network:n1;
+
+group:g2 =
+ network:n2,
+;
+
+group:g3 =
+ network:n3,
+;
+
+group:g4 =
+ network:n4,
+;
+
+group:g5 = network:n5;
+=OPTIONS=--diff
+=OUTPUT=
+--- a/INPUT/b
++++ b/INPUT/b
+@@ -1,2 +1 @@
+-network:n2 = { ip = 10.1.2.0/24;
+-}
++network:n2 = { ip = 10.1.2.0/24; }
+--- a/INPUT/c
++++ b/INPUT/c
+@@ -1,4 +1,6 @@
+-group:g1 = network:n1;
++group:g1 =
++ network:n1,
++;
+ 
+ group:g2 =
+  network:n2,
+@@ -12,4 +14,6 @@
+  network:n4,
+ ;
+ 
+-group:g5 = network:n5;
++group:g5 =
++ network:n5,
++;
+=END=
+
+############################################################
+=TITLE=Read from STDIN and write to STDOUT
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24;
+ host:h2 = { ip = 10.1.1.2; }
+ host:h1 = { ip = 10.1.1.1; }
+}
+=PARAMS=-
+=OUTPUT=
+network:n1 = {
+ ip = 10.1.1.0/24;
+ host:h1 = { ip = 10.1.1.1; }
+ host:h2 = { ip = 10.1.1.2; }
+}
+=END=
+
+############################################################
+=TITLE=Show differences of STDIN
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24;
+}
+=OPTIONS=--diff
+=PARAMS=-
+=OUTPUT=
+--- a/<stdin>
++++ b/<stdin>
+@@ -1,2 +1 @@
+-network:n1 = { ip = 10.1.1.0/24;
+-}
++network:n1 = { ip = 10.1.1.0/24; }
+=END=
+
+############################################################
+=TITLE=Check unformatted STDIN
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24;
+}
+=OPTIONS=--check
+=PARAMS=-
+=ERROR=
+Unformatted <stdin>
+=OUTPUT=NONE
+
+############################################################
+=TITLE=Check formatted STDIN
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+=OPTIONS=--check
+=PARAMS=-
+=WARNING=NONE
+=OUTPUT=NONE
+
+############################################################
+=TITLE=Syntax error in STDIN
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24;
+=PARAMS=-
+=ERROR=
+Error: Expected something at line 1 of <stdin>, at EOF
+=END=
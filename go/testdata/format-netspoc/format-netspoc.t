@@ -4,7 +4,9 @@
 =INPUT=NONE
 =PARAMS=-h
 =ERROR=
-Usage: PROGRAM [options] FILE|DIR
+Usage: PROGRAM [options] FILE|DIR|-
+      --check   Don't change files, but show unformatted files and fail
+      --diff    Don't change files, but show differences
   -q, --quiet   Don't show changed files
 =END=
 
@@ -12,7 +14,9 @@ Usage: PROGRAM [options] FILE|DIR
 =TITLE=No parameters
 =INPUT=NONE
 =ERROR=
-Usage: PROGRAM [options] FILE|DIR
+Usage: PROGRAM [options] FILE|DIR|-
+      --check   Don't change files, but show unformatted files and fail
+      --diff    Don't change files, but show differences
   -q, --quiet   Don't show changed files
 =END=
 
@@ -29,7 +33,9 @@ Error: unknown shorthand flag: 'x' in -x
 =INPUT=#
 =PARAMS=other_arg
 =ERROR=
-Usage: PROGRAM [options] FILE|DIR
+Usage: PROGRAM [options] FILE|DIR|-
+      --check   Don't change files, but show unformatted files and fail
+      --diff    Don't change files, but show differences
   -q, --quiet   Don't show changed files
 =END=
 
@@ -141,6 +147,24 @@ group:g1 =
 ;
 =END=
 
+############################################################
+=TITLE=Intersection starting with complement
+=INPUT=
+service:s1 = {
+ user = !group:g1 & group:g2;
+ permit src = user; dst = network:n1; prt = tcp;
+}
+=OUTPUT=
+service:s1 = {
+ user = ! group:g1
+        & group:g2
+        ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp;
+}
+=END=
+
 ############################################################
 =TITLE=Short automatic groups
 =INPUT=
@@ -1542,6 +1566,22 @@ foo = bar;
 xxx
 =WARNING=NONE
 
+############################################################
+=TITLE=Invalid format keys and values in config file
+=INPUT=
+--config
+foo = bar;
+format_indent = x;
+format_sort_hosts = 0;
+format_foo = 1;
+format_sort_elements = maybe;
+=ERROR=
+Error: Invalid line in config:
+ - bad keyword 'format_foo'
+ - bad value in 'format_indent = x'
+ - bad value in 'format_sort_elements = maybe'
+=END=
+
 ############################################################
 =TITLE=Can't change readonly file
 =INPUT=
@@ -1570,3 +1610,222 @@ service:s1 = {
         prt = tcp 80;
 }
 =END=
+
+############################################################
+=TITLE=Check for unformatted files
+=INPUT=
+-- a
+network:n1 = { ip = 10.1.1.0/24; }
+-- b
+network:n2 = { ip = 10.1.2.0/24;
+}
+-- c
+network:n3 = {
+ ip = 10.1.3.0/24; }
+=OPTIONS=--check
+=ERROR=
+Unformatted b
+Unformatted c
+=OUTPUT=
+-- a
+network:n1 = { ip = 10.1.1.0/24; }
+-- b
+network:n2 = { ip = 10.1.2.0/24;
+}
+-- c
+network:n3 = {
+ ip = 10.1.3.0/24; }
+=END=
+
+############################################################
+=TITLE=Check formatted file
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+=OPTIONS=--check
+=WARNING=NONE
+=OUTPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+=END=
+
+############################################################
+=TITLE=Show differences, don't change file
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24;
+}
+=OPTIONS=--diff
+=WARNING=NONE
+=OUTPUT=
+network:n1 = { ip = 10.1.1.0/24;
+}
+=END=
+
+############################################################
+=TITLE=Indent from config file
+=INPUT=
+-- config
+format_indent = 4;
+-- topo
+network:n1 = { ip = 10.1.1.0/24; host:h1 = { ip = 10.1.1.1; } }
+router:r1 = {
+managed;
+model = ASA;
+interface:n1 = { ip = 10.1.1.2; hardware = n1; }
+}
+group:g1 = network:n1, host:h1;
+service:s1 = {
+description = test
+user = group:g1;
+permit src = user; dst = any:[ip = 10.0.0.0/8 & network:n1, interface:r1.n1]; prt = tcp 80, tcp 90;
+}
+=OUTPUT=
+-- config
+format_indent = 4;
+-- topo
+network:n1 = {
+    ip = 10.1.1.0/24;
+    host:h1 = { ip = 10.1.1.1; }
+}
+
+router:r1 = {
+    managed;
+    model = ASA;
+    interface:n1 = { ip = 10.1.1.2; hardware = n1; }
+}
+
+group:g1 =
+    network:n1,
+    host:h1,
+;
+
+service:s1 = {
+    description = test
+
+    user = group:g1;
+    permit src = user;
+           dst = any:[ip = 10.0.0.0/8 &
+                     network:n1,
+                     interface:r1.n1,
+                 ];
+           prt = tcp 80,
+                 tcp 90,
+                 ;
+}
+=END=
+
+############################################################
+=TITLE=Maximum line length from config file
+=INPUT=
+-- config
+format_max_line_length = 40;
+-- topo
+group:g1 = network:n1, network:n2;
+group:g2 = network:n1, network:n2, network:n3, network:n4;
+group:g3 = network:n1, # comment
+ network:n2;
+service:s1 = {
+ owner = o1, o2;
+ user = network:n1, network:n2;
+ permit src = user; dst = host:h1, host:h2; prt = tcp 80, tcp 90;
+ permit src = user;
+        dst = host:h_10_1_1_1, host:h_10_1_1_2, host:h_10_1_1_3;
+        prt = tcp 80, tcp 81, tcp 82, tcp 83, tcp 84, tcp 85;
+}
+=OUTPUT=
+-- config
+format_max_line_length = 40;
+-- topo
+group:g1 = network:n1, network:n2;
+
+group:g2 =
+ network:n1,
+ network:n2,
+ network:n3,
+ network:n4,
+;
+
+group:g3 =
+ network:n1, # comment
+ network:n2,
+;
+
+service:s1 = {
+
+ owner = o1, o2;
+
+ user = network:n1, network:n2;
+ permit src = user;
+        dst = host:h1, host:h2;
+        prt = tcp 80, tcp 90;
+ permit src = user;
+        dst = host:h_10_1_1_1,
+              host:h_10_1_1_2,
+              host:h_10_1_1_3,
+              ;
+        prt = tcp 80,
+              tcp 81,
+              tcp 82,
+              tcp 83,
+              tcp 84,
+              tcp 85,
+              ;
+}
+=END=
+
+############################################################
+=TITLE=Don't sort, as requested by config file
+=INPUT=
+-- config
+format_sort_elements = 0;
+format_sort_hosts = 0;
+format_sort_service_attributes = 0;
+-- topo
+network:n1 = {
+ ip = 10.1.1.0/24;
+ host:h2 = { ip = 10.1.1.2; }
+ host:h1 = { ip = 10.1.1.1; }
+}
+group:g1 = network:n2, host:h1, group:g2;
+service:s1 = {
+ overlaps = service:s2;
+ disabled;
+ user = network:n2, any:[network:n3, network:n1];
+ permit src = user; dst = host:h2, host:h1; prt = udp 53, tcp 80;
+}
+=OUTPUT=
+-- config
+format_sort_elements = 0;
+format_sort_hosts = 0;
+format_sort_service_attributes = 0;
+-- topo
+network:n1 = {
+ ip = 10.1.1.0/24;
+ host:h2 = { ip = 10.1.1.2; }
+ host:h1 = { ip = 10.1.1.1; }
+}
+
+group:g1 =
+ network:n2,
+ host:h1,
+ group:g2,
+;
+
+service:s1 = {
+
+ overlaps = service:s2;
+ disabled;
+
+ user = network:n2,
+        any:[
+         network:n3,
+         network:n1,
+        ],
+        ;
+ permit src = user;
+        dst = host:h2,
+              host:h1,
+              ;
+        prt = tcp 80,
+              udp 53,
+              ;
+}
+=END=
@@ -0,0 +1,539 @@
+############################################################
+=TITLE=Option '-h'
+=INPUT=NONE
+=PARAMS=-h
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR ASA-CONFIG
+  -f, --file string   Add new definitions to this file, if DIR is given (default "import-asa")
+  -q, --quiet         Don't show changed files
+=END=
+
+############################################################
+=TITLE=Missing ASA configuration
+=INPUT=NONE
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR ASA-CONFIG
+  -f, --file string   Add new definitions to this file, if DIR is given (default "import-asa")
+  -q, --quiet         Don't show changed files
+=END=
+
+############################################################
+=TITLE=Interfaces and routes
+=SETUP=
+cat > asa.cfg <<'END'
+hostname asa1
+interface GigabitEthernet0/0
+ nameif outside
+ security-level 0
+ ip address 192.0.2.1 255.255.255.0
+ ipv6 address 2001:db8:1::1/64
+!
+interface GigabitEthernet0/1
+ nameif inside
+ security-level 100
+ ip address 10.1.1.1 255.255.255.0
+!
+interface GigabitEthernet0/2
+ shutdown
+ no nameif
+ no ip address
+!
+route outside 0.0.0.0 0.0.0.0 192.0.2.254 1
+route inside 10.2.0.0 255.255.0.0 10.1.1.254 1
+route inside 10.2.3.0 255.255.255.0 10.1.1.254 1
+route inside 10.3.0.0 255.255.0.0 10.1.1.253 1
+route inside 10.3.1.0 255.255.255.0 10.1.1.252 1
+route inside 10.4.0.0 255.255.0.0 10.9.9.9 1
+ipv6 route outside ::/0 2001:db8:1::ff
+END
+=INPUT=
+# Empty
+=PARAMS=asa.cfg
+=WARNING=
+Warning: Ignoring route to 10.4.0.0/16, next hop 10.9.9.9 isn't located at inside
+=OUTPUT=
+network:inside = {
+ ip = 10.1.1.0/24;
+ subnet_of = network:internet;
+}
+network:internet    = { ip = 0.0.0.0/0; }
+network:internet_v6 = { ip6 = ::/0; }
+network:n_10_2_0_0_16 = {
+ ip = 10.2.0.0/16;
+ subnet_of = network:internet;
+}
+network:n_10_3_0_0_16 = {
+ ip = 10.3.0.0/16;
+ subnet_of = network:internet;
+}
+network:n_10_3_1_0_24 = {
+ ip = 10.3.1.0/24;
+ subnet_of = network:n_10_3_0_0_16;
+}
+network:outside = {
+ ip = 192.0.2.0/24;
+ subnet_of = network:internet;
+}
+network:outside_v6 = {
+ ip6 = 2001:db8:1::/64;
+ subnet_of = network:internet_v6;
+}
+router:asa1 = {
+ managed;
+ model = ASA;
+ interface:outside    = { ip = 192.0.2.1; hardware = outside; }
+ interface:outside_v6 = { ip6 = 2001:db8:1::1; hardware = outside; }
+ interface:inside     = { ip = 10.1.1.1; hardware = inside; }
+}
+router:gw_10_1_1_252 = {
+ interface:inside = { ip = 10.1.1.252; }
+ interface:n_10_3_1_0_24;
+}
+router:gw_10_1_1_253 = {
+ interface:inside = { ip = 10.1.1.253; }
+ interface:n_10_3_0_0_16;
+}
+router:gw_10_1_1_254 = {
+ interface:inside = { ip = 10.1.1.254; }
+ interface:n_10_2_0_0_16;
+}
+router:gw_192_0_2_254 = {
+ interface:outside = { ip = 192.0.2.254; }
+ interface:internet;
+}
+router:gw_2001_db8_1__ff = {
+ interface:outside_v6 = { ip6 = 2001:db8:1::ff; }
+ interface:internet_v6;
+}
+# Empty
+=END=
+
+############################################################
+=TITLE=Objects, groups and services
+=SETUP=
+cat > asa.cfg <<'END'
+hostname asa1
+name 10.1.1.10 srv1
+interface GigabitEthernet0/0
+ nameif outside
+ ip address 192.0.2.1 255.255.255.0
+!
+interface GigabitEthernet0/1
+ nameif inside
+ ip address 10.1.1.1 255.255.255.0
+!
+object network web
+ host 10.1.1.10
+ description Web server
+object network part
+ subnet 10.1.1.128 255.255.255.192
+object network dmz-range
+ range 10.1.1.20 10.1.1.29
+object network remote
+ subnet 10.9.0.0 255.255.0.0
+object-group network admins
+ network-object host 10.1.1.5
+ network-object object part
+ group-object partner
+object-group network partner
+ description Partner networks
+ network-object 198.51.100.0 255.255.255.0
+ network-object host 203.0.113.7
+object-group service web-ports tcp
+ port-object eq www
+ port-object eq https
+object-group service mixed
+ service-object tcp destination eq ssh
+ service-object udp destination range 5000 5010
+ service-object icmp echo
+ service-object esp
+object-group icmp-type ping
+ icmp-object echo
+ icmp-object echo-reply
+access-list outside_in remark Access to web server
+access-list outside_in extended permit tcp any object web object-group web-ports
+access-list outside_in extended permit object-group mixed object-group partner host srv1
+access-list outside_in extended deny ip host 198.51.100.7 any log
+access-list inside_in extended permit udp object-group admins eq ntp any eq ntp inactive
+access-list inside_in extended permit icmp any any object-group ping
+access-list inside_in extended permit ip object dmz-range any4
+access-list inside_in extended permit tcp 10.1.1.0 255.255.255.0 object-group partner neq 23
+access-list inside_in extended permit ip any interface outside
+access-group outside_in in interface outside
+access-group inside_in in interface inside
+route outside 0.0.0.0 0.0.0.0 192.0.2.254 1
+route inside 10.9.0.0 255.255.0.0 10.1.1.254
+END
+=INPUT=
+# Empty
+=PARAMS=asa.cfg
+=WARNING=
+Warning: Rule with 'deny' from line 42 has precedence over all rules with 'permit' in Netspoc
+=OUTPUT=
+network:inside = {
+ ip = 10.1.1.0/24;
+ subnet_of = network:internet;
+ host:h_10_1_1_5 = { ip = 10.1.1.5; }
+ host:web        = { ip = 10.1.1.10; }
+ host:dmz-range  = { range = 10.1.1.20-10.1.1.29; }
+ host:part       = { range = 10.1.1.128-10.1.1.191; }
+}
+network:internet = {
+ ip = 0.0.0.0/0;
+ host:r_198_51_100_0_198_51_100_255 = { range = 198.51.100.0-198.51.100.255; }
+ host:h_198_51_100_7                = { ip = 198.51.100.7; }
+ host:h_203_0_113_7                 = { ip = 203.0.113.7; }
+}
+network:n_10_9_0_0_16 = {
+ ip = 10.9.0.0/16;
+ subnet_of = network:internet;
+}
+network:outside = {
+ ip = 192.0.2.0/24;
+ subnet_of = network:internet;
+}
+router:asa1 = {
+ managed;
+ model = ASA;
+ interface:outside = { ip = 192.0.2.1; hardware = outside; }
+ interface:inside  = { ip = 10.1.1.1; hardware = inside; }
+}
+router:gw_10_1_1_254 = {
+ interface:inside = { ip = 10.1.1.254; }
+ interface:n_10_9_0_0_16;
+}
+router:gw_192_0_2_254 = {
+ interface:outside = { ip = 192.0.2.254; }
+ interface:internet;
+}
+group:admins =
+ group:partner,
+ host:part,
+ host:h_10_1_1_5,
+;
+group:partner =
+ description = Partner networks
+ host:h_203_0_113_7,
+ host:r_198_51_100_0_198_51_100_255,
+;
+protocol:udp_123_123 = udp 123:123;
+protocolgroup:mixed =
+ icmp 8,
+ proto 50,
+ tcp 22,
+ udp 5000-5010,
+;
+protocolgroup:ping =
+ icmp 0,
+ icmp 8,
+;
+protocolgroup:web-ports =
+ tcp 80,
+ tcp 443,
+;
+service:inside_in-1 = {
+ disabled;
+ user = group:admins;
+ permit src = user;
+        dst = any:[
+               interface:asa1.[all],
+              ]
+              &! any:[
+                  interface:asa1.inside,
+                 ]
+              ;
+        prt = protocol:udp_123_123;
+}
+service:inside_in-2 = {
+ user = any:[
+         interface:asa1.inside,
+        ];
+ permit src = user;
+        dst = any:[
+               interface:asa1.[all],
+              ]
+              &! any:[
+                  interface:asa1.inside,
+                 ]
+              ;
+        prt = protocolgroup:ping;
+}
+service:inside_in-3 = {
+ user = host:dmz-range;
+ permit src = user;
+        dst = any:[
+               interface:asa1.[all],
+              ]
+              &! any:[
+                  interface:asa1.inside,
+                 ]
+              ;
+        prt = ip;
+}
+service:inside_in-4 = {
+ user = network:inside;
+ permit src = user;
+        dst = group:partner;
+        prt = tcp 1-22,
+              tcp 24-65535,
+              ;
+}
+service:inside_in-5 = {
+ user = any:[
+         interface:asa1.inside,
+        ];
+ permit src = user;
+        dst = interface:asa1.outside;
+        prt = ip;
+}
+service:outside_in-1 = {
+ description = Access to web server
+ user = any:[
+         interface:asa1.outside,
+        ];
+ permit src = user;
+        dst = host:web;
+        prt = protocolgroup:web-ports;
+}
+service:outside_in-2 = {
+ user = group:partner;
+ permit src = user;
+        dst = host:web;
+        prt = protocolgroup:mixed;
+}
+service:outside_in-3 = {
+ user = host:h_198_51_100_7;
+ deny   src = user;
+        dst = any:[
+               interface:asa1.[all],
+              ]
+              &! any:[
+                  interface:asa1.outside,
+                 ]
+              ;
+        prt = ip;
+}
+# Empty
+=END=
+
+############################################################
+=TITLE=NAT
+=SETUP=
+cat > asa.cfg <<'END'
+hostname asa1
+interface GigabitEthernet0/0
+ nameif outside
+ ip address 192.0.2.1 255.255.255.0
+!
+interface GigabitEthernet0/1
+ nameif inside
+ ip address 10.1.1.1 255.255.255.0
+!
+interface GigabitEthernet0/2
+ nameif dmz
+ ip address 10.1.2.1 255.255.255.0
+!
+object network inside-net
+ subnet 10.1.1.0 255.255.255.0
+object network dmz-net
+ subnet 10.1.2.0 255.255.255.0
+object network dmz-public
+ subnet 198.51.100.0 255.255.255.0
+object network srv
+ host 10.1.2.10
+ nat (dmz,outside) static 198.51.100.10
+object network srv-range
+ range 10.1.2.20 10.1.2.30
+object network inside-net
+ nat (inside,outside) dynamic interface
+nat (dmz,outside) source static dmz-net dmz-public
+nat (dmz,inside) source dynamic any interface
+nat (inside,dmz) source static inside-net inside-net destination static dmz-net dmz-net
+nat (dmz,outside) source dynamic srv-range 203.0.113.5
+route outside 0.0.0.0 0.0.0.0 192.0.2.254 1
+END
+=INPUT=
+# Empty
+=PARAMS=asa.cfg
+=WARNING=
+Warning: Ignoring NAT with 'destination static dmz-net dmz-net' in line 29
+Warning: Ignoring NAT in line 22, real address is no known network
+Warning: Ignoring NAT in line 28 with real address 'any'
+Warning: Ignoring NAT in line 30, real address is no known network
+=OUTPUT=
+network:dmz = {
+ ip = 10.1.2.0/24;
+ subnet_of = network:internet;
+ nat:outside = { ip = 198.51.100.0/24; subnet_of = network:internet; }
+}
+network:inside = {
+ ip = 10.1.1.0/24;
+ subnet_of = network:internet;
+ nat:outside = { ip = 192.0.2.1/32; dynamic; subnet_of = network:outside; }
+}
+network:internet = { ip = 0.0.0.0/0; }
+network:outside = {
+ ip = 192.0.2.0/24;
+ subnet_of = network:internet;
+}
+router:asa1 = {
+ managed;
+ model = ASA;
+ interface:outside = {
+  ip = 192.0.2.1;
+  hardware = outside;
+  nat_out = outside;
+ }
+ interface:inside = { ip = 10.1.1.1; hardware = inside; }
+ interface:dmz    = { ip = 10.1.2.1; hardware = dmz; }
+}
+router:gw_192_0_2_254 = {
+ interface:outside = { ip = 192.0.2.254; }
+ interface:internet;
+}
+# Empty
+=END=
+
+############################################################
+=TITLE=Reuse existing objects
+=SETUP=
+cat > asa.cfg <<'END'
+hostname fw
+interface GigabitEthernet0/1
+ nameif inside
+ ip address 10.1.1.1 255.255.255.0
+!
+interface GigabitEthernet0/2
+ nameif dmz
+ ip address 10.1.2.1 255.255.255.0
+!
+object network www
+ host 10.1.1.10
+object network pool
+ range 10.1.1.20 10.1.1.29
+object network branch
+ subnet 10.5.0.0 255.255.0.0
+object network db
+ host 10.1.1.5
+access-list dmz_access_in extended permit tcp object branch object www eq 443
+access-list dmz_access_in extended permit tcp object branch object pool eq 8080
+access-list dmz_access_in extended permit tcp host 10.1.2.2 object db eq 1521
+access-group dmz_access_in in interface dmz
+route dmz 10.5.0.0 255.255.0.0 10.1.2.2
+route dmz 10.6.0.0 255.255.0.0 10.1.2.2
+END
+=INPUT=
+-- topo
+network:lan = {
+ ip = 10.1.1.0/24;
+ host:web = { ip = 10.1.1.10; }
+ host:pool = { range = 10.1.1.20 - 10.1.1.29; }
+}
+network:dmz = { ip = 10.1.2.0/24; }
+router:fw = {
+ managed;
+ model = ASA;
+ interface:lan = { ip = 10.1.1.1; hardware = inside; }
+ interface:dmz = { ip = 10.1.2.1; hardware = dmz; }
+}
+network:branch = { ip = 10.5.0.0/16; }
+router:wan = {
+ interface:dmz = { ip = 10.1.2.2; }
+ interface:branch;
+}
+=OPTIONS=--file=asa
+=PARAMS=asa.cfg
+=OUTPUT=
+-- asa
+network:n_10_6_0_0_16 = { ip = 10.6.0.0/16; }
+service:dmz_access_in-1 = {
+ user = network:branch;
+ permit src = user;
+        dst = host:web;
+        prt = tcp 443;
+}
+service:dmz_access_in-2 = {
+ user = network:branch;
+ permit src = user;
+        dst = host:pool;
+        prt = tcp 8080;
+}
+service:dmz_access_in-3 = {
+ user = interface:wan.dmz;
+ permit src = user;
+        dst = host:db;
+        prt = tcp 1521;
+}
+-- topo
+network:lan = {
+ ip = 10.1.1.0/24;
+ host:db   = { ip = 10.1.1.5; }
+ host:web  = { ip = 10.1.1.10; }
+ host:pool = { range = 10.1.1.20-10.1.1.29; }
+}
+network:dmz = { ip = 10.1.2.0/24; }
+router:fw = {
+ managed;
+ model = ASA;
+ interface:lan = { ip = 10.1.1.1; hardware = inside; }
+ interface:dmz = { ip = 10.1.2.1; hardware = dmz; }
+}
+network:branch = { ip = 10.5.0.0/16; }
+router:wan = {
+ interface:dmz = { ip = 10.1.2.2; }
+ interface:branch;
+ interface:n_10_6_0_0_16;
+}
+=END=
+
+############################################################
+=TITLE=Unsupported commands
+=SETUP=
+cat > asa.cfg <<'END'
+hostname asa1
+interface Management0/0
+ nameif mgmt
+ ip address 10.1.1.1 255.255.255.0
+!
+object network h1
+ host 10.1.1.10
+ nat (mgmt,outside) static 10.9.9.10 service tcp 22 2222
+object-group user admins
+ user LOCAL\admin
+access-list std standard permit 10.1.1.0 255.255.255.0
+access-list mgmt_in extended permit tcp any object h1 eq ssh time-range work
+access-list mgmt_in extended permit tcp any host 10.2.2.2 eq ssh
+access-group mgmt_in in interface mgmt
+access-group other_in in interface mgmt
+END
+=INPUT=
+# Empty
+=PARAMS=asa.cfg
+=WARNING=
+Warning: Ignoring NAT with 'service tcp 22 2222' in line 8
+Warning: Ignoring object-group admins of type user in line 9
+Warning: Ignoring access-list std of type standard in line 11
+Warning: Ignoring time-range work in line 12
+Warning: No network found for 10.2.2.2
+Warning: Ignoring incomplete line 13 of access-list mgmt_in
+Warning: Ignoring unknown access-list other_in
+=OUTPUT=
+network:mgmt = {
+ ip = 10.1.1.0/24;
+ host:h1 = { ip = 10.1.1.10; }
+}
+router:asa1 = {
+ managed;
+ model = ASA;
+ interface:mgmt = { ip = 10.1.1.1; hardware = mgmt; }
+}
+service:mgmt_in-1 = {
+ user = any:[
+         interface:asa1.mgmt,
+        ];
+ permit src = user;
+        dst = host:h1;
+        prt = tcp 22;
+}
+# Empty
+=END=
@@ -0,0 +1,375 @@
+############################################################
+=TITLE=Option '-h'
+=INPUT=NONE
+=PARAMS=-h
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR ROUTER IPTABLES-SAVE ...
+  -f, --file string   Add new definitions to this file, if DIR is given (default "import-iptables")
+  -q, --quiet         Don't show changed files
+=END=
+
+############################################################
+=TITLE=Missing ruleset
+=INPUT=NONE
+=PARAMS=r1
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR ROUTER IPTABLES-SAVE ...
+  -f, --file string   Add new definitions to this file, if DIR is given (default "import-iptables")
+  -q, --quiet         Don't show changed files
+=END=
+
+############################################################
+=TITLE=Unknown router
+=SETUP=
+touch rules
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+=PARAMS=r1 rules
+=ERROR=
+Error: Can't find router:r1
+=END=
+
+############################################################
+=TITLE=Flatten user defined chains
+=SETUP=
+cat > rules <<'END'
+# Generated by iptables-save v1.8.7
+*nat
+:PREROUTING ACCEPT [0:0]
+-A PREROUTING -d 10.1.2.5 -j DNAT --to-destination 10.1.3.5
+COMMIT
+*filter
+:INPUT DROP [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT DROP [0:0]
+:c_web - [0:0]
+:c_ssh - [0:0]
+-A INPUT -m state --state RELATED,ESTABLISHED -j ACCEPT
+-A INPUT -s 10.1.1.0/24 -p tcp -m tcp --dport 22 -j ACCEPT
+-A FORWARD -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A FORWARD -s 10.1.1.10/32 -d 10.1.2.0/24 -p tcp -m multiport --dports 80,443,8000:8080 -j ACCEPT
+-A FORWARD -s 10.1.1.0/24 -j c_web
+-A FORWARD -s 10.1.3.0/24 -d 10.1.1.10 -p udp -m udp --sport 123 --dport 123 -j ACCEPT
+-A FORWARD -m iprange --src-range 10.1.3.10-10.1.3.20 -d 10.1.1.10 -j REJECT --reject-with icmp-port-unreachable
+-A FORWARD -j LOG --log-prefix "dropped: "
+-A c_web -d 10.1.2.7/32 -j DROP
+-A c_web -d 10.1.2.0/25 -p tcp -m comment --comment "Web servers" -m tcp --dport 80 -j ACCEPT
+-A c_web -d 10.1.3.0/24 -j c_ssh
+-A c_web -j RETURN
+-A c_web -d 10.1.2.0/24 -j ACCEPT
+-A c_ssh -p tcp --dport 22 -j ACCEPT
+-A c_ssh -p esp -j ACCEPT
+-A OUTPUT -d 10.1.3.53 -p udp --dport 53 -j ACCEPT
+COMMIT
+END
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; host:h10 = { ip = 10.1.1.10; } }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip = 10.1.1.1; hardware = eth0; }
+ interface:n2 = { ip = 10.1.2.1; hardware = eth1; }
+ interface:n3 = { ip = 10.1.3.1; hardware = eth2; }
+}
+=PARAMS=r1 rules
+=WARNING=
+Warning: Ignoring table nat in line 2
+Warning: Rule with 'DROP' from line 20 has precedence over all rules with 'permit' in Netspoc
+Warning: Rule with 'REJECT' from line 18 has precedence over all rules with 'permit' in Netspoc
+=OUTPUT=
+network:n1 = {
+ ip = 10.1.1.0/24;
+ host:h10 = { ip = 10.1.1.10; }
+}
+network:n2 = {
+ ip = 10.1.2.0/24;
+ host:r_10_1_2_0_10_1_2_127 = { range = 10.1.2.0-10.1.2.127; }
+ host:h_10_1_2_7            = { ip = 10.1.2.7; }
+}
+network:n3 = {
+ ip = 10.1.3.0/24;
+ host:r_10_1_3_10_10_1_3_20 = { range = 10.1.3.10-10.1.3.20; }
+ host:h_10_1_3_53           = { ip = 10.1.3.53; }
+}
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip = 10.1.1.1; hardware = eth0; }
+ interface:n2 = { ip = 10.1.2.1; hardware = eth1; }
+ interface:n3 = { ip = 10.1.3.1; hardware = eth2; }
+}
+protocol:udp_123_123 = udp 123:123;
+service:r1-FORWARD-2 = {
+ user = host:h10;
+ permit src = user;
+        dst = network:n2;
+        prt = tcp 80,
+              tcp 443,
+              tcp 8000-8080,
+              ;
+}
+service:r1-FORWARD-4 = {
+ user = network:n3;
+ permit src = user;
+        dst = host:h10;
+        prt = protocol:udp_123_123;
+}
+service:r1-FORWARD-5 = {
+ user = host:r_10_1_3_10_10_1_3_20;
+ deny   src = user;
+        dst = host:h10;
+        prt = ip;
+}
+service:r1-FORWARD-c_ssh-1 = {
+ user = network:n1;
+ permit src = user;
+        dst = network:n3;
+        prt = tcp 22;
+}
+service:r1-FORWARD-c_ssh-2 = {
+ user = network:n1;
+ permit src = user;
+        dst = network:n3;
+        prt = proto 50;
+}
+service:r1-FORWARD-c_web-1 = {
+ user = network:n1;
+ deny   src = user;
+        dst = host:h_10_1_2_7;
+        prt = ip;
+}
+service:r1-FORWARD-c_web-2 = {
+ description = Web servers
+ user = network:n1;
+ permit src = user;
+        dst = host:r_10_1_2_0_10_1_2_127;
+        prt = tcp 80;
+}
+service:r1-INPUT-2 = {
+ user = network:n1;
+ permit src = user;
+        dst = interface:r1.[all];
+        prt = tcp 22;
+}
+service:r1-OUTPUT-1 = {
+ user = interface:r1.[all];
+ permit src = user;
+        dst = host:h_10_1_3_53;
+        prt = udp 53;
+}
+=END=
+
+############################################################
+=TITLE=Unsupported rules
+=SETUP=
+cat > rules <<'END'
+*filter
+:INPUT ACCEPT [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT ACCEPT [0:0]
+:c1 - [0:0]
+:c2 - [0:0]
+-A INPUT -i lo -j ACCEPT
+-A FORWARD -m mark --mark 0x1 -j ACCEPT
+-A FORWARD ! -s 10.1.2.0/24 -p icmp -m icmp --icmp-type 8 -j ACCEPT
+-A FORWARD -o eth1 -m limit --limit 5/min -j ACCEPT
+-A FORWARD -j NFQUEUE --queue-num 1
+-A FORWARD -d 10.1.2.0/24 -j c1
+-A FORWARD -d 10.1.1.0/24 -p tcp -j ACCEPT
+-A c1 -j c2
+-A c1 -p tcp --dport 8080 -j RETURN
+-A c1 -p tcp -j ACCEPT
+-A c2 -j c1
+-A c2 -p udp --dport 53 -j ACCEPT
+COMMIT
+END
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip = 10.1.1.1; hardware = eth0; }
+ interface:n2 = { ip = 10.1.2.1; hardware = eth1; }
+}
+=PARAMS=r1 rules
+=WARNING=
+Warning: Ignoring policy ACCEPT of chain INPUT
+Warning: Can't convert rule in line 7: -i lo
+Warning: Can't convert rule in line 8: --mark 0x1
+Warning: Can't convert rule in line 9: ! -s 10.1.2.0/24
+Warning: Can't convert rule in line 10: -o eth1, --limit 5/min
+Warning: Can't convert rule in line 11: target NFQUEUE
+Warning: Can't convert rule in line 17: loop at chain c1
+Warning: Can't convert rule in line 15: conditional RETURN
+Warning: Can't convert rule in line 16: follows unconverted rule in line 15
+Warning: Can't convert rule in line 13: follows unconverted rule in line 15
+Warning: Ignoring policy ACCEPT of chain OUTPUT
+=OUTPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip = 10.1.1.1; hardware = eth0; }
+ interface:n2 = { ip = 10.1.2.1; hardware = eth1; }
+}
+service:r1-FORWARD-c2-2 = {
+ user = any:[
+         interface:r1.[all],
+        ];
+ permit src = user;
+        dst = network:n2;
+        prt = udp 53;
+}
+=END=
+
+############################################################
+=TITLE=Stop at unsupported or unconditional DROP
+=SETUP=
+cat > rules <<'END'
+*filter
+:INPUT DROP [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT DROP [0:0]
+-A INPUT -p tcp --dport 22 -j ACCEPT
+-A INPUT -m recent --rcheck -j DROP
+-A INPUT -p tcp --dport 80 -j ACCEPT
+-A FORWARD -j DROP
+-A FORWARD -p tcp --dport 80 -j ACCEPT
+COMMIT
+END
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip = 10.1.1.1; hardware = eth0; }
+}
+=PARAMS=r1 rules
+=WARNING=
+Warning: Can't convert rule in line 6: --rcheck
+Warning: Can't convert rule in line 7: follows unconverted rule in line 6
+=OUTPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip = 10.1.1.1; hardware = eth0; }
+}
+service:r1-INPUT-1 = {
+ user = any:[
+         interface:r1.[all],
+        ];
+ permit src = user;
+        dst = interface:r1.[all];
+        prt = tcp 22;
+}
+=END=
+
+############################################################
+=TITLE=IPv6 at dual stack router
+=SETUP=
+cat > rules <<'END'
+*filter
+:INPUT DROP [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT DROP [0:0]
+-A INPUT -s 10.1.1.0/24 -p tcp --dport 22 -j ACCEPT
+COMMIT
+END
+cat > rules6 <<'END'
+# Generated by ip6tables-save v1.8.7
+*filter
+:INPUT DROP [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT DROP [0:0]
+-A INPUT -p ipv6-icmp -m icmp6 --icmpv6-type echo-request -j ACCEPT
+-A INPUT -s 2001:db8:1::/64 -p tcp --dport 22 -j ACCEPT
+-A FORWARD -p tcp --dport 443 -j ACCEPT
+-A OUTPUT -d 2001:db8:1::53 -p udp --dport 53 -j ACCEPT
+COMMIT
+END
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n1_v6 = { ip6 = 2001:db8:1::/64; }
+network:n2_v6 = { ip6 = 2001:db8:2::/64; }
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip = 10.1.1.1; hardware = eth0; }
+ interface:n1_v6 = { ip6 = 2001:db8:1::1; hardware = eth0; }
+ interface:n2_v6 = { ip6 = 2001:db8:2::1; hardware = eth1; }
+}
+=PARAMS=r1 rules rules6
+=OUTPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n1_v6 = {
+ ip6 = 2001:db8:1::/64;
+ host:h_2001_db8_1__53 = { ip6 = 2001:db8:1::53; }
+}
+network:n2_v6 = { ip6 = 2001:db8:2::/64; }
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1    = { ip = 10.1.1.1; hardware = eth0; }
+ interface:n1_v6 = { ip6 = 2001:db8:1::1; hardware = eth0; }
+ interface:n2_v6 = { ip6 = 2001:db8:2::1; hardware = eth1; }
+}
+service:r1-FORWARD-1 = {
+ user = any:[
+         interface:r1.n1_v6,
+        ],
+        any:[
+         interface:r1.n2_v6,
+        ],
+        ;
+ permit src = user;
+        dst = any:[
+               interface:r1.n1_v6,
+              ],
+              any:[
+               interface:r1.n2_v6,
+              ],
+              ;
+        prt = tcp 443;
+}
+service:r1-INPUT-1 = {
+ user = network:n1;
+ permit src = user;
+        dst = interface:r1.n1;
+        prt = tcp 22;
+}
+service:r1-INPUT-1_2 = {
+ user = any:[
+         interface:r1.n1_v6,
+        ],
+        any:[
+         interface:r1.n2_v6,
+        ],
+        ;
+ permit src = user;
+        dst = interface:r1.n1_v6,
+              interface:r1.n2_v6,
+              ;
+        prt = icmpv6 128;
+}
+service:r1-INPUT-2 = {
+ user = network:n1_v6;
+ permit src = user;
+        dst = interface:r1.n1_v6,
+              interface:r1.n2_v6,
+              ;
+        prt = tcp 22;
+}
+service:r1-OUTPUT-1 = {
+ user = interface:r1.n1_v6,
+        interface:r1.n2_v6,
+        ;
+ permit src = user;
+        dst = host:h_2001_db8_1__53;
+        prt = udp 53;
+}
+=END=
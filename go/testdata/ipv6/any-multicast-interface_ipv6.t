@@ -83,6 +83,23 @@ ipv6 access-list e0_in
  deny ipv6 any any
 =END=
 
+############################################################
+=TITLE=Interface with BGP
+=INPUT=
+network:U = { ip6 = ::a01:100/120; }
+router:R = {
+ managed;
+ model = IOS;
+ interface:U = { ip6 = ::a01:101; hardware = e0; routing = BGP; }
+}
+=OUTPUT=
+--ipv6/R
+ipv6 access-list e0_in
+ permit tcp ::a01:100/120 ::a01:100/120 eq 179
+ permit tcp ::a01:100/120 eq 179 ::a01:100/120
+ deny ipv6 any any
+=END=
+
 ############################################################
 =TITLE=Interface with HSRP
 =INPUT=
@@ -966,7 +966,7 @@ Warning: Ignoring 'merge_tunnelspecified' at router:r
 =END=
 
 ############################################################
-=TITLE=Crypto not supported
+=TITLE=Crypto supported for model Linux
 =INPUT=
 [[crypto_sts]]
 network:n = { ip6 = ::a01:100/120; }
@@ -975,8 +975,22 @@ router:r = {
  model = Linux;
  interface:n = { ip6 = ::a01:101; hardware = n; hub = crypto:sts; }
 }
+=WARNING=
+Warning: No spokes have been defined for crypto:sts
+=END=
+
+############################################################
+=TITLE=Crypto not supported
+=INPUT=
+[[crypto_sts]]
+network:n = { ip6 = ::a01:100/120; }
+router:r@v1 = {
+ managed;
+ model = PAN-OS;
+ interface:n = { ip6 = ::a01:101; hardware = n; hub = crypto:sts; }
+}
 =ERROR=
-Error: Crypto not supported for router:r of model Linux
+Error: Crypto not supported for router:r@v1 of model PAN-OS
 =END=
 
 ############################################################
@@ -383,3 +383,68 @@ Error: Ambiguous static routes for network:n1 at interface:r4.n3 via
 =END=
 
 ############################################################
+=TITLE=Generate configuration of HSRP and VRRP
+=INPUT=
+network:n1 = { ip6 = ::a01:100/120; }
+network:n2 = { ip6 = ::a01:200/120; }
+router:r1 = {
+ managed;
+ model = IOS;
+ interface:n1 = {
+  ip6 = ::a01:102;
+  virtual = { ip6 = ::a01:101; type = HSRP; id = 11; priority = 110; }
+  hardware = n1;
+ }
+ interface:n2 = {
+  ip6 = ::a01:202;
+  virtual = { ip6 = ::a01:201; type = VRRP; id = 12; priority = 110; }
+  hardware = n2;
+ }
+}
+router:r2 = {
+ managed;
+ model = IOS;
+ interface:n1 = {
+  ip6 = ::a01:103;
+  virtual = { ip6 = ::a01:101; type = HSRP; id = 11; }
+  hardware = n1;
+ }
+ interface:n2 = {
+  ip6 = ::a01:203;
+  virtual = { ip6 = ::a01:201; type = VRRP; id = 12; }
+  hardware = n2;
+ }
+}
+=OPTIONS=--generate_redundancy
+=OUTPUT=
+--ipv6/r1
+interface n1
+ ipv6 address ::a01:102/120
+ standby version 2
+ standby 11 ipv6 autoconfig
+ standby 11 ipv6 ::a01:101/120
+ standby 11 priority 110
+ ipv6 traffic-filter n1_in in
+interface n2
+ ipv6 address ::a01:202/120
+ vrrp 12 address-family ipv6
+  address fe80::200:5eff:fe00:20c primary
+  address ::a01:201/120
+  priority 110
+ ipv6 traffic-filter n2_in in
+--ipv6/r2
+interface n1
+ ipv6 address ::a01:103/120
+ standby version 2
+ standby 11 ipv6 autoconfig
+ standby 11 ipv6 ::a01:101/120
+ ipv6 traffic-filter n1_in in
+interface n2
+ ipv6 address ::a01:203/120
+ vrrp 12 address-family ipv6
+  address fe80::200:5eff:fe00:20c primary
+  address ::a01:201/120
+ ipv6 traffic-filter n2_in in
+=END=
+
+############################################################
@@ -0,0 +1,319 @@
+
+############################################################
+=TITLE=Option '-h'
+=INPUT=NONE
+=PARAMS=-h
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR [service:]NAME ...
+  -f, --file string   Read groups of identical services from warnings in file
+  -q, --quiet         Don't show changed files
+=END=
+
+############################################################
+=TITLE=Need at least two services
+=INPUT=#
+=PARAMS=service:s1
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR [service:]NAME ...
+  -f, --file string   Read groups of identical services from warnings in file
+  -q, --quiet         Don't show changed files
+=END=
+
+############################################################
+=TITLE=Read groups from unknown file
+=INPUT=#
+=PARAMS=-f unknown
+=ERROR=
+Error: Can't open unknown: no such file or directory
+=END=
+
+############################################################
+=TITLE=Unknown service
+=INPUT=
+service:s1 = {
+ user = host:h1;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+=PARAMS=service:s1 service:s2
+=ERROR=
+Error: Can't find service:s2
+=END=
+
+############################################################
+=TITLE=Merge two services
+=INPUT=
+service:s1 = {
+ user = host:h2;
+ permit src = user; dst = network:n1; prt = tcp 80, tcp 22;
+}
+service:s2 = {
+ user = host:h1, host:h2;
+ permit src = user; dst = network:n1; prt = tcp 22, tcp 80;
+}
+=PARAMS=s1 service:s2
+=OUTPUT=
+service:s1 = {
+ user = host:h1,
+        host:h2,
+        ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 80,
+              tcp 22,
+              ;
+}
+=END=
+
+############################################################
+=TITLE=Combine descriptions
+=INPUT=
+service:s1 = {
+ user = host:h1;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s2 = {
+ description = Web access;
+ user = host:h2;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s3 = {
+ description = Access to intranet
+ user = host:h3;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s4 = {
+ description = Web access
+ user = host:h4;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+=PARAMS=s1 s2 s3 s4
+=OUTPUT=
+service:s1 = {
+ description = Web access; Access to intranet
+ user = host:h1,
+        host:h2,
+        host:h3,
+        host:h4,
+        ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 80;
+}
+=END=
+
+############################################################
+=TITLE=Merge with identical attributes
+=INPUT=
+service:s1 = {
+ disable_at = 2099-12-31;
+ user = host:h1;
+ permit src = network:n1; dst = user; prt = udp 53;
+}
+service:s2 = {
+ disable_at = 2099-12-31;
+ user = host:h2;
+ permit src = network:n1; dst = user; prt = udp 53;
+}
+=PARAMS=s2 s1
+=OUTPUT=
+service:s2 = {
+ disable_at = 2099-12-31;
+ user = host:h1,
+        host:h2,
+        ;
+ permit src = network:n1;
+        dst = user;
+        prt = udp 53;
+}
+=END=
+
+############################################################
+=TITLE=Attribute differs
+=INPUT=
+service:s1 = {
+ disable_at = 2099-12-31;
+ user = host:h1;
+ permit src = network:n1; dst = user; prt = udp 53;
+}
+service:s2 = {
+ user = host:h2;
+ permit src = network:n1; dst = user; prt = udp 53;
+}
+=PARAMS=s1 s2
+=ERROR=
+Error: Can't merge service:s1 and service:s2: values of attribute 'disable_at' differ
+=END=
+
+############################################################
+=TITLE=Rules differ
+=INPUT=
+service:s1 = {
+ user = host:h1;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s2 = {
+ user = host:h2;
+ permit src = user; dst = network:n1; prt = tcp 81;
+}
+=PARAMS=s1 s2
+=ERROR=
+Error: Can't merge service:s1 and service:s2: rules differ
+=END=
+
+############################################################
+=TITLE=Only one uses foreach
+=INPUT=
+service:s1 = {
+ user = foreach interface:r1.n1;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s2 = {
+ user = interface:r1.n2;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+=PARAMS=s1 s2
+=ERROR=
+Error: Can't merge service:s1 and service:s2: only one uses 'foreach'
+=END=
+
+############################################################
+=TITLE=Combine and change references in overlaps
+=INPUT=
+service:s1 = {
+ overlaps = service:s2, service:s4;
+ user = host:h1;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s2 = {
+ overlaps = service:s3;
+ user = host:h2;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s3 = {
+ overlaps = service:s2;
+ user = host:h3;
+ permit src = user; dst = network:n1; prt = tcp;
+}
+service:s4 = {
+ user = host:h4;
+ permit src = user; dst = network:n1; prt = tcp;
+}
+=PARAMS=s1 s2
+=OUTPUT=
+service:s1 = {
+ overlaps = service:s4,
+            service:s3,
+            ;
+ user = host:h1,
+        host:h2,
+        ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 80;
+}
+service:s3 = {
+ overlaps = service:s1;
+ user = host:h3;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp;
+}
+service:s4 = {
+ user = host:h4;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp;
+}
+=END=
+
+############################################################
+=TITLE=Read groups from warnings
+=FILE_OPTION=
+Warning: These services have identical rule definitions.
+ A single service should be created instead, with merged users.
+ - service:s1
+ - service:s2
+Warning: These services have identical rule definitions.
+ A single service should be created instead, with merged users.
+ - service:s3
+ - service:s4
+ - service:s5
+=INPUT=
+-- a
+service:s1 = {
+ user = host:h1;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s3 = {
+ user = host:h3;
+ permit src = user; dst = network:n1; prt = tcp 22;
+}
+-- b
+service:s2 = {
+ user = host:h2;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s4 = {
+ identical_body = service:s5;
+ user = host:h4;
+ permit src = user; dst = network:n1; prt = tcp 22;
+}
+service:s5 = {
+ user = host:h5;
+ permit src = user; dst = network:n1; prt = tcp 22;
+}
+=OUTPUT=
+-- a
+service:s1 = {
+ user = host:h1,
+        host:h2,
+        ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 80;
+}
+service:s3 = {
+ user = host:h3,
+        host:h4,
+        host:h5,
+        ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 22;
+}
+-- b
+=END=
+
+############################################################
+=TITLE=Ignore other warnings when reading groups
+=FILE_OPTION=
+Warning: Duplicate elements in group:g:
+ - host:h1
+ - host:h2
+Warning: These services have identical rule definitions.
+ A single service should be created instead, with merged users.
+ - service:s1
+ - service:s2
+Warning: Useless 'overlaps = service:s3' in service:s4
+Warning: unused group:g2
+ - service:s3
+ - service:s4
+=INPUT=
+service:s1 = {
+ user = host:h1;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+service:s2 = {
+ user = host:h2;
+ permit src = user; dst = network:n1; prt = tcp 80;
+}
+=OUTPUT=
+service:s1 = {
+ user = host:h1,
+        host:h2,
+        ;
+ permit src = user;
+        dst = network:n1;
+        prt = tcp 80;
+}
+=END=
@@ -33,6 +33,16 @@ Error: Invalid line in config:
 Aborted
 =END=
 
+############################################################
+=TITLE=Keys of format-netspoc in config file are ignored
+=INPUT=
+-- config
+format_indent = 4;
+format_foo = bar;
+-- topo
+network:n1 = { ip = 10.1.1.0/24; }
+=WARNING=NONE
+
 ############################################################
 =TITLE=Invalid line in config file
 =INPUT=
@@ -104,7 +114,11 @@ Aborted
 =INPUT= #
 =ERROR=
 Usage: PROGRAM [options] IN-DIR|IN-FILE [CODE-DIR]
+      --aggregate_routes
+      --all_syntax_errors
       --auto_default_route                          (default true)
+      --autofix string
+      --check_crypto_policy tristate
       --check_duplicate_rules tristate              (default warn)
       --check_empty_files tristate                  (default warn)
       --check_fully_redundant_rules tristate
@@ -124,13 +138,49 @@ Usage: PROGRAM [options] IN-DIR|IN-FILE [CODE-DIR]
       --check_unused_protocols tristate
       --concurrency_pass1 int                       (default 1)
       --concurrency_pass2 int                       (default 1)
+      --crypto_policy_encryption strings            (default [])
+      --crypto_policy_hash strings                  (default [])
+      --crypto_policy_min_group int
+      --crypto_policy_min_ike_version int
+      --crypto_policy_min_ipsec_lifetime int
+      --crypto_policy_min_isakmp_lifetime int
       --debug_pass2 string
+      --generate_redundancy
   -m, --max_errors int                              (default 10)
+      --memory_limit int
+      --profile_dir string
   -q, --quiet
+      --service_map
   -t, --time_stamps
+      --warn_expiring_days int
 Aborted
 =END=
 
+############################################################
+=TITLE=Option --service_map
+=OPTIONS=--service_map
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s2 = {
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 81;
+}
+=OUTPUT=
+--r1.rules
+{"model":"ASA","acls":[{"name":"n1_in","rules":[{"src":["10.1.1.0/24"],"dst":["10.1.2.0/24"],"prt":["tcp 80"],"services":["service:s1"]},{"src":["10.1.1.0/24"],"dst":["10.1.2.0/24"],"prt":["tcp 81"],"services":["service:s2"]}],"intf_rules":[],"add_deny":true},{"name":"n2_in","rules":[],"intf_rules":[],"add_deny":true}],"do_objectgroup":true}
+=END=
+
 ############################################################
 =TITLE=Too many arguments
 =PARAMS=abc def
@@ -287,7 +337,7 @@ router:r1 = {
 }
 =WITH_OUTDIR=
 =ERROR=
-panic: open out/r1.config: permission denied
+panic: Can't hash out/r1.config: open out/r1.config: permission denied
 =END=
 
 ############################################################
@@ -305,7 +355,7 @@ router:r1 = {
 }
 =WITH_OUTDIR=
 =ERROR=
-panic: open out/r1.rules: permission denied
+panic: Can't hash out/r1.rules: open out/r1.rules: permission denied
 =END=
 
 ############################################################
@@ -353,7 +403,7 @@ mkdir out
 =INPUT= ignored
 =WITH_OUTDIR=
 =ERROR=
-panic: open out/r1.rules: no such file or directory
+panic: Can't hash out/r1.rules: open out/r1.rules: no such file or directory
 =END=
 
 ############################################################
@@ -407,3 +457,58 @@ END
 =ERROR=
 panic: Unexpected overlapping ranges [70 80] [80 99]
 =END=
+
+############################################################
+=TITLE=Can't create directory for profiles
+=OPTIONS=--profile_dir=/dev/null/prof
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+=ERROR=
+Error: Can't mkdir /dev/null: not a directory
+Aborted
+=END=
+
+############################################################
+=TITLE=Low-memory mode with option --memory_limit
+=OPTIONS=--memory_limit=1000
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+router:r2 = {
+ managed;
+ model = IOS;
+ interface:n2 = { ip = 10.1.2.2; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = network:n3; prt = tcp;
+}
+service:s2 = {
+ user = network:n1;
+ permit src = user; dst = network:n2, network:n3; prt = tcp 80;
+}
+=WARNING=
+Warning: Redundant rules in service:s2 compared to service:s1:
+  permit src=network:n1; dst=network:n3; prt=tcp 80; of service:s2
+< permit src=network:n1; dst=network:n3; prt=tcp; of service:s1
+=OUTPUT=
+--r1
+! n1_in
+access-list n1_in extended permit tcp 10.1.1.0 255.255.255.0 10.1.3.0 255.255.255.0
+access-list n1_in extended permit tcp 10.1.1.0 255.255.255.0 10.1.2.0 255.255.255.0 eq 80
+access-list n1_in extended deny ip any4 any4
+access-group n1_in in interface n1
+--r2
+ip access-list extended n2_in
+ deny ip any host 10.1.3.1
+ permit tcp 10.1.1.0 0.0.0.255 10.1.3.0 0.0.0.255
+ deny ip any any
+=END=
@@ -37,6 +37,27 @@ Error: Typed name expected at line 2 of INPUT, near "--HERE-->network:⋝3"
 Aborted
 =END=
 
+############################################################
+=TITLE=Show all syntax errors
+=OPTIONS=--all_syntax_errors
+=INPUT=
+--a
+network:n1 = { ip = 10.1.1.0/24 }
+network:n2 = { ip = 10.1.2.0/24; }
+group:g1 = network:n1, network:n2;
+router:r1 = {
+ interface:n1 = { ip = 10.1.1.1; };
+ interface:n2;
+}
+--b
+network:n3 = { ip = 10.1.3.0/24; host:h3 = { ip = 10.1.3.10; }
+network:n4 = { ip = 10.1.4.0/24; }
+=ERROR=
+Error: Expected ';' at line 1 of a, near "10.1.1.0/24 --HERE-->}"
+Error: Unexpected separator ';' at line 5 of a, near "10.1.1.1; }--HERE-->;"
+Error: Expected '}' at line 2 of b, near "--HERE-->network:n4"
+=END=
+
 ############################################################
 =TITLE=Invalid IP address
 =INPUT=
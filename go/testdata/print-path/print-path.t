@@ -4,7 +4,8 @@
 =PARAMS=-h
 =ERROR=
 Usage: PROGRAM [options] FILE|DIR SOURCE DESTINATION
-  -q, --quiet   Don't print progress messages
+  -d, --detailed   Show hops with interfaces, zones and routes of each path
+  -q, --quiet      Don't print progress messages
 =END=
 
 ############################################################
@@ -20,7 +21,8 @@ Error: unknown flag: --abc
 =INPUT=NONE
 =ERROR=
 Usage: PROGRAM [options] FILE|DIR SOURCE DESTINATION
-  -q, --quiet   Don't print progress messages
+  -d, --detailed   Show hops with interfaces, zones and routes of each path
+  -q, --quiet      Don't print progress messages
 =END=
 
 ############################################################
@@ -165,3 +167,419 @@ network:n2 = { ip6 = 2001:db8:9:2::/64; }
 =OUTPUT=
 ["network:n1","network:n2","router:r1"]
 =END=
+
+############################################################
+=TITLE=Detailed path inside zone
+=PARAMS=--detailed network:n3 network:n4
+=TEMPL=redundancy
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+router:r1 = {
+ managed;
+ model = IOS;
+ interface:n1 = { ip = 10.1.1.1; virtual = { ip = 10.1.1.9; type = VRRP; } hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; virtual = { ip = 10.1.2.9; type = VRRP; } hardware = n2; }
+}
+router:r2 = {
+ managed;
+ model = IOS;
+ interface:n1 = { ip = 10.1.1.2; virtual = { ip = 10.1.1.9; type = VRRP; } hardware = n1; }
+ interface:n2 = { ip = 10.1.2.2; virtual = { ip = 10.1.2.9; type = VRRP; } hardware = n2; }
+}
+router:r3 = {
+ managed;
+ model = ASA;
+ interface:n2 = { ip = 10.1.2.3; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+router:u = {
+ interface:n3 = { ip = 10.1.3.2; }
+ interface:n4;
+}
+=INPUT=[[redundancy]]
+=OUTPUT=
+{
+ "source": "network:n3",
+ "destination": "network:n4",
+ "paths": [
+  []
+ ]
+}
+=END=
+
+############################################################
+=TITLE=Detailed path with redundancy group
+=PARAMS=--detailed network:n1 network:n4
+=INPUT=[[redundancy]]
+=OUTPUT=
+{
+ "source": "network:n1",
+ "destination": "network:n4",
+ "paths": [
+  [
+   {
+    "router": "router:r1",
+    "in": "interface:r1.n1.virtual",
+    "out": "interface:r1.n2.virtual",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n2]",
+    "next_hop": "interface:r3.n2"
+   },
+   {
+    "router": "router:r3",
+    "in": "interface:r3.n2",
+    "out": "interface:r3.n3",
+    "in_zone": "any:[network:n2]",
+    "out_zone": "any:[network:n3]",
+    "next_hop": "interface:u.n3"
+   }
+  ],
+  [
+   {
+    "router": "router:r2",
+    "in": "interface:r2.n1.virtual",
+    "out": "interface:r2.n2.virtual",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n2]",
+    "next_hop": "interface:r3.n2"
+   },
+   {
+    "router": "router:r3",
+    "in": "interface:r3.n2",
+    "out": "interface:r3.n3",
+    "in_zone": "any:[network:n2]",
+    "out_zone": "any:[network:n3]",
+    "next_hop": "interface:u.n3"
+   }
+  ]
+ ]
+}
+=END=
+
+############################################################
+=TITLE=Detailed path with redundancy group, reverse
+=PARAMS=--detailed network:n4 network:n1
+=INPUT=[[redundancy]]
+=OUTPUT=
+{
+ "source": "network:n4",
+ "destination": "network:n1",
+ "paths": [
+  [
+   {
+    "router": "router:r3",
+    "in": "interface:r3.n3",
+    "out": "interface:r3.n2",
+    "in_zone": "any:[network:n3]",
+    "out_zone": "any:[network:n2]",
+    "next_hop": "interface:r1.n2.virtual"
+   },
+   {
+    "router": "router:r1",
+    "in": "interface:r1.n2.virtual",
+    "out": "interface:r1.n1.virtual",
+    "in_zone": "any:[network:n2]",
+    "out_zone": "any:[network:n1]"
+   }
+  ],
+  [
+   {
+    "router": "router:r3",
+    "in": "interface:r3.n3",
+    "out": "interface:r3.n2",
+    "in_zone": "any:[network:n3]",
+    "out_zone": "any:[network:n2]",
+    "next_hop": "interface:r2.n2.virtual"
+   },
+   {
+    "router": "router:r2",
+    "in": "interface:r2.n2.virtual",
+    "out": "interface:r2.n1.virtual",
+    "in_zone": "any:[network:n2]",
+    "out_zone": "any:[network:n1]"
+   }
+  ]
+ ]
+}
+=END=
+
+############################################################
+=TITLE=Detailed path with loop
+=PARAMS=--detailed network:n1 network:n4
+=TEMPL=loop
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+router:r1 = {
+ managed;
+ model = IOS;
+ routing = OSPF;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+router:r2 = {
+ managed;
+ model = ASA;
+ interface:n2 = { ip = 10.1.2.2; hardware = n2; }
+ interface:n4 = { ip = 10.1.4.1; hardware = n4; }
+}
+router:r3 = {
+ managed;
+ model = ASA;
+ interface:n3 = { ip = 10.1.3.2; hardware = n3; }
+ interface:n4 = { ip = 10.1.4.2; hardware = n4; }
+}
+=INPUT=[[loop]]
+=OUTPUT=
+{
+ "source": "network:n1",
+ "destination": "network:n4",
+ "paths": [
+  [
+   {
+    "router": "router:r1",
+    "in": "interface:r1.n1",
+    "out": "interface:r1.n2",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n2]",
+    "routing": "OSPF"
+   },
+   {
+    "router": "router:r2",
+    "in": "interface:r2.n2",
+    "out": "interface:r2.n4",
+    "in_zone": "any:[network:n2]",
+    "out_zone": "any:[network:n4]"
+   }
+  ],
+  [
+   {
+    "router": "router:r1",
+    "in": "interface:r1.n1",
+    "out": "interface:r1.n3",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n3]",
+    "routing": "OSPF"
+   },
+   {
+    "router": "router:r3",
+    "in": "interface:r3.n3",
+    "out": "interface:r3.n4",
+    "in_zone": "any:[network:n3]",
+    "out_zone": "any:[network:n4]"
+   }
+  ]
+ ]
+}
+=END=
+
+############################################################
+=TITLE=Detailed path with pathrestriction
+=PARAMS=--detailed network:n1 network:n4
+=INPUT=
+[[loop]]
+pathrestriction:p = interface:r1.n3, interface:r3.n4;
+=OUTPUT=
+{
+ "source": "network:n1",
+ "destination": "network:n4",
+ "paths": [
+  [
+   {
+    "router": "router:r1",
+    "in": "interface:r1.n1",
+    "out": "interface:r1.n2",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n2]",
+    "routing": "OSPF"
+   },
+   {
+    "router": "router:r2",
+    "in": "interface:r2.n2",
+    "out": "interface:r2.n4",
+    "in_zone": "any:[network:n2]",
+    "out_zone": "any:[network:n4]"
+   }
+  ]
+ ]
+}
+=END=
+
+############################################################
+=TITLE=Detailed path with pathrestriction in loop
+# Path from r1.n2 to r5.n7 isn't valid,
+# although each hop is part of some other valid path.
+=PARAMS=--detailed network:n1 network:n7
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+network:n5 = { ip = 10.1.5.0/24; }
+network:n6 = { ip = 10.1.6.0/24; }
+network:n7 = { ip = 10.1.7.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+router:r2 = {
+ managed;
+ model = ASA;
+ interface:n2 = { ip = 10.1.2.2; hardware = n2; }
+ interface:n4 = { ip = 10.1.4.2; hardware = n4; }
+}
+router:r3 = {
+ managed;
+ model = ASA;
+ interface:n3 = { ip = 10.1.3.2; hardware = n3; }
+ interface:n4 = { ip = 10.1.4.3; hardware = n4; }
+}
+router:r4 = {
+ managed;
+ model = ASA;
+ interface:n4 = { ip = 10.1.4.4; hardware = n4; }
+ interface:n5 = { ip = 10.1.5.4; hardware = n5; }
+ interface:n6 = { ip = 10.1.6.4; hardware = n6; }
+}
+router:r5 = {
+ managed;
+ model = ASA;
+ interface:n5 = { ip = 10.1.5.5; hardware = n5; }
+ interface:n7 = { ip = 10.1.7.5; hardware = n7; }
+}
+router:r6 = {
+ managed;
+ model = ASA;
+ interface:n6 = { ip = 10.1.6.6; hardware = n6; }
+ interface:n7 = { ip = 10.1.7.6; hardware = n7; }
+}
+router:r7 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.7; hardware = n1; }
+ interface:n7 = { ip = 10.1.7.7; hardware = n7; }
+}
+pathrestriction:p = interface:r1.n2, interface:r5.n7;
+=OUTPUT=
+{
+ "source": "network:n1",
+ "destination": "network:n7",
+ "paths": [
+  [
+   {
+    "router": "router:r7",
+    "in": "interface:r7.n1",
+    "out": "interface:r7.n7",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n7]"
+   }
+  ],
+  [
+   {
+    "router": "router:r1",
+    "in": "interface:r1.n1",
+    "out": "interface:r1.n2",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n2]",
+    "next_hop": "interface:r2.n2"
+   },
+   {
+    "router": "router:r2",
+    "in": "interface:r2.n2",
+    "out": "interface:r2.n4",
+    "in_zone": "any:[network:n2]",
+    "out_zone": "any:[network:n4]",
+    "next_hop": "interface:r4.n4"
+   },
+   {
+    "router": "router:r4",
+    "in": "interface:r4.n4",
+    "out": "interface:r4.n6",
+    "in_zone": "any:[network:n4]",
+    "out_zone": "any:[network:n6]",
+    "next_hop": "interface:r6.n6"
+   },
+   {
+    "router": "router:r6",
+    "in": "interface:r6.n6",
+    "out": "interface:r6.n7",
+    "in_zone": "any:[network:n6]",
+    "out_zone": "any:[network:n7]"
+   }
+  ],
+  [
+   {
+    "router": "router:r1",
+    "in": "interface:r1.n1",
+    "out": "interface:r1.n3",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n3]",
+    "next_hop": "interface:r3.n3"
+   },
+   {
+    "router": "router:r3",
+    "in": "interface:r3.n3",
+    "out": "interface:r3.n4",
+    "in_zone": "any:[network:n3]",
+    "out_zone": "any:[network:n4]",
+    "next_hop": "interface:r4.n4"
+   },
+   {
+    "router": "router:r4",
+    "in": "interface:r4.n4",
+    "out": "interface:r4.n5",
+    "in_zone": "any:[network:n4]",
+    "out_zone": "any:[network:n5]",
+    "next_hop": "interface:r5.n5"
+   },
+   {
+    "router": "router:r5",
+    "in": "interface:r5.n5",
+    "out": "interface:r5.n7",
+    "in_zone": "any:[network:n5]",
+    "out_zone": "any:[network:n7]"
+   }
+  ],
+  [
+   {
+    "router": "router:r1",
+    "in": "interface:r1.n1",
+    "out": "interface:r1.n3",
+    "in_zone": "any:[network:n1]",
+    "out_zone": "any:[network:n3]",
+    "next_hop": "interface:r3.n3"
+   },
+   {
+    "router": "router:r3",
+    "in": "interface:r3.n3",
+    "out": "interface:r3.n4",
+    "in_zone": "any:[network:n3]",
+    "out_zone": "any:[network:n4]",
+    "next_hop": "interface:r4.n4"
+   },
+   {
+    "router": "router:r4",
+    "in": "interface:r4.n4",
+    "out": "interface:r4.n6",
+    "in_zone": "any:[network:n4]",
+    "out_zone": "any:[network:n6]",
+    "next_hop": "interface:r6.n6"
+   },
+   {
+    "router": "router:r6",
+    "in": "interface:r6.n6",
+    "out": "interface:r6.n7",
+    "in_zone": "any:[network:n6]",
+    "out_zone": "any:[network:n7]"
+   }
+  ]
+ ]
+}
+=END=
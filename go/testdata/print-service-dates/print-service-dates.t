@@ -0,0 +1,92 @@
+
+=TEMPL=date
+{{DATE . -}}
+
+=TEMPL=input
+owner:o1 = { admins = a1@example.com; }
+owner:o2 = { admins = a2@example.com; }
+network:n1 = { ip = 10.1.1.0/24; owner = o1; }
+network:n2 = { ip = 10.1.2.0/24; owner = o2; }
+network:n3 = { ip = 10.1.3.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80;
+}
+service:s2 = {
+ disable_at = [[date 5]];
+ user = network:n2;
+ permit src = user; dst = network:n1; prt = tcp 81;
+}
+service:s3 = {
+ enable_at = [[date 3]];
+ user = network:n3;
+ permit src = user; dst = network:n1, network:n2; prt = tcp 82;
+}
+service:s4 = {
+ enable_at = [[date -3]];
+ disable_at = [[date 30]];
+ user = network:n1;
+ permit src = user; dst = network:n3; prt = tcp 83;
+}
+service:s5 = {
+ disable_at = [[date -1]];
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 84;
+}
+=END=
+
+############################################################
+=TITLE=Option '-h'
+=INPUT=NONE
+=PARAMS=-h
+=ERROR=
+Usage: PROGRAM [options] FILE|DIR
+  -d, --dated          Show only services with attribute enable_at or disable_at
+  -e, --expiring int   Show only services disabled within given number of days
+  -q, --quiet          Don't print progress messages
+=END=
+
+############################################################
+=TITLE=Unknown option
+=INPUT=#
+=OPTIONS=--abc
+=ERROR=
+Error: unknown flag: --abc
+=END=
+
+############################################################
+=TITLE=List all services
+=INPUT=[[input]]
+=OUTPUT=
+service:s1 - - enabled o2
+service:s2 - [[date 5]] enabled o1
+service:s3 [[date 3]] - pending o1,o2
+service:s4 [[date -3]] [[date 30]] enabled -
+service:s5 - [[date -1]] disabled o2
+=END=
+
+############################################################
+=TITLE=List dated services
+=INPUT=[[input]]
+=OPTIONS=--dated
+=OUTPUT=
+service:s2 - [[date 5]] enabled o1
+service:s3 [[date 3]] - pending o1,o2
+service:s4 [[date -3]] [[date 30]] enabled -
+service:s5 - [[date -1]] disabled o2
+=END=
+
+############################################################
+=TITLE=List expiring services
+=INPUT=[[input]]
+=OPTIONS=--expiring 10
+=OUTPUT=
+service:s2 - [[date 5]] enabled o1
+=END=
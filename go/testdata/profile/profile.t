@@ -0,0 +1,95 @@
+############################################################
+=TITLE=Write profiles and report of phases
+=TEMPL=input
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+router:r1 = {
+ managed;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = network:n2; prt = tcp 80, tcp 81;
+}
+=INPUT=[[input]]
+=OUTPUT=
+pass1.cpu.pprof
+pass1.heap.pprof
+pass2.cpu.pprof
+pass2.heap.pprof
+report.json
+pass1 readNetspoc 2 0 0 0
+pass1 orderProtocols 2 0 0 0
+pass1 checkIPAddresses 2 0 0 0
+pass1 setZone 2 0 0 0
+pass1 setPath 2 0 0 0
+pass1 distributeNatInfo 2 0 0 0
+pass1 normalizeServices 2 0 0 0
+pass1 convertHostsInRules 2 0 0 0
+pass1 groupPathRules 2 1 0 0
+pass1 findSubnetsInNatDomain 2 1 0 0
+pass1 checkUnstableNatRules 2 1 0 0
+pass1 markManagedLocal 2 1 0 0
+pass1 checkDynamicNatRules 2 1 0 0
+pass1 checkSupernetRules 2 1 0 0
+pass1 checkServiceOwner 2 1 0 0
+pass1 checkIdenticalServices 2 1 0 0
+pass1 checkUnused 2 1 0 0
+pass1 checkRedundantRules 2 1 2 0
+pass1 removeSimpleDuplicateRules 2 1 2 0
+pass1 combineSubnetsInRules 2 1 2 0
+pass1 setPolicyDistributionIP 2 1 2 0
+pass1 expandCrypto 2 1 2 0
+pass1 findActiveRoutes 2 1 2 0
+pass1 genReverseRules 2 1 2 0
+pass1 markSecondaryRules 2 1 2 0
+pass1 rulesDistribution 2 1 2 0
+pass1 printCode 2 1 2 1
+pass1 copyRaw 2 1 2 1
+pass2 pass2 2 1 2 1
+acl_lines map[r1:1]
+=END=
+
+############################################################
+=TITLE=Report of phases in low-memory mode
+=INPUT=[[input]]
+=OPTIONS=--memory_limit=100
+=OUTPUT=
+pass1.cpu.pprof
+pass1.heap.pprof
+pass2.cpu.pprof
+pass2.heap.pprof
+report.json
+pass1 readNetspoc 2 0 0 0
+pass1 orderProtocols 2 0 0 0
+pass1 checkIPAddresses 2 0 0 0
+pass1 setZone 2 0 0 0
+pass1 setPath 2 0 0 0
+pass1 distributeNatInfo 2 0 0 0
+pass1 normalizeServices 2 0 0 0
+pass1 convertHostsInRules 2 0 0 0
+pass1 groupPathRules 2 1 0 0
+pass1 findSubnetsInNatDomain 2 1 0 0
+pass1 checkUnstableNatRules 2 1 0 0
+pass1 markManagedLocal 2 1 0 0
+pass1 checkDynamicNatRules 2 1 0 0
+pass1 checkSupernetRules 2 1 0 0
+pass1 checkServiceOwner 2 1 0 0
+pass1 checkIdenticalServices 2 1 0 0
+pass1 checkUnused 2 1 0 0
+pass1 checkRedundantRules 2 1 2 0
+pass1 removeSimpleDuplicateRules 2 1 2 0
+pass1 combineSubnetsInRules 2 1 2 0
+pass1 setPolicyDistributionIP 2 1 2 0
+pass1 expandCrypto 2 1 2 0
+pass1 findActiveRoutes 2 1 2 0
+pass1 genReverseRules 2 1 2 0
+pass1 markSecondaryRules 2 1 2 0
+pass1 rulesDistribution 2 1 2 0
+pass1 printCode 2 1 2 1
+pass1 copyRaw 2 1 2 1
+pass2 pass2 2 1 2 1
+acl_lines map[r1:1]
+=END=
@@ -123,6 +123,79 @@ Warning: service:2a is fully redundant
 Warning: service:2b is fully redundant
 =OPTIONS=--check_fully_redundant_rules=warn
 
+############################################################
+=TITLE=Find redundant rules in batches with option --memory_limit
+# Rules with src network:n1 and network:n2 are checked in
+# different batches, because both networks have no common supernet.
+=OPTIONS=--memory_limit=1000 --check_fully_redundant_rules=warn
+=INPUT=
+network:n1-sub = {
+ ip = 10.1.1.128/25;
+ subnet_of = network:n1;
+ host:h1 = { ip = 10.1.1.130; }
+}
+router:u = {
+ interface:n1-sub;
+ interface:n1;
+ interface:n2;
+}
+network:n1 = { ip = 10.1.1.0/24; }
+router:filter = {
+ managed;
+ model = ASA;
+ routing = manual;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+network:n2 = {
+ ip = 10.1.2.0/24;
+ host:h2 = { ip = 10.1.2.10; }
+}
+network:n3 = {
+ ip = 10.1.3.0/24;
+ host:h3 = { ip = 10.1.3.10; }
+}
+service:s1 = {
+ user = network:n1, network:n2;
+ permit src = user; dst = network:n3; prt = tcp;
+}
+service:s2 = {
+ user = host:h1, host:h2;
+ permit src = user; dst = network:n3; prt = tcp 80;
+}
+service:s3 = {
+ user = network:n2;
+ permit src = user; dst = network:n3; prt = tcp;
+}
+service:s4 = {
+ user = network:n1-sub;
+ permit src = user; dst = host:h3; prt = tcp 81;
+}
+=WARNING=
+Warning: Duplicate rules in service:s3 and service:s1:
+  permit src=network:n2; dst=network:n3; prt=tcp; of service:s3
+Warning: Redundant rules in service:s2 compared to service:s1:
+  permit src=host:h1; dst=network:n3; prt=tcp 80; of service:s2
+< permit src=network:n1; dst=network:n3; prt=tcp; of service:s1
+  permit src=host:h2; dst=network:n3; prt=tcp 80; of service:s2
+< permit src=network:n2; dst=network:n3; prt=tcp; of service:s1
+Warning: Redundant rules in service:s4 compared to service:s1:
+  permit src=network:n1-sub; dst=host:h3; prt=tcp 81; of service:s4
+< permit src=network:n1; dst=network:n3; prt=tcp; of service:s1
+Warning: service:s2 is fully redundant
+Warning: service:s3 is fully redundant
+Warning: service:s4 is fully redundant
+=OUTPUT=
+--filter
+! n1_in
+object-group network g0
+ network-object 10.1.1.0 255.255.255.0
+ network-object 10.1.2.0 255.255.255.0
+access-list n1_in extended permit tcp object-group g0 10.1.3.0 255.255.255.0
+access-list n1_in extended deny ip any4 any4
+access-group n1_in in interface n1
+=END=
+
 ############################################################
 =TITLE=Duplicate protocol in rule
 =INPUT=
@@ -123,12 +123,18 @@ network:n1 = { ip = 10.1.1.0/24; }
 =WARNING=NONE
 
 ############################################################
-=TITLE=Can't create link to reused file
+=TITLE=Can't copy reused file
 # No IPv6
 =SETUP=
 mkdir old
 mkdir out
 ln -s ../old out/.prev
+cat <<END > old/.manifest
+{"r1":{
+ "config":"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+ "rules":"bda081b688ed2aaff2da9e4b4c1fb112445036548b5a690b2d99c1194c368736",
+ "code":"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}}
+END
 cat <<END > old/r1.info
 {"generated_by":"devel","model":"ASA","name_list":["r1"]}
 END
@@ -150,5 +156,61 @@ router:r1 = {
 }
 =WITH_OUTDIR=
 =ERROR=
-panic: link out/.prev/r1 out/r1: file exists
+panic: Can't open out/r1: is a directory
 =END=
+
+############################################################
+=TITLE=Don't reuse code without manifest
+# No IPv6
+=SHOW_DIAG=
+=SETUP=
+mkdir old
+mkdir out
+ln -s ../old out/.prev
+cat <<END > old/r1.config
+END
+cat <<END > old/r1.rules
+{"model":"ASA","acls":null,"do_objectgroup":true}
+END
+cp old/r1.config old/r1
+=INPUT=
+-- topology
+network:n1 = { ip = 10.1.1.0/24; }
+router:r1 = {
+ managed = routing_only;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+}
+=WITH_OUTDIR=
+=WARNING=NONE
+
+############################################################
+=TITLE=Don't reuse changed code file
+# No IPv6
+=SHOW_DIAG=
+=SETUP=
+mkdir old
+mkdir out
+ln -s ../old out/.prev
+cat <<END > old/.manifest
+{"r1":{
+ "config":"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+ "rules":"bda081b688ed2aaff2da9e4b4c1fb112445036548b5a690b2d99c1194c368736",
+ "code":"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}}
+END
+cat <<END > old/r1.config
+END
+cat <<END > old/r1.rules
+{"model":"ASA","acls":null,"do_objectgroup":true}
+END
+echo changed > old/r1
+=INPUT=
+-- topology
+network:n1 = { ip = 10.1.1.0/24; }
+router:r1 = {
+ managed = routing_only;
+ model = ASA;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+}
+=WITH_OUTDIR=
+=WARNING=NONE
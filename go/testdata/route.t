@@ -508,6 +508,44 @@ ip route add 10.1.1.0/28 via 10.9.1.2
 ip route add 10.1.1.0/24 via 10.9.2.2
 =OPTIONS=--check_redundant_rules=0
 
+############################################################
+=TITLE=Routes of Linux in format of FRR
+=INPUT=[[input {n4: ",network:n4"}]]
+=SUBST=/model = Linux;/model = Linux, FRR;/
+=OUTPUT=
+--r
+# [ Routing ]
+vtysh <<EOF
+configure terminal
+ip route 0.0.0.0/0 10.9.1.2
+ip route 10.1.1.0/28 10.9.1.2
+ip route 10.1.1.0/24 10.9.2.2
+end
+EOF
+=END=
+
+############################################################
+=TITLE=Routes of Linux in format of ip -batch
+=INPUT=[[input {n4: ",network:n4"}]]
+=SUBST=/model = Linux;/model = Linux, BATCH;/
+=OUTPUT=
+--r
+# [ Routing ]
+ip -batch - <<EOF
+route add 0.0.0.0/0 via 10.9.1.2
+route add 10.1.1.0/28 via 10.9.1.2
+route add 10.1.1.0/24 via 10.9.2.2
+EOF
+=END=
+
+############################################################
+=TITLE=Unknown extension of model Linux
+=INPUT=[[input {n4: ""}]]
+=SUBST=/model = Linux;/model = Linux, NETPLAN;/
+=ERROR=
+Error: Unknown extension in 'model' of router:r: NETPLAN
+=END=
+
 ############################################################
 =TITLE=Must not optimize route to supernet in other part of zone cluster
 =TEMPL=topo
@@ -857,6 +895,59 @@ service:ping_local = {
 ip route 10.1.2.0 255.255.255.0 10.1.1.2
 =END=
 
+############################################################
+=TITLE=No static routes at interfaces with BGP
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+router:r1 = {
+ managed;
+ model = IOS;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; routing = BGP; }
+}
+router:r2 = {
+ managed;
+ model = ASA;
+ interface:n2 = { ip = 10.1.2.2; hardware = n2; routing = BGP; }
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+}
+router:r3 = {
+ managed;
+ model = Linux;
+ interface:n3 = { ip = 10.1.3.2; hardware = n3; routing = BGP; }
+ interface:n4 = { ip = 10.1.4.1; hardware = n4; }
+}
+router:u1 = {
+ interface:n4 = { ip = 10.1.4.2; }
+ interface:n5;
+}
+network:n5 = { ip = 10.1.5.0/24; }
+service:s1 = {
+ user = network:n1;
+ permit src = user; dst = network:n5; prt = tcp 80;
+}
+=OUTPUT=
+--r1
+ip access-list extended n2_in
+ permit tcp 10.1.2.0 0.0.0.255 10.1.2.0 0.0.0.255 eq 179
+ permit tcp 10.1.2.0 0.0.0.255 eq 179 10.1.2.0 0.0.0.255
+ permit tcp 10.1.5.0 0.0.0.255 10.1.1.0 0.0.0.255 established
+ deny ip any any
+--
+interface n2
+ ip address 10.1.2.1 255.255.255.0
+ ip access-group n2_in in
+--r2
+! [ Routing ]
+route n3 10.1.5.0 255.255.255.0 10.1.3.2
+--r3
+# [ Routing ]
+ip route add 10.1.5.0/24 via 10.1.4.2
+=END=
+
 ############################################################
 =TITLE=No route between pair of virtual interfaces
 =INPUT=
@@ -982,3 +1073,69 @@ route n4 10.1.2.0 255.255.255.0 10.1.4.1
 =END=
 
 ############################################################
+=TITLE=Aggregate routes with same hop
+# 10.2.5.0/24 and 10.2.7.0/24 can't be aggregated,
+# because 10.2.6.0/24 uses other hop.
+=TEMPL=input
+network:n1 = { ip = 10.1.1.0/24; }
+router:r1 = {
+ managed;
+ model = IOS;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:t1 = { ip = 10.9.1.1; hardware = t1; }
+ interface:t2 = { ip = 10.9.2.1; hardware = t2; }
+}
+network:t1 = { ip = 10.9.1.0/30; }
+network:t2 = { ip = 10.9.2.0/30; }
+router:u1 = {
+ interface:t1 = { ip = 10.9.1.2; }
+ interface:a1;
+ interface:a2;
+ interface:a3;
+ interface:a4;
+}
+router:u2 = {
+ interface:t2 = { ip = 10.9.2.2; }
+ interface:b1;
+ interface:b2;
+}
+network:a1 = { ip = 10.2.0.0/24; }
+network:a2 = { ip = 10.2.2.0/24; }
+network:a3 = { ip = 10.2.5.0/24; }
+network:a4 = { ip = 10.2.7.0/24; }
+network:b1 = { ip = 10.2.6.0/24; }
+network:b2 = { ip = 10.3.0.0/24; }
+service:s1 = {
+ user = network:n1;
+ permit src = user;
+        dst = network:a1, network:a2, network:a3, network:a4,
+              network:b1, network:b2;
+        prt = tcp 80;
+}
+=INPUT=[[input]]
+=OPTIONS=--auto_default_route=0 --aggregate_routes
+=OUTPUT=
+--r1
+! [ Routing ]
+ip route 10.2.5.0 255.255.255.0 10.9.1.2
+ip route 10.2.7.0 255.255.255.0 10.9.1.2
+ip route 10.2.0.0 255.255.252.0 10.9.1.2
+ip route 10.2.6.0 255.255.255.0 10.9.2.2
+ip route 10.3.0.0 255.255.255.0 10.9.2.2
+=END=
+
+############################################################
+=TITLE=Don't aggregate routes over directly connected network
+=INPUT=[[input]]
+=SUBST=/10.1.1./10.2.1./
+=OPTIONS=--auto_default_route=0 --aggregate_routes
+=OUTPUT=
+--r1
+! [ Routing ]
+ip route 10.2.0.0 255.255.255.0 10.9.1.2
+ip route 10.2.2.0 255.255.255.0 10.9.1.2
+ip route 10.2.5.0 255.255.255.0 10.9.1.2
+ip route 10.2.7.0 255.255.255.0 10.9.1.2
+ip route 10.2.6.0 255.255.255.0 10.9.2.2
+ip route 10.3.0.0 255.255.255.0 10.9.2.2
+=END=
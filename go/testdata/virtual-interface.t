@@ -383,3 +383,215 @@ Error: Ambiguous static routes for network:n1 at interface:r4.n3 via
 =END=
 
 ############################################################
+=TITLE=Generate configuration of HSRP and VRRP
+=TEMPL=input
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+router:r1 = {
+ managed;
+ model = IOS;
+ interface:n1 = {
+  ip = 10.1.1.2;
+  virtual = { ip = 10.1.1.1; type = HSRPv2; id = 11; priority = 110; }
+  hardware = n1;
+ }
+ interface:n2 = {
+  ip = 10.1.2.2;
+  virtual = { ip = 10.1.2.1; type = VRRP; id = 12; priority = 110; }
+  hardware = n2;
+ }
+}
+router:r2 = {
+ managed;
+ model = IOS;
+ interface:n1 = {
+  ip = 10.1.1.3;
+  virtual = { ip = 10.1.1.1; type = HSRPv2; id = 11; }
+  hardware = n1;
+ }
+ interface:n2 = {
+  ip = 10.1.2.3;
+  virtual = { ip = 10.1.2.1; type = VRRP; id = 12; }
+  hardware = n2;
+ }
+}
+router:r3 = {
+ managed;
+ model = Linux;
+ interface:n2 = {
+  ip = 10.1.2.4;
+  virtual = { ip = 10.1.2.10; type = VRRP; id = 13; priority = 120; }
+  hardware = eth0;
+ }
+ interface:n3 = { ip = 10.1.3.1; hardware = eth1; }
+}
+router:r4 = {
+ managed;
+ model = Linux;
+ interface:n2 = {
+  ip = 10.1.2.5;
+  virtual = { ip = 10.1.2.10; type = VRRP; id = 13; priority = 100; }
+  hardware = eth0;
+ }
+ interface:n3 = { ip = 10.1.3.2; hardware = eth1; }
+}
+=INPUT=[[input]]
+=OPTIONS=--generate_redundancy
+=OUTPUT=
+--r1
+interface n1
+ ip address 10.1.1.2 255.255.255.0
+ standby version 2
+ standby 11 ip 10.1.1.1
+ standby 11 priority 110
+ ip access-group n1_in in
+interface n2
+ ip address 10.1.2.2 255.255.255.0
+ vrrp 12 ip 10.1.2.1
+ vrrp 12 priority 110
+ ip access-group n2_in in
+--r2
+interface n1
+ ip address 10.1.1.3 255.255.255.0
+ standby version 2
+ standby 11 ip 10.1.1.1
+ ip access-group n1_in in
+interface n2
+ ip address 10.1.2.3 255.255.255.0
+ vrrp 12 ip 10.1.2.1
+ ip access-group n2_in in
+--r3
+# [ Redundancy ]
+cat > /etc/keepalived/conf.d/ipv4.conf <<EOF
+vrrp_instance eth0_13 {
+ interface eth0
+ virtual_router_id 13
+ priority 120
+ virtual_ipaddress {
+  10.1.2.10/24
+ }
+}
+EOF
+=END=
+
+############################################################
+=TITLE=Configuration of keepalived for dual stack router
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; ip6 = 2001:db8:1:1::/64; }
+network:n2 = { ip = 10.1.2.0/24; ip6 = 2001:db8:1:2::/64; }
+router:r1 = {
+ managed;
+ model = Linux;
+ interface:n1 = {
+  ip = 10.1.1.2;
+  ip6 = 2001:db8:1:1::2;
+  virtual = { ip = 10.1.1.1; ip6 = 2001:db8:1:1::1; type = VRRP; id = 11;
+              priority = 110; }
+  hardware = eth0;
+ }
+ interface:n2 = { ip = 10.1.2.1; ip6 = 2001:db8:1:2::1; hardware = eth1; }
+}
+router:r2 = {
+ managed;
+ model = Linux;
+ interface:n1 = {
+  ip = 10.1.1.3;
+  ip6 = 2001:db8:1:1::3;
+  virtual = { ip = 10.1.1.1; ip6 = 2001:db8:1:1::1; type = VRRP; id = 11;
+              priority = 100; }
+  hardware = eth0;
+ }
+ interface:n2 = { ip = 10.1.2.2; ip6 = 2001:db8:1:2::2; hardware = eth1; }
+}
+=OPTIONS=--generate_redundancy
+=OUTPUT=
+--r1
+# [ Redundancy ]
+cat > /etc/keepalived/conf.d/ipv4.conf <<EOF
+vrrp_instance eth0_11 {
+ interface eth0
+ virtual_router_id 11
+ priority 110
+ virtual_ipaddress {
+  10.1.1.1/24
+ }
+}
+EOF
+--ipv6/r1
+# [ Redundancy ]
+cat > /etc/keepalived/conf.d/ipv6.conf <<EOF
+vrrp_instance eth0_11_v6 {
+ interface eth0
+ virtual_router_id 11
+ priority 110
+ virtual_ipaddress {
+  2001:db8:1:1::1/64
+ }
+}
+EOF
+=END=
+
+############################################################
+=TITLE=No configuration of redundancy protocol by default
+=INPUT=[[input]]
+=OUTPUT=
+--r2
+interface n1
+ ip address 10.1.1.3 255.255.255.0
+ ip access-group n1_in in
+interface n2
+ ip address 10.1.2.3 255.255.255.0
+ ip access-group n2_in in
+=END=
+
+############################################################
+=TITLE=Can't generate configuration of redundancy protocol
+=INPUT=[[input]]
+=SUBST=/type = VRRP; id = 13;/type = HSRP;/
+=OPTIONS=--generate_redundancy
+=ERROR=
+Error: Missing 'id' in 'virtual' of interface:r3.n2.virtual, needed to generate redundancy configuration
+Error: Can't generate configuration of HSRP for model Linux at interface:r3.n2.virtual
+Error: Missing 'id' in 'virtual' of interface:r4.n2.virtual, needed to generate redundancy configuration
+Error: Can't generate configuration of HSRP for model Linux at interface:r4.n2.virtual
+=END=
+
+############################################################
+=TITLE=Must use different priority in redundancy group
+=INPUT=[[input]]
+=SUBST=/priority = 100;/priority = 120;/
+=ERROR=
+Error: Must use different priority at
+ - interface:r3.n2.virtual
+ - interface:r4.n2.virtual
+=END=
+
+############################################################
+=TITLE=Bad redundancy priority
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+router:r1 = {
+ interface:n1 = {
+  ip = 10.1.1.2;
+  virtual = { ip = 10.1.1.1; priority = high; }
+ }
+}
+router:r2 = {
+ interface:n1 = {
+  ip = 10.1.1.3;
+  virtual = { ip = 10.1.1.1; type = VRRP; priority = 256; }
+ }
+}
+router:r3 = {
+ interface:n1 = {
+  ip = 10.1.1.4;
+  virtual = { ip = 10.1.1.1; type = VRRP; priority = 0; }
+ }
+}
+=ERROR=
+Error: Redundancy priority must be numeric in 'virtual' of interface:r1.n1
+Error: Redundancy priority is given without redundancy protocol in 'virtual' of interface:r1.n1
+Error: Redundancy priority must be > 0, < 256 in 'virtual' of interface:r2.n1
+Error: Redundancy priority must be > 0, < 256 in 'virtual' of interface:r3.n1
+=END=
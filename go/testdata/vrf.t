@@ -358,4 +358,80 @@ Error: Must not use VRF at router:r1@v1 of model ASA
 Error: Must not use VRF at router:r1@v2 of model ASA
 =END=
 
-############################################################
\ No newline at end of file
+############################################################
+=TITLE=Linux with VRF
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+network:n2 = { ip = 10.1.2.0/24; }
+network:n3 = { ip = 10.1.3.0/24; }
+network:n4 = { ip = 10.1.4.0/24; }
+router:r1@v1 = {
+ managed;
+ model = Linux, FRR;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+ interface:n2 = { ip = 10.1.2.1; hardware = n2; }
+}
+router:r1@v2 = {
+ managed;
+ model = Linux, FRR;
+ interface:n3 = { ip = 10.1.3.1; hardware = n3; }
+ interface:n4 = { ip = 10.1.4.1; hardware = n4; }
+}
+router:u = {
+ interface:n2 = { ip = 10.1.2.2; }
+ interface:n3 = { ip = 10.1.3.2; }
+}
+service:s = {
+ user = network:n1;
+ permit src = user; dst = network:n4; prt = tcp 80;
+}
+=OUTPUT=
+--r1
+# [ Routing for router:r1@v1 ]
+vtysh <<EOF
+configure terminal
+vrf v1
+ ip route 10.1.4.0/24 10.1.2.2
+exit-vrf
+end
+EOF
+--
+# [ Routing for router:r1@v2 ]
+vtysh <<EOF
+configure terminal
+vrf v2
+ ip route 10.1.1.0/24 10.1.3.2
+exit-vrf
+end
+EOF
+--
+# [ ACL for router:r1@v1 ]
+:n1_self -
+-A INPUT -j n1_self -i n1
+--
+# [ ACL for router:r1@v2 ]
+:n3_self -
+-A INPUT -j n3_self -i n3
+--
+:n4_n3 -
+-A FORWARD -j n4_n3 -i n4 -o n3
+--
+# [ SUFFIX ]
+-A INPUT -j droplog
+-A FORWARD -j droplog
+COMMIT
+EOF
+=END=
+
+############################################################
+=TITLE=Linux supports VRF only with FRR or BATCH
+=INPUT=
+network:n1 = { ip = 10.1.1.0/24; }
+router:r1@v1 = {
+ managed;
+ model = Linux;
+ interface:n1 = { ip = 10.1.1.1; hardware = n1; }
+}
+=ERROR=
+Error: Must not use VRF at router:r1@v1 of model Linux
+=END=